@@ -0,0 +1,238 @@
+// Package actions maps named actions, like "jump" or "move_x", to the keys,
+// mouse buttons, wheel and mouse movement that trigger them, so game code
+// asks about actions instead of keys and players can rebind them.
+//
+//	m := actions.New()
+//	m.Bind("jump", actions.Key(input.K_SPACE), actions.Key(input.K_W))
+//	m.Bind("move_x", actions.Key(input.K_A).Scaled(-1), actions.Key(input.K_D))
+//	m.Load("controls")
+//
+// Update is called once a frame, before the actions are asked about.
+package actions
+
+import (
+	"math"
+
+	"github.com/losinggeneration/hge/ini"
+	"github.com/losinggeneration/hge/input"
+)
+
+type action struct {
+	bindings []Binding
+	defaults []Binding
+
+	value             float64
+	held, wasHeld     bool
+	pressed, released bool
+}
+
+// Map holds the actions and what they're bound to.
+type Map struct {
+	actions map[string]*action
+	order   []string
+
+	mouse         input.Mouse
+	lastX, lastY  float64
+	hasLastMouse  bool
+	dx, dy, wheel float64
+	modifiers     int
+}
+
+// Creates a map with no actions.
+func New() *Map {
+	return &Map{actions: make(map[string]*action)}
+}
+
+func (m *Map) get(name string) *action {
+	a, ok := m.actions[name]
+	if !ok {
+		a = new(action)
+		m.actions[name] = a
+		m.order = append(m.order, name)
+	}
+
+	return a
+}
+
+// Adds bindings to an action, making the action if it's new. The bindings
+// an action is given before Load are its defaults.
+func (m *Map) Bind(name string, bindings ...Binding) {
+	a := m.get(name)
+	a.bindings = append(a.bindings, bindings...)
+}
+
+// Replaces the bindings of an action, to rebind it.
+func (m *Map) Rebind(name string, bindings ...Binding) {
+	m.get(name).bindings = append([]Binding(nil), bindings...)
+}
+
+// Removes all the bindings of an action.
+func (m *Map) Unbind(name string) {
+	if a, ok := m.actions[name]; ok {
+		a.bindings = nil
+	}
+}
+
+// Returns the bindings of an action.
+func (m *Map) Bindings(name string) []Binding {
+	if a, ok := m.actions[name]; ok {
+		return append([]Binding(nil), a.bindings...)
+	}
+
+	return nil
+}
+
+// Returns the names of the actions in the order they were made.
+func (m *Map) Actions() []string {
+	return append([]string(nil), m.order...)
+}
+
+// Goes back to the bindings the actions had before Load.
+func (m *Map) Reset() {
+	for _, a := range m.actions {
+		if a.defaults != nil {
+			a.bindings = append([]Binding(nil), a.defaults...)
+		}
+	}
+}
+
+// Reads the state of the input and works out the actions from it. It's
+// called once a frame.
+func (m *Map) Update() {
+	x, y := m.mouse.Pos()
+	if m.hasLastMouse {
+		m.dx, m.dy = x-m.lastX, y-m.lastY
+	}
+	m.lastX, m.lastY, m.hasLastMouse = x, y, true
+	m.wheel = float64(m.mouse.WheelMovement())
+
+	m.modifiers = 0
+	for _, k := range []struct {
+		key  input.Key
+		flag int
+	}{{input.K_SHIFT, input.INP_SHIFT}, {input.K_CTRL, input.INP_CTRL}, {input.K_ALT, input.INP_ALT}} {
+		if k.key.State() {
+			m.modifiers |= k.flag
+		}
+	}
+
+	for _, a := range m.actions {
+		a.wasHeld = a.held
+		a.value, a.held = 0, false
+
+		down, up := false, false
+		for _, b := range a.bindings {
+			if b.Modifiers&m.modifiers != b.Modifiers {
+				continue
+			}
+
+			v := m.value(b)
+			a.value += v
+			if v != 0 {
+				a.held = true
+			}
+
+			if b.Source == SOURCE_KEY {
+				down = down || b.Key.Down()
+				up = up || b.Key.Up()
+			}
+		}
+
+		// A key pressed and let go within a frame is still a press, but
+		// another key for an action that's held isn't
+		a.pressed = !a.wasHeld && (a.held || down)
+		a.released = a.wasHeld && !a.held || !a.wasHeld && !a.held && down && up
+	}
+}
+
+func (m *Map) value(b Binding) float64 {
+	switch b.Source {
+	case SOURCE_KEY:
+		if b.Key.State() {
+			return b.Scale
+		}
+	case SOURCE_WHEEL:
+		return m.wheel * b.Scale
+	case SOURCE_MOUSEX:
+		return m.dx * b.Scale
+	case SOURCE_MOUSEY:
+		return m.dy * b.Scale
+	}
+
+	return 0
+}
+
+// Reports whether the action started this frame.
+func (m *Map) Pressed(name string) bool {
+	a, ok := m.actions[name]
+	return ok && a.pressed
+}
+
+// Reports whether the action stopped this frame.
+func (m *Map) Released(name string) bool {
+	a, ok := m.actions[name]
+	return ok && a.released
+}
+
+// Reports whether anything bound to the action is held.
+func (m *Map) Held(name string) bool {
+	a, ok := m.actions[name]
+	return ok && a.held
+}
+
+// Returns the sum of the values of the action's bindings. Keys give their
+// scale while they're held, so an axis bound to two keys is -1, 0 or 1.
+func (m *Map) Value(name string) float64 {
+	if a, ok := m.actions[name]; ok {
+		return a.value
+	}
+
+	return 0
+}
+
+// Like Value, but kept between -1 and 1, for axes that shouldn't go faster
+// when two bindings for the same direction are held.
+func (m *Map) Axis(name string) float64 {
+	return math.Max(-1, math.Min(1, m.Value(name)))
+}
+
+// Returns a binding for the key or mouse button pressed this frame, with the
+// modifiers held, for when the player is picking a new binding. Modifier
+// keys on their own aren't returned, so Ctrl+S can be picked.
+func (m *Map) Listen() (Binding, bool) {
+	k := input.GetKey()
+	switch k {
+	case 0, input.K_SHIFT, input.K_CTRL, input.K_ALT:
+		return Binding{}, false
+	}
+
+	return Key(k).With(m.modifiers), true
+}
+
+// Saves the bindings of every action into the ini section, one name for each
+// action.
+func (m *Map) Save(section string) {
+	for _, name := range m.order {
+		ini.NewIni(section, name).SetString(FormatBindings(m.actions[name].bindings))
+	}
+}
+
+// Reads the bindings of the actions saved in the ini section. Only actions
+// that have been bound already are read, and their bindings are kept if the
+// ones saved can't be parsed. The bindings before the first Load are kept as
+// the defaults Reset goes back to.
+func (m *Map) Load(section string) {
+	for _, name := range m.order {
+		a := m.actions[name]
+		if a.defaults == nil {
+			a.defaults = append([]Binding{}, a.bindings...)
+		}
+
+		s := FormatBindings(a.bindings)
+		if saved := ini.NewIni(section, name).GetString(s); saved != s {
+			if b, err := ParseBindings(saved); err == nil {
+				a.bindings = b
+			}
+		}
+	}
+}
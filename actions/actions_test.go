@@ -0,0 +1,338 @@
+package actions
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/losinggeneration/hge"
+	"github.com/losinggeneration/hge/hgetest"
+	"github.com/losinggeneration/hge/ini"
+	"github.com/losinggeneration/hge/input"
+)
+
+type state struct {
+	pressed, held, released bool
+	value                   float64
+}
+
+// A frame of play: the input given before it, and what the actions should
+// be once the map's updated.
+type frame struct {
+	input func(h *hge.Headless)
+	want  map[string]state
+	check func(m *Map)
+}
+
+func press(keys ...input.Key) func(*hge.Headless) {
+	return func(h *hge.Headless) {
+		for _, k := range keys {
+			h.PressKey(int(k))
+		}
+	}
+}
+
+func release(keys ...input.Key) func(*hge.Headless) {
+	return func(h *hge.Headless) {
+		for _, k := range keys {
+			h.ReleaseKey(int(k))
+		}
+	}
+}
+
+// Runs the frames on the headless backend, updating the map in each.
+// Whatever's still held at the end is let go.
+func play(t *testing.T, m *Map, frames []frame) {
+	t.Helper()
+
+	var headless *hge.Headless
+	give := func(i int) {
+		if i < len(frames) {
+			if frames[i].input != nil {
+				frames[i].input(headless)
+			}
+			return
+		}
+
+		for k := 1; k < 256; k++ {
+			if headless.KeyState(k) {
+				headless.ReleaseKey(k)
+			}
+		}
+	}
+
+	_, err := hgetest.Render(hgetest.Scene{
+		Width: 8, Height: 8,
+		Frames: len(frames) + 1,
+		Setup: func() error {
+			h := hge.New()
+			headless = h.Backend().(*hge.Headless)
+			h.Free()
+
+			give(0)
+			return nil
+		},
+		Frame: func(i int) {
+			if i >= len(frames) {
+				return
+			}
+
+			m.Update()
+			for name, want := range frames[i].want {
+				got := state{m.Pressed(name), m.Held(name), m.Released(name), m.Value(name)}
+				if got != want {
+					t.Errorf("frame %d: %s is %+v, want %+v", i, name, got, want)
+				}
+			}
+			if frames[i].check != nil {
+				frames[i].check(m)
+			}
+
+			give(i + 1)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	m := New()
+	m.Bind("jump", Key(input.K_SPACE), Key(input.K_W))
+	m.Bind("move_x", Key(input.K_A).Scaled(-1), Key(input.K_D))
+	m.Bind("save", Key(input.K_S).With(input.INP_CTRL))
+	m.Bind("look", MouseX().Scaled(0.5))
+	m.Bind("zoom", Wheel())
+
+	play(t, m, []frame{
+		{
+			input: func(h *hge.Headless) {
+				h.MoveMouse(100, 100)
+				h.PressKey(int(input.K_SPACE))
+			},
+			want: map[string]state{"jump": {true, true, false, 1}, "move_x": {}, "look": {}},
+		},
+		{want: map[string]state{"jump": {false, true, false, 1}}},
+		{
+			input: func(h *hge.Headless) {
+				h.ReleaseKey(int(input.K_SPACE))
+				h.PressKey(int(input.K_D))
+			},
+			want: map[string]state{"jump": {false, false, true, 0}, "move_x": {true, true, false, 1}},
+		},
+		// Both directions held cancel out, but the action's still held
+		{input: press(input.K_A), want: map[string]state{"move_x": {false, true, false, 0}}},
+		{input: release(input.K_D), want: map[string]state{"move_x": {false, true, false, -1}}},
+		{
+			input: func(h *hge.Headless) {
+				h.ReleaseKey(int(input.K_A))
+				h.PressKey(int(input.K_S))
+			},
+			want: map[string]state{"move_x": {false, false, true, 0}, "save": {}},
+		},
+		// Holding Shift as well doesn't stop Ctrl+S
+		{input: press(input.K_CTRL, input.K_SHIFT), want: map[string]state{"save": {true, true, false, 1}}},
+		{input: release(input.K_S, input.K_CTRL, input.K_SHIFT), want: map[string]state{"save": {false, false, true, 0}}},
+		// Pressed and let go within the frame
+		{
+			input: func(h *hge.Headless) {
+				h.PressKey(int(input.K_SPACE))
+				h.ReleaseKey(int(input.K_SPACE))
+			},
+			want: map[string]state{"jump": {true, false, true, 0}},
+		},
+		{
+			input: func(h *hge.Headless) {
+				h.MoveMouse(110, 96)
+				h.ScrollWheel(-2)
+			},
+			want: map[string]state{"jump": {}, "look": {true, true, false, 5}, "zoom": {true, true, false, -2}},
+		},
+		{want: map[string]state{"look": {false, false, true, 0}, "zoom": {false, false, true, 0}}},
+		{
+			input: press(input.K_SPACE, input.K_W),
+			want:  map[string]state{"jump": {true, true, false, 2}},
+			check: func(m *Map) {
+				if a := m.Axis("jump"); a != 1 {
+					t.Errorf("jump's axis is %v with two keys held, want 1", a)
+				}
+			},
+		},
+	})
+
+	if m.Pressed("missing") || m.Held("missing") || m.Released("missing") || m.Value("missing") != 0 {
+		t.Error("an action that was never bound isn't idle")
+	}
+}
+
+func TestRebind(t *testing.T) {
+	m := New()
+	m.Bind("jump", Key(input.K_SPACE))
+	m.Bind("fire", Key(input.K_LBUTTON))
+	m.Bind("jump", Key(input.K_W))
+
+	if got, want := m.Actions(), []string{"jump", "fire"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("actions %v, want %v", got, want)
+	}
+	if got, want := m.Bindings("jump"), []Binding{Key(input.K_SPACE), Key(input.K_W)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("jump is bound to %v, want %v", got, want)
+	}
+	m.Bindings("jump")[0] = Wheel()
+	if b := m.Bindings("jump")[0]; b != Key(input.K_SPACE) {
+		t.Errorf("changing what Bindings returned rebound jump to %v", b)
+	}
+
+	listen := func(want Binding, ok bool) func(*Map) {
+		return func(m *Map) {
+			if b, listened := m.Listen(); listened != ok || b != want {
+				t.Errorf("listened for %v,%v, want %v,%v", b, listened, want, ok)
+			}
+		}
+	}
+
+	play(t, m, []frame{
+		// A modifier on its own isn't picked
+		{input: press(input.K_CTRL), check: listen(Binding{}, false)},
+		{
+			input: press(input.K_S),
+			check: func(m *Map) {
+				listen(Key(input.K_S).With(input.INP_CTRL), true)(m)
+				b, _ := m.Listen()
+				m.Rebind("jump", b)
+			},
+		},
+		{want: map[string]state{"jump": {true, true, false, 1}}},
+		{input: release(input.K_CTRL), want: map[string]state{"jump": {false, false, true, 0}}},
+		// What jump used to be bound to does nothing now
+		{input: press(input.K_SPACE, input.K_W), want: map[string]state{"jump": {}}},
+		{
+			input: press(input.K_LBUTTON),
+			want:  map[string]state{"fire": {true, true, false, 1}},
+			check: func(m *Map) {
+				listen(Key(input.K_LBUTTON), true)(m)
+				m.Unbind("fire")
+			},
+		},
+		{want: map[string]state{"fire": {false, false, true, 0}}},
+		{want: map[string]state{"fire": {}}},
+	})
+
+	if b := m.Bindings("fire"); b != nil {
+		t.Errorf("fire is still bound to %v after Unbind", b)
+	}
+	m.Unbind("missing")
+	if a := m.Actions(); len(a) != 2 {
+		t.Errorf("unbinding an action that isn't there made it: %v", a)
+	}
+}
+
+func TestBindingStrings(t *testing.T) {
+	tests := []struct {
+		b Binding
+		s string
+	}{
+		{Key(input.K_SPACE), "Space"},
+		{Key(input.K_S).With(input.INP_CTRL), "Ctrl+S"},
+		{Key(input.K_F1).With(input.INP_SHIFT | input.INP_CTRL | input.INP_ALT).Scaled(2), "Shift+Ctrl+Alt+F1*2"},
+		{Key(input.K_A).Scaled(-1), "A*-1"},
+		{Key(input.K_LBUTTON), "Left Mouse Button"},
+		{Key(0x07), "#7"},
+		{Wheel(), "Wheel"},
+		{MouseX().Scaled(0.5), "MouseX*0.5"},
+		{MouseY().Scaled(-0.25).With(input.INP_ALT), "Alt+MouseY*-0.25"},
+	}
+
+	for _, test := range tests {
+		if s := test.b.String(); s != test.s {
+			t.Errorf("%+v is written %q, want %q", test.b, s, test.s)
+		}
+		if b, err := ParseBinding(test.s); err != nil || b != test.b {
+			t.Errorf("%q is read as %+v, %v, want %+v", test.s, b, err, test.b)
+		}
+	}
+
+	// Reading is forgiving about case and spaces
+	if b, err := ParseBinding(" ctrl + left arrow * 3 "); err != nil || b != Key(input.K_LEFT).With(input.INP_CTRL).Scaled(3) {
+		t.Errorf("read %+v, %v", b, err)
+	}
+
+	for _, s := range []string{"Hyper+A", "A*fast", "Nope", "#0", "#300", ""} {
+		if b, err := ParseBinding(s); err == nil {
+			t.Errorf("%q is read as %+v, want an error", s, b)
+		}
+	}
+
+	all := make([]Binding, len(tests))
+	for i, test := range tests {
+		all[i] = test.b
+	}
+	if b, err := ParseBindings(FormatBindings(all)); err != nil || !reflect.DeepEqual(b, all) {
+		t.Errorf("bindings read back as %v, %v, want %v", b, err, all)
+	}
+	if b, err := ParseBindings(""); err != nil || b != nil {
+		t.Errorf("no bindings read back as %v, %v", b, err)
+	}
+	if _, err := ParseBindings("Space, Nope"); err == nil {
+		t.Error("a bad binding in a list isn't an error")
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	h := hge.New()
+	defer h.Free()
+	h.SetState(hge.INIFILE, filepath.Join(t.TempDir(), "controls.ini"))
+	defer h.SetState(hge.INIFILE, "")
+
+	defaults := func() *Map {
+		m := New()
+		m.Bind("jump", Key(input.K_SPACE))
+		m.Bind("move_x", Key(input.K_A).Scaled(-1), Key(input.K_D))
+		m.Bind("look", MouseX().Scaled(0.5))
+		return m
+	}
+
+	saved := defaults()
+	saved.Rebind("jump", Key(input.K_W), Key(input.K_S).With(input.INP_SHIFT|input.INP_CTRL))
+	saved.Rebind("look", MouseY().Scaled(-0.25))
+	saved.Unbind("move_x")
+	saved.Save("controls")
+
+	if s := ini.NewIni("controls", "jump").GetString(""); s != "W, Shift+Ctrl+S" {
+		t.Errorf("jump is saved as %q", s)
+	}
+
+	m := defaults()
+	m.Bind("fire", Key(input.K_LBUTTON))
+	m.Load("controls")
+	for _, name := range saved.Actions() {
+		if got, want := m.Bindings(name), saved.Bindings(name); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s is loaded as %v, want %v", name, got, want)
+		}
+	}
+	// Nothing was saved for fire
+	if got, want := m.Bindings("fire"), []Binding{Key(input.K_LBUTTON)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("fire is loaded as %v, want %v", got, want)
+	}
+
+	// Reset goes back to what was bound before the first Load
+	m.Rebind("jump", Key(input.K_ENTER))
+	m.Load("controls")
+	m.Reset()
+	for _, name := range defaults().Actions() {
+		if got, want := m.Bindings(name), defaults().Bindings(name); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s is reset to %v, want %v", name, got, want)
+		}
+	}
+
+	// Bindings that can't be read leave the action as it was
+	ini.NewIni("controls", "jump").SetString("Hyper+X")
+	m = defaults()
+	m.Load("controls")
+	if got, want := m.Bindings("jump"), []Binding{Key(input.K_SPACE)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("jump is loaded as %v from a bad binding, want %v", got, want)
+	}
+	if got := m.Bindings("look"); len(got) != 1 || math.Abs(got[0].Scale+0.25) > 1e-9 {
+		t.Errorf("look is loaded as %v", got)
+	}
+}
@@ -0,0 +1,197 @@
+package actions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/losinggeneration/hge/input"
+)
+
+// Binding sources
+const (
+	SOURCE_KEY    = iota // a key or mouse button, Scale while it's held
+	SOURCE_WHEEL         // the notches the mouse wheel turned this frame
+	SOURCE_MOUSEX        // how far the mouse moved right this frame
+	SOURCE_MOUSEY        // how far the mouse moved down this frame
+)
+
+// What an action is bound to. The value it gives is multiplied by Scale, so
+// binding one key with a Scale of -1 and another with 1 makes an axis.
+type Binding struct {
+	Source int
+	Key    input.Key // for SOURCE_KEY, mouse buttons are K_LBUTTON and so on
+
+	// INP_SHIFT, INP_CTRL and INP_ALT that have to be held as well. Others
+	// being held don't stop the binding.
+	Modifiers int
+
+	Scale float64
+}
+
+// Returns a binding to a key or mouse button.
+func Key(k input.Key) Binding {
+	return Binding{Source: SOURCE_KEY, Key: k, Scale: 1}
+}
+
+// Returns a binding to the mouse wheel.
+func Wheel() Binding {
+	return Binding{Source: SOURCE_WHEEL, Scale: 1}
+}
+
+// Returns a binding to the mouse moving left and right.
+func MouseX() Binding {
+	return Binding{Source: SOURCE_MOUSEX, Scale: 1}
+}
+
+// Returns a binding to the mouse moving up and down.
+func MouseY() Binding {
+	return Binding{Source: SOURCE_MOUSEY, Scale: 1}
+}
+
+// Returns the binding needing modifiers held as well.
+func (b Binding) With(modifiers int) Binding {
+	b.Modifiers = modifiers
+	return b
+}
+
+// Returns the binding with its value multiplied by scale.
+func (b Binding) Scaled(scale float64) Binding {
+	b.Scale = scale
+	return b
+}
+
+var modifierNames = []struct {
+	flag int
+	name string
+}{
+	{input.INP_SHIFT, "Shift"},
+	{input.INP_CTRL, "Ctrl"},
+	{input.INP_ALT, "Alt"},
+}
+
+var sourceNames = map[int]string{
+	SOURCE_WHEEL:  "Wheel",
+	SOURCE_MOUSEX: "MouseX",
+	SOURCE_MOUSEY: "MouseY",
+}
+
+// Keys are written by name when the name can be read back, otherwise as
+// #code.
+func keyName(k input.Key) string {
+	name := k.Name()
+	if name == "" || name == "?" || strings.ContainsAny(name, "+*,#") {
+		return "#" + strconv.Itoa(int(k))
+	}
+
+	return name
+}
+
+func parseKey(s string) (input.Key, bool) {
+	if code, ok := strings.CutPrefix(s, "#"); ok {
+		k, err := strconv.Atoi(code)
+		return input.Key(k), err == nil && k > 0 && k < 256
+	}
+
+	for k := input.Key(1); k < 256; k++ {
+		if strings.EqualFold(k.Name(), s) {
+			return k, true
+		}
+	}
+
+	return 0, false
+}
+
+// Returns the binding the way it's saved, like "Ctrl+S" or "MouseX*0.5".
+func (b Binding) String() string {
+	var s strings.Builder
+	for _, m := range modifierNames {
+		if b.Modifiers&m.flag != 0 {
+			s.WriteString(m.name + "+")
+		}
+	}
+
+	if b.Source == SOURCE_KEY {
+		s.WriteString(keyName(b.Key))
+	} else {
+		s.WriteString(sourceNames[b.Source])
+	}
+
+	if b.Scale != 1 {
+		s.WriteString("*" + strconv.FormatFloat(b.Scale, 'g', -1, 64))
+	}
+
+	return s.String()
+}
+
+// Parses a binding written by Binding.String.
+func ParseBinding(s string) (Binding, error) {
+	b := Binding{Scale: 1}
+
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndex(s, "*"); i >= 0 {
+		scale, err := strconv.ParseFloat(strings.TrimSpace(s[i+1:]), 64)
+		if err != nil {
+			return b, fmt.Errorf("actions: bad scale in binding %q", s)
+		}
+		b.Scale, s = scale, strings.TrimSpace(s[:i])
+	}
+
+	parts := strings.Split(s, "+")
+	for _, p := range parts[:len(parts)-1] {
+		found := false
+		for _, m := range modifierNames {
+			if strings.EqualFold(strings.TrimSpace(p), m.name) {
+				b.Modifiers |= m.flag
+				found = true
+			}
+		}
+		if !found {
+			return b, fmt.Errorf("actions: unknown modifier %q in binding %q", p, s)
+		}
+	}
+
+	source := strings.TrimSpace(parts[len(parts)-1])
+	for src, name := range sourceNames {
+		if strings.EqualFold(source, name) {
+			b.Source = src
+			return b, nil
+		}
+	}
+
+	k, ok := parseKey(source)
+	if !ok {
+		return b, fmt.Errorf("actions: unknown key %q in binding %q", source, s)
+	}
+	b.Source, b.Key = SOURCE_KEY, k
+
+	return b, nil
+}
+
+// Returns bindings the way they're saved, separated by commas.
+func FormatBindings(bindings []Binding) string {
+	s := make([]string, len(bindings))
+	for i, b := range bindings {
+		s[i] = b.String()
+	}
+
+	return strings.Join(s, ", ")
+}
+
+// Parses bindings written by FormatBindings.
+func ParseBindings(s string) ([]Binding, error) {
+	var bindings []Binding
+	for _, f := range strings.Split(s, ",") {
+		if strings.TrimSpace(f) == "" {
+			continue
+		}
+
+		b, err := ParseBinding(f)
+		if err != nil {
+			return nil, err
+		}
+		bindings = append(bindings, b)
+	}
+
+	return bindings, nil
+}
@@ -0,0 +1,92 @@
+package atlas
+
+import (
+	"fmt"
+	"image"
+	"path"
+
+	"github.com/losinggeneration/hge"
+	"github.com/losinggeneration/hge/gfx"
+	"github.com/losinggeneration/hge/helpers/sprite"
+	"github.com/losinggeneration/hge/resource"
+)
+
+// An atlas loaded into textures.
+type Atlas struct {
+	*Manifest
+	Textures []*gfx.Texture
+}
+
+// Loads a manifest and its pages. The files are read through the resource
+// system, so they can be in an attached pack.
+func Load(filename string) (*Atlas, error) {
+	data, err := resource.LoadBytes(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := parseManifest(data)
+	if err != nil {
+		return nil, &hge.LoadError{Op: "atlas.Load", Filename: filename, Message: err.Error(), Err: hge.ErrFormat}
+	}
+
+	a := &Atlas{Manifest: m}
+	dir := path.Dir(filename)
+	for _, p := range m.Pages {
+		tex, err := gfx.LoadTexture(path.Join(dir, p.File))
+		if err != nil {
+			a.Free()
+			return nil, err
+		}
+		a.Textures = append(a.Textures, tex)
+	}
+
+	return a, nil
+}
+
+// Makes textures from pages packed at runtime, like the ones Pack returns.
+func New(pages []*image.NRGBA, m *Manifest) (*Atlas, error) {
+	a := &Atlas{Manifest: m}
+
+	for i, page := range pages {
+		tex, err := gfx.NewTextureFromImage(page)
+		if err != nil {
+			a.Free()
+			return nil, fmt.Errorf("atlas: page %d: %w", i, err)
+		}
+		a.Textures = append(a.Textures, tex)
+	}
+
+	return a, nil
+}
+
+// Returns a sprite showing the named region, or false if there's no such
+// region.
+func (a *Atlas) Sprite(name string) (sprite.Sprite, bool) {
+	r, ok := a.Regions[name]
+	if !ok || r.Page < 0 || r.Page >= len(a.Textures) {
+		return sprite.Sprite{}, false
+	}
+
+	return sprite.New(a.Textures[r.Page], float64(r.X), float64(r.Y), float64(r.W), float64(r.H)), true
+}
+
+// Returns a sprite for every region.
+func (a *Atlas) Sprites() map[string]sprite.Sprite {
+	sprites := make(map[string]sprite.Sprite, len(a.Regions))
+	for name := range a.Regions {
+		if s, ok := a.Sprite(name); ok {
+			sprites[name] = s
+		}
+	}
+
+	return sprites
+}
+
+// Frees the textures.
+func (a *Atlas) Free() {
+	for _, tex := range a.Textures {
+		tex.Free()
+	}
+	a.Textures = nil
+}
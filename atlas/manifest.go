@@ -0,0 +1,105 @@
+package atlas
+
+import (
+	"encoding/json"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/losinggeneration/hge"
+)
+
+// Where an image was packed, in pixels on its page.
+type Region struct {
+	Page int `json:"page"`
+	X    int `json:"x"`
+	Y    int `json:"y"`
+	W    int `json:"w"`
+	H    int `json:"h"`
+}
+
+// A page image, its file relative to the manifest.
+type Page struct {
+	File   string `json:"file"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// The manifest describing an atlas:
+//
+//	{
+//		"pages": [{"file": "sprites0.png", "width": 512, "height": 256}],
+//		"regions": {"ship": {"page": 0, "x": 1, "y": 1, "w": 64, "h": 48}}
+//	}
+type Manifest struct {
+	Pages   []Page            `json:"pages"`
+	Regions map[string]Region `json:"regions"`
+}
+
+// Parses a manifest.
+func ParseManifest(data []byte) (*Manifest, error) {
+	m, err := parseManifest(data)
+	if err != nil {
+		return nil, &hge.LoadError{Op: "atlas.ParseManifest", Message: err.Error(), Err: hge.ErrFormat}
+	}
+
+	return m, nil
+}
+
+func parseManifest(data []byte) (*Manifest, error) {
+	m := new(Manifest)
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	if m.Regions == nil {
+		m.Regions = make(map[string]Region)
+	}
+
+	return m, nil
+}
+
+// Returns the names of the regions, sorted.
+func (m *Manifest) Names() []string {
+	names := make([]string, 0, len(m.Regions))
+	for name := range m.Regions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Writes the manifest as indented JSON.
+func (m *Manifest) WriteFile(filename string) error {
+	data, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, append(data, '\n'), 0644)
+}
+
+// Writes the pages as PNGs next to the manifest, named the way the manifest
+// says, and then the manifest itself.
+func Save(filename string, pages []*image.NRGBA, m *Manifest) error {
+	dir := filepath.Dir(filename)
+
+	for i, page := range pages {
+		f, err := os.Create(filepath.Join(dir, filepath.FromSlash(m.Pages[i].File)))
+		if err != nil {
+			return err
+		}
+
+		err = png.Encode(f, page)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return m.WriteFile(filename)
+}
@@ -0,0 +1,67 @@
+package atlas
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/losinggeneration/hge"
+	"github.com/losinggeneration/hge/hgetest"
+)
+
+func TestParseManifest(t *testing.T) {
+	m := &Manifest{
+		Pages:   []Page{{"page0.png", 64, 32}},
+		Regions: map[string]Region{"ship": {0, 1, 2, 16, 8}, "rock": {0, 20, 2, 4, 4}},
+	}
+
+	filename := filepath.Join(t.TempDir(), "atlas.json")
+	if err := m.WriteFile(filename); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := ParseManifest(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, m) {
+		t.Errorf("read back %+v, want %+v", read, m)
+	}
+	if names := read.Names(); !reflect.DeepEqual(names, []string{"rock", "ship"}) {
+		t.Errorf("names %v", names)
+	}
+
+	if m, err := ParseManifest([]byte("{}")); err != nil || m.Regions == nil {
+		t.Errorf("empty manifest: %+v, %v", m, err)
+	}
+
+	for _, data := range []string{"", "{", `{"pages": {}}`, `{"regions": {"ship": {"x": "1"}}}`} {
+		var le *hge.LoadError
+		if _, err := ParseManifest([]byte(data)); !errors.As(err, &le) || !errors.Is(err, hge.ErrFormat) {
+			t.Errorf("%q: %v, want a LoadError with ErrFormat", data, err)
+		}
+	}
+}
+
+func TestLoadBadManifest(t *testing.T) {
+	const name = "testdata/truncated.json"
+
+	_, err := hgetest.Render(hgetest.Scene{
+		Width: 8, Height: 8,
+		Setup: func() error {
+			_, err := Load(name)
+			return err
+		},
+	})
+
+	var le *hge.LoadError
+	if !errors.As(err, &le) || le.Op != "atlas.Load" || le.Filename != name || !errors.Is(err, hge.ErrFormat) {
+		t.Errorf("%v, want a LoadError with ErrFormat", err)
+	}
+}
@@ -0,0 +1,257 @@
+// Package atlas packs many small images into a few large textures and looks
+// up sprites in them by name.
+//
+// Atlases are usually built offline with cmd/hgeatlas, which writes the page
+// images and a JSON manifest. Load reads them back into textures and
+// sprites.
+package atlas
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"sort"
+)
+
+var ErrTooBig = errors.New("atlas: image doesn't fit on a page")
+
+// An image to pack and the name its region is found by.
+type Image struct {
+	Name  string
+	Image image.Image
+}
+
+// How images are packed.
+type Options struct {
+	// The largest a page can be, 1024x1024 if not set
+	MaxWidth, MaxHeight int
+	// Transparent pixels left between images
+	Padding int
+	// How many times the edge pixels of each image are repeated around it, so
+	// filtering near the edge doesn't pull in the neighbours
+	Extrude int
+	// Round page sizes up to a power of two
+	PowerOfTwo bool
+}
+
+// The default page size.
+const DefaultPageSize = 1024
+
+// Packs the images onto as few pages as it can and returns the pages along
+// with the manifest of where each image went. Page files in the manifest are
+// named name0.png, name1.png and so on.
+func Pack(name string, images []Image, opts Options) ([]*image.NRGBA, *Manifest, error) {
+	if opts.MaxWidth <= 0 {
+		opts.MaxWidth = DefaultPageSize
+	}
+	if opts.MaxHeight <= 0 {
+		opts.MaxHeight = DefaultPageSize
+	}
+	if opts.Padding < 0 {
+		opts.Padding = 0
+	}
+	if opts.Extrude < 0 {
+		opts.Extrude = 0
+	}
+
+	// Biggest first packs tighter, the name keeps the result the same from
+	// run to run
+	order := make([]int, len(images))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := images[order[i]].Image.Bounds(), images[order[j]].Image.Bounds()
+		sa, sb := max(a.Dx(), a.Dy()), max(b.Dx(), b.Dy())
+		if sa != sb {
+			return sa > sb
+		}
+		if a.Dx()*a.Dy() != b.Dx()*b.Dy() {
+			return a.Dx()*a.Dy() > b.Dx()*b.Dy()
+		}
+		return images[order[i]].Name < images[order[j]].Name
+	})
+
+	m := &Manifest{Regions: make(map[string]Region)}
+	var bins []*maxRects
+	page := make([]int, len(images))
+
+	border := 2 * opts.Extrude
+	for _, i := range order {
+		img := images[i]
+		if _, ok := m.Regions[img.Name]; ok {
+			return nil, nil, fmt.Errorf("atlas: %q is in the atlas twice", img.Name)
+		}
+
+		b := img.Image.Bounds()
+		w, h := b.Dx()+border+opts.Padding, b.Dy()+border+opts.Padding
+
+		p, ok := -1, false
+		var at image.Point
+		for n, bin := range bins {
+			if at, ok = bin.insert(w, h); ok {
+				p = n
+				break
+			}
+		}
+		if !ok {
+			bin := newMaxRects(opts.MaxWidth+opts.Padding, opts.MaxHeight+opts.Padding)
+			if at, ok = bin.insert(w, h); !ok {
+				return nil, nil, fmt.Errorf("%w: %s is %dx%d", ErrTooBig, img.Name, b.Dx(), b.Dy())
+			}
+			bins = append(bins, bin)
+			p = len(bins) - 1
+		}
+
+		page[i] = p
+		m.Regions[img.Name] = Region{
+			Page: p,
+			X:    at.X + opts.Extrude,
+			Y:    at.Y + opts.Extrude,
+			W:    b.Dx(),
+			H:    b.Dy(),
+		}
+	}
+
+	pages := make([]*image.NRGBA, len(bins))
+	for n, bin := range bins {
+		w, h := bin.extent(opts.Padding)
+		if opts.PowerOfTwo {
+			w, h = powerOfTwo(w), powerOfTwo(h)
+		}
+
+		pages[n] = image.NewNRGBA(image.Rect(0, 0, w, h))
+		m.Pages = append(m.Pages, Page{File: fmt.Sprintf("%s%d.png", name, n), Width: w, Height: h})
+	}
+
+	for i, img := range images {
+		r := m.Regions[img.Name]
+		dst := pages[page[i]]
+		dr := image.Rect(r.X, r.Y, r.X+r.W, r.Y+r.H)
+		draw.Draw(dst, dr, img.Image, img.Image.Bounds().Min, draw.Src)
+		extrude(dst, dr, opts.Extrude)
+	}
+
+	return pages, m, nil
+}
+
+// Repeats the pixels on the edges of r outwards n times.
+func extrude(dst *image.NRGBA, r image.Rectangle, n int) {
+	if n <= 0 || r.Empty() {
+		return
+	}
+
+	for i := 1; i <= n; i++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			dst.Set(x, r.Min.Y-i, dst.At(x, r.Min.Y))
+			dst.Set(x, r.Max.Y-1+i, dst.At(x, r.Max.Y-1))
+		}
+	}
+
+	// The columns include the rows just extruded, which fills the corners
+	for i := 1; i <= n; i++ {
+		for y := r.Min.Y - n; y < r.Max.Y+n; y++ {
+			dst.Set(r.Min.X-i, y, dst.At(r.Min.X, y))
+			dst.Set(r.Max.X-1+i, y, dst.At(r.Max.X-1, y))
+		}
+	}
+}
+
+func powerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+
+	return p
+}
+
+// A max-rects bin: it keeps every maximal free rectangle, places each new
+// rectangle in the free one that leaves the shortest side over, and splits
+// the free rectangles it overlaps.
+type maxRects struct {
+	free []image.Rectangle
+	used []image.Rectangle
+}
+
+func newMaxRects(w, h int) *maxRects {
+	return &maxRects{free: []image.Rectangle{image.Rect(0, 0, w, h)}}
+}
+
+func (m *maxRects) insert(w, h int) (image.Point, bool) {
+	best := -1
+	bestShort, bestLong := 0, 0
+
+	for i, f := range m.free {
+		if f.Dx() < w || f.Dy() < h {
+			continue
+		}
+
+		dx, dy := f.Dx()-w, f.Dy()-h
+		short, long := min(dx, dy), max(dx, dy)
+		if best < 0 || short < bestShort || (short == bestShort && long < bestLong) {
+			best, bestShort, bestLong = i, short, long
+		}
+	}
+	if best < 0 {
+		return image.Point{}, false
+	}
+
+	at := m.free[best].Min
+	r := image.Rect(at.X, at.Y, at.X+w, at.Y+h)
+	m.split(r)
+	m.used = append(m.used, r)
+
+	return at, true
+}
+
+// Replaces every free rectangle r overlaps with the up to four parts of it
+// that are left, then drops the ones inside others.
+func (m *maxRects) split(r image.Rectangle) {
+	var free []image.Rectangle
+
+	for _, f := range m.free {
+		if !f.Overlaps(r) {
+			free = append(free, f)
+			continue
+		}
+
+		if r.Min.X > f.Min.X {
+			free = append(free, image.Rect(f.Min.X, f.Min.Y, r.Min.X, f.Max.Y))
+		}
+		if r.Max.X < f.Max.X {
+			free = append(free, image.Rect(r.Max.X, f.Min.Y, f.Max.X, f.Max.Y))
+		}
+		if r.Min.Y > f.Min.Y {
+			free = append(free, image.Rect(f.Min.X, f.Min.Y, f.Max.X, r.Min.Y))
+		}
+		if r.Max.Y < f.Max.Y {
+			free = append(free, image.Rect(f.Min.X, r.Max.Y, f.Max.X, f.Max.Y))
+		}
+	}
+
+	m.free = m.free[:0]
+	for i, a := range free {
+		contained := false
+		for j, b := range free {
+			if i != j && a.In(b) && (a != b || i > j) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			m.free = append(m.free, a)
+		}
+	}
+}
+
+// Returns the size of the area used, without the padding after the last
+// rectangles.
+func (m *maxRects) extent(padding int) (w, h int) {
+	for _, r := range m.used {
+		w, h = max(w, r.Max.X-padding), max(h, r.Max.Y-padding)
+	}
+
+	return w, h
+}
@@ -0,0 +1,240 @@
+package atlas
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+// Makes images of the sizes given, each filled with its own color.
+func solidImages(sizes ...image.Point) []Image {
+	images := make([]Image, len(sizes))
+	for i, s := range sizes {
+		img := image.NewNRGBA(image.Rect(0, 0, s.X, s.Y))
+		c := color.NRGBA{uint8(i*37 + 1), uint8(i * 91), uint8(i * 13), 0xFF}
+		for y := 0; y < s.Y; y++ {
+			for x := 0; x < s.X; x++ {
+				img.SetNRGBA(x, y, c)
+			}
+		}
+		images[i] = Image{fmt.Sprintf("img%d", i), img}
+	}
+
+	return images
+}
+
+func sizes(n int, seed int64, maxSize int) []image.Point {
+	r := rand.New(rand.NewSource(seed))
+	s := make([]image.Point, n)
+	for i := range s {
+		s[i] = image.Pt(1+r.Intn(maxSize), 1+r.Intn(maxSize))
+	}
+
+	return s
+}
+
+// Checks every image is on its page, in bounds with its extruded border,
+// apart from the others by the padding, and drawn with its border repeated.
+func checkPacked(t *testing.T, name string, images []Image, pages []*image.NRGBA, m *Manifest, opts Options) {
+	t.Helper()
+
+	maxW, maxH := opts.MaxWidth, opts.MaxHeight
+	if maxW == 0 {
+		maxW, maxH = DefaultPageSize, DefaultPageSize
+	}
+
+	if len(pages) != len(m.Pages) {
+		t.Fatalf("%s: %d pages and %d in the manifest", name, len(pages), len(m.Pages))
+	}
+	for n, p := range m.Pages {
+		b := pages[n].Bounds()
+		if b.Dx() != p.Width || b.Dy() != p.Height {
+			t.Errorf("%s: page %d is %v, the manifest says %dx%d", name, n, b.Size(), p.Width, p.Height)
+		}
+		if opts.PowerOfTwo {
+			if p.Width&(p.Width-1) != 0 || p.Height&(p.Height-1) != 0 {
+				t.Errorf("%s: page %d is %dx%d, not a power of two", name, n, p.Width, p.Height)
+			}
+		} else if p.Width > maxW || p.Height > maxH {
+			t.Errorf("%s: page %d is %dx%d, bigger than %dx%d", name, n, p.Width, p.Height, maxW, maxH)
+		}
+	}
+
+	// Where each image went with its extruded border
+	used := make(map[int][]image.Rectangle)
+	for _, img := range images {
+		r, ok := m.Regions[img.Name]
+		if !ok {
+			t.Errorf("%s: %s isn't in the manifest", name, img.Name)
+			continue
+		}
+		if r.W != img.Image.Bounds().Dx() || r.H != img.Image.Bounds().Dy() {
+			t.Errorf("%s: %s is %dx%d, want %v", name, img.Name, r.W, r.H, img.Image.Bounds().Size())
+		}
+		if r.Page < 0 || r.Page >= len(pages) {
+			t.Errorf("%s: %s is on page %d of %d", name, img.Name, r.Page, len(pages))
+			continue
+		}
+
+		e := opts.Extrude
+		outer := image.Rect(r.X-e, r.Y-e, r.X+r.W+e, r.Y+r.H+e)
+		if !outer.In(pages[r.Page].Bounds()) {
+			t.Errorf("%s: %s at %v is off page %d %v", name, img.Name, outer, r.Page, pages[r.Page].Bounds())
+		}
+
+		for _, o := range used[r.Page] {
+			padded := image.Rectangle{o.Min, o.Max.Add(image.Pt(opts.Padding, opts.Padding))}
+			mine := image.Rectangle{outer.Min, outer.Max.Add(image.Pt(opts.Padding, opts.Padding))}
+			if padded.Overlaps(outer) || mine.Overlaps(o) {
+				t.Errorf("%s: %s at %v is within %d of %v", name, img.Name, outer, opts.Padding, o)
+			}
+		}
+		used[r.Page] = append(used[r.Page], outer)
+
+		if x, y, ok := filled(pages[r.Page], outer, img.Image.At(0, 0)); !ok {
+			t.Errorf("%s: %s pixel %d,%d is %v, want %v", name, img.Name, x, y, pages[r.Page].At(x, y), img.Image.At(0, 0))
+		}
+	}
+
+	// Everything else is left transparent
+	for n, page := range pages {
+		b := page.Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+		next:
+			for x := b.Min.X; x < b.Max.X; x++ {
+				for _, r := range used[n] {
+					if image.Pt(x, y).In(r) {
+						continue next
+					}
+				}
+				if c := page.NRGBAAt(x, y); c.A != 0 {
+					t.Errorf("%s: page %d pixel %d,%d is %v outside every image", name, n, x, y, c)
+					return
+				}
+			}
+		}
+	}
+}
+
+// Reports whether r is all c on page, or the first pixel that isn't.
+func filled(page *image.NRGBA, r image.Rectangle, c color.Color) (x, y int, ok bool) {
+	r = r.Intersect(page.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if page.At(x, y) != c {
+				return x, y, false
+			}
+		}
+	}
+
+	return 0, 0, true
+}
+
+func TestPack(t *testing.T) {
+	tests := []struct {
+		name  string
+		sizes []image.Point
+		opts  Options
+		pages int
+	}{
+		{"one", []image.Point{{10, 20}}, Options{}, 1},
+		{"exact fit", []image.Point{{32, 32}, {32, 32}, {32, 32}, {32, 32}}, Options{MaxWidth: 64, MaxHeight: 64}, 1},
+		{"spill to a second page", []image.Point{{32, 32}, {32, 32}, {32, 32}, {32, 32}, {32, 32}}, Options{MaxWidth: 64, MaxHeight: 64}, 2},
+		{"padding", []image.Point{{31, 31}, {31, 31}, {31, 31}, {31, 31}}, Options{MaxWidth: 64, MaxHeight: 64, Padding: 2}, 1},
+		{"padding spills", []image.Point{{32, 32}, {32, 32}, {32, 32}, {32, 32}}, Options{MaxWidth: 64, MaxHeight: 64, Padding: 1}, 4},
+		{"extrude", []image.Point{{30, 30}, {30, 30}, {30, 30}, {30, 30}}, Options{MaxWidth: 64, MaxHeight: 64, Extrude: 1}, 1},
+		{"extrude and padding", sizes(40, 1, 24), Options{MaxWidth: 128, MaxHeight: 128, Padding: 2, Extrude: 2}, 0},
+		{"thin strips", []image.Point{{100, 1}, {1, 100}, {100, 1}, {1, 100}, {50, 3}}, Options{MaxWidth: 128, MaxHeight: 128, Padding: 1}, 1},
+		{"power of two", sizes(12, 2, 40), Options{MaxWidth: 256, MaxHeight: 256, PowerOfTwo: true}, 0},
+		{"many small", sizes(200, 3, 16), Options{MaxWidth: 256, MaxHeight: 256, Padding: 1}, 0},
+		{"many pages", sizes(60, 4, 60), Options{MaxWidth: 128, MaxHeight: 128, Extrude: 1}, 0},
+		{"not square", sizes(30, 5, 20), Options{MaxWidth: 200, MaxHeight: 50, Padding: 3}, 0},
+	}
+
+	for _, test := range tests {
+		images := solidImages(test.sizes...)
+		pages, m, err := Pack("page", images, test.opts)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		if test.pages > 0 && len(pages) != test.pages {
+			t.Errorf("%s: %d pages, want %d", test.name, len(pages), test.pages)
+		}
+		checkPacked(t, test.name, images, pages, m, test.opts)
+	}
+}
+
+func TestPackTooBig(t *testing.T) {
+	tests := []struct {
+		name string
+		size image.Point
+		opts Options
+	}{
+		{"wider than a page", image.Pt(65, 1), Options{MaxWidth: 64, MaxHeight: 64}},
+		{"taller than a page", image.Pt(1, 2000), Options{}},
+		{"fits without the extrude", image.Pt(64, 64), Options{MaxWidth: 64, MaxHeight: 64, Extrude: 1}},
+	}
+
+	for _, test := range tests {
+		images := solidImages(image.Pt(8, 8), test.size)
+		if _, _, err := Pack("page", images, test.opts); !errors.Is(err, ErrTooBig) {
+			t.Errorf("%s: %v, want ErrTooBig", test.name, err)
+		}
+	}
+
+	// Padding only goes between images, so a full page of one still fits
+	images := solidImages(image.Pt(64, 64))
+	if _, _, err := Pack("page", images, Options{MaxWidth: 64, MaxHeight: 64, Padding: 4}); err != nil {
+		t.Errorf("padding a full page: %v", err)
+	}
+}
+
+func TestPackSameName(t *testing.T) {
+	images := solidImages(image.Pt(4, 4), image.Pt(4, 4))
+	images[1].Name = images[0].Name
+	if _, _, err := Pack("page", images, Options{}); err == nil {
+		t.Error("two images with the same name didn't fail")
+	}
+}
+
+func TestMaxRects(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		bin := newMaxRects(100, 80)
+		bounds := image.Rect(0, 0, 100, 80)
+
+		for i := 0; i < 100; i++ {
+			w, h := 1+r.Intn(30), 1+r.Intn(30)
+			at, ok := bin.insert(w, h)
+			if !ok {
+				continue
+			}
+
+			placed := image.Rect(at.X, at.Y, at.X+w, at.Y+h)
+			if !placed.In(bounds) {
+				t.Fatalf("seed %d: %v is outside %v", seed, placed, bounds)
+			}
+			for _, u := range bin.used[:len(bin.used)-1] {
+				if u.Overlaps(placed) {
+					t.Fatalf("seed %d: %v overlaps %v", seed, placed, u)
+				}
+			}
+		}
+
+		for _, f := range bin.free {
+			if !f.In(bounds) {
+				t.Fatalf("seed %d: free %v is outside %v", seed, f, bounds)
+			}
+			for _, u := range bin.used {
+				if u.Overlaps(f) {
+					t.Fatalf("seed %d: free %v overlaps used %v", seed, f, u)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package hge
+
+import (
+	"errors"
+	"image"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Handle types the backends hand out for their resources. A zero handle
+// means the resource couldn't be created.
+type (
+	HTexture uintptr
+	HTarget  uintptr
+	HEffect  uintptr
+	HMusic   uintptr
+	HStream  uintptr
+	HChannel uintptr
+)
+
+// The vertex backends render with. It's laid out the same as gfx.Vertex and
+// the hge-unix vertex so either can be cast to it.
+type BackendVertex struct {
+	X, Y   float32 // screen position
+	Z      float32 // Z-buffer depth 0..1
+	Color  Dword   // color
+	TX, TY float32 // texture coordinates
+}
+
+// The input event backends fill in. It's laid out the same as
+// input.InputEvent and the hge-unix event.
+type BackendInputEvent struct {
+	Type  int     // event type
+	Key   int     // key code
+	Flags int     // event flags
+	Chr   int     // character code
+	Wheel int     // wheel shift
+	X     float32 // mouse cursor x-coordinate
+	Y     float32 // mouse cursor y-coordinate
+}
+
+// The system part of a backend: states, the main loop and logging.
+type SystemBackend interface {
+	// Create and Release are reference counted the same way the C++
+	// hgeCreate and Release are.
+	Create(ver int)
+	Release()
+
+	Initiate() bool
+	Shutdown()
+	Start() bool
+	ErrorMessage() string
+	Log(str string)
+	Launch(url string) bool
+	Snapshot(filename string)
+
+	SetStateBool(state BoolState, value bool)
+	SetStateFunc(state FuncState, value StateFunc)
+	SetStateHwnd(state HwndState, value Hwnd)
+	SetStateInt(state IntState, value int)
+	SetStateString(state StringState, value string)
+
+	StateBool(state BoolState) bool
+	StateFunc(state FuncState) StateFunc
+	StateHwnd(state HwndState) Hwnd
+	StateInt(state IntState) int
+	StateString(state StringState) string
+}
+
+// The resource part of a backend.
+type ResourceBackend interface {
+	// Returns nil if the resource couldn't be loaded
+	ResourceLoad(filename string) []byte
+	ResourceFree(data []byte)
+	// An empty password means the pack isn't password protected
+	ResourceAttachPack(filename, password string) bool
+	ResourceRemovePack(filename string)
+	ResourceRemoveAllPacks()
+	// An empty filename returns the application path
+	ResourceMakePath(filename string) string
+	// An empty wildcard continues the previous enumeration
+	ResourceEnumFiles(wildcard string) string
+	ResourceEnumFolders(wildcard string) string
+}
+
+// The ini part of a backend.
+type IniBackend interface {
+	IniSetInt(section, name string, value int)
+	IniGetInt(section, name string, defVal int) int
+	IniSetFloat(section, name string, value float64)
+	IniGetFloat(section, name string, defVal float64) float64
+	IniSetString(section, name, value string)
+	IniGetString(section, name, defVal string) string
+}
+
+// The random number part of a backend.
+type RandomBackend interface {
+	RandomSeed(seed int)
+	RandomInt(min, max int) int
+	RandomFloat(min, max float64) float64
+}
+
+// The timer part of a backend.
+type TimerBackend interface {
+	Time() float64
+	Delta() float64
+	FPS() int
+}
+
+// The sound part of a backend.
+type SoundBackend interface {
+	EffectLoad(filename string, size Dword) HEffect
+	EffectFree(eff HEffect)
+	EffectPlay(eff HEffect) HChannel
+	EffectPlayEx(eff HEffect, volume, pan int, pitch float64, loop bool) HChannel
+
+	MusicLoad(filename string, size Dword) HMusic
+	MusicFree(mus HMusic)
+	MusicPlay(mus HMusic, loop bool, volume, order, row int) HChannel
+	MusicSetAmplification(mus HMusic, ampl int)
+	MusicAmplification(mus HMusic) int
+	MusicLength(mus HMusic) int
+	MusicSetPos(mus HMusic, order, row int)
+	MusicPos(mus HMusic) (order, row int, ok bool)
+	MusicSetInstrVolume(mus HMusic, instr, volume int)
+	MusicInstrVolume(mus HMusic, instr int) int
+	MusicSetChannelVolume(mus HMusic, channel, volume int)
+	MusicChannelVolume(mus HMusic, channel int) int
+
+	StreamLoad(filename string, size Dword) HStream
+	StreamFree(stream HStream)
+	StreamPlay(stream HStream, loop bool, volume int) HChannel
+
+	ChannelSetPanning(chn HChannel, pan int)
+	ChannelSetVolume(chn HChannel, volume int)
+	ChannelSetPitch(chn HChannel, pitch float64)
+	ChannelPause(chn HChannel)
+	ChannelResume(chn HChannel)
+	ChannelStop(chn HChannel)
+	ChannelPauseAll()
+	ChannelResumeAll()
+	ChannelStopAll()
+	ChannelIsPlaying(chn HChannel) bool
+	ChannelLength(chn HChannel) float64
+	ChannelPos(chn HChannel) float64
+	ChannelSetPos(chn HChannel, seconds float64)
+	ChannelSlideTo(chn HChannel, time float64, volume, pan int, pitch float64)
+	ChannelIsSliding(chn HChannel) bool
+}
+
+// The input part of a backend.
+type InputBackend interface {
+	MousePos() (x, y float64)
+	SetMousePos(x, y float64)
+	MouseWheel() int
+	IsMouseOver() bool
+	KeyDown(key int) bool
+	KeyUp(key int) bool
+	KeyState(key int) bool
+	KeyName(key int) string
+	Key() int
+	Char() int
+	Event(e *BackendInputEvent) bool
+}
+
+// The graphics part of a backend.
+type GfxBackend interface {
+	BeginScene(target HTarget) bool
+	EndScene()
+	Clear(color Dword)
+	RenderLine(x1, y1, x2, y2 float64, color Dword, z float64)
+	RenderTriple(v *[3]BackendVertex, tex HTexture, blend int)
+	RenderQuad(v *[4]BackendVertex, tex HTexture, blend int)
+	// Returns nil if a batch couldn't be started
+	StartBatch(primType int, tex HTexture, blend int) (v *BackendVertex, maxPrim int)
+	FinishBatch(prim int)
+	SetClipping(x, y, w, h int)
+	SetTransform(x, y, dx, dy, rot, hscale, vscale float64)
+
+	TargetCreate(width, height int, zbuffer bool) HTarget
+	TargetFree(target HTarget)
+	TargetTexture(target HTarget) HTexture
+
+	TextureCreate(width, height int) HTexture
+	TextureLoad(filename string, size Dword, mipmap bool) HTexture
+	TextureFree(tex HTexture)
+	TextureWidth(tex HTexture, original bool) int
+	TextureHeight(tex HTexture, original bool) int
+	TextureLock(tex HTexture, readonly bool, left, top, width, height int) *Dword
+	TextureUnlock(tex HTexture)
+}
+
+// A Backend does the actual work behind an HGE instance. hge-unix is used
+// when it's compiled in, otherwise the pure Go headless backend is used.
+type Backend interface {
+	SystemBackend
+	ResourceBackend
+	IniBackend
+	RandomBackend
+	TimerBackend
+	SoundBackend
+	InputBackend
+	GfxBackend
+}
+
+// Backends that draw in memory, like the headless one, can also hand back
+// copies of what they've drawn.
+type ImageBackend interface {
+	ScreenImage() *image.RGBA
+	TargetImage(target HTarget) *image.RGBA
+}
+
+// The environment variable that selects a backend by name.
+const BackendEnv = "HGE_BACKEND"
+
+var (
+	ErrUnknownBackend = errors.New("hge: unknown backend")
+	ErrBackendInUse   = errors.New("hge: a different backend is already in use")
+)
+
+var (
+	backendsMu     sync.Mutex
+	backends       = make(map[string]func() Backend)
+	defaultBackend string
+	backendName    string
+	backend        Backend
+)
+
+// Makes a backend available by name. Backends registered with preferred set
+// are used when neither UseBackend nor HGE_BACKEND pick one.
+func RegisterBackend(name string, open func() Backend, preferred bool) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	if open == nil {
+		panic("hge: RegisterBackend open func is nil")
+	}
+	if _, dup := backends[name]; dup {
+		panic("hge: RegisterBackend called twice for backend " + name)
+	}
+
+	backends[name] = open
+	if preferred || defaultBackend == "" {
+		defaultBackend = name
+	}
+}
+
+// Returns the names of the registered backends.
+func Backends() []string {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	var names []string
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Selects the backend to run on. It must be called before any HGE instance
+// (including the ones the subsystem packages hold) first touches the engine.
+func UseBackend(name string) error {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	if _, ok := backends[name]; !ok {
+		return ErrUnknownBackend
+	}
+	if backend != nil && backendName != name {
+		return ErrBackendInUse
+	}
+
+	backendName = name
+	return nil
+}
+
+// Returns the name of the backend in use, or the one that will be used.
+func BackendName() string {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	return selectBackend()
+}
+
+func selectBackend() string {
+	if backendName != "" {
+		return backendName
+	}
+	if name := os.Getenv(BackendEnv); name != "" {
+		if _, ok := backends[name]; ok {
+			return name
+		}
+	}
+
+	return defaultBackend
+}
+
+// Returns the backend in use, or nil if none has been opened yet.
+func openedBackend() Backend {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	return backend
+}
+
+func openBackend() Backend {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	if backend == nil {
+		backendName = selectBackend()
+		backend = backends[backendName]()
+	}
+
+	return backend
+}
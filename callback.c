@@ -1,3 +1,5 @@
+//go:build !headless
+
 #include "callback.h"
 
 extern BOOL goFrameFunc();
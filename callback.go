@@ -1,38 +1,48 @@
+//go:build !headless
+
 package hge
 
 // #include "callback.h"
 import "C"
 
-type StateFunc func() int
-
 var funcCBs []StateFunc = make([]StateFunc, EXITFUNC+1)
 
+func callFunc(state FuncState) int {
+	if funcCBs[state] == nil {
+		return 0
+	}
+
+	return funcCBs[state]()
+}
+
 //export goFrameFunc
 func goFrameFunc() int {
-	return funcCBs[FRAMEFUNC]()
+	RunPending()
+	return callFunc(FRAMEFUNC)
 }
 
 //export goRenderFunc
 func goRenderFunc() int {
-	return funcCBs[RENDERFUNC]()
+	return callFunc(RENDERFUNC)
 }
 
 //export goFocusLostFunc
 func goFocusLostFunc() int {
-	return funcCBs[FOCUSLOSTFUNC]()
+	return callFunc(FOCUSLOSTFUNC)
 }
 
 //export goFocusGainFunc
 func goFocusGainFunc() int {
-	return funcCBs[FOCUSGAINFUNC]()
+	return callFunc(FOCUSGAINFUNC)
 }
 
 //export goGfxRestoreFunc
 func goGfxRestoreFunc() int {
-	return funcCBs[GFXRESTOREFUNC]()
+	runGfxRestoreHooks()
+	return callFunc(GFXRESTOREFUNC)
 }
 
 //export goExitFunc
 func goExitFunc() int {
-	return funcCBs[EXITFUNC]()
+	return callFunc(EXITFUNC)
 }
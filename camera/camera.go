@@ -0,0 +1,266 @@
+// Package camera scrolls, zooms and rotates the view of a world using
+// gfx.SetTransform, and clips it to a viewport with gfx.SetClipping.
+package camera
+
+import (
+	"math"
+
+	"github.com/losinggeneration/hge"
+	"github.com/losinggeneration/hge/gfx"
+	"github.com/losinggeneration/hge/input"
+	"github.com/losinggeneration/hge/rand"
+)
+
+// An area of the screen, in pixels.
+type Viewport struct {
+	X, Y, W, H int
+}
+
+// Reports whether a point on the screen is inside the viewport.
+func (v Viewport) Contains(sx, sy float64) bool {
+	return sx >= float64(v.X) && sx < float64(v.X+v.W) && sy >= float64(v.Y) && sy < float64(v.Y+v.H)
+}
+
+func (v Viewport) center() (x, y float64) {
+	return float64(v.X) + float64(v.W)/2, float64(v.Y) + float64(v.H)/2
+}
+
+// A Camera shows the world around X, Y in the middle of its viewport.
+type Camera struct {
+	Viewport Viewport
+
+	// The world point in the middle of the viewport
+	X, Y float64
+	// How many pixels a world unit covers
+	Zoom float64
+	// Turns the view, in radians
+	Rotation float64
+
+	// How quickly the camera catches up with what it follows, 0 snaps to it.
+	// Higher is faster: it covers about 1-e^(-Smoothing*dt) of the distance
+	// each update.
+	Smoothing float64
+	// The size of the box around the middle of the view, in world units, the
+	// followed point can move in without the camera moving
+	DeadZoneW, DeadZoneH float64
+
+	following          bool
+	targetX, targetY   float64
+	bounded            bool
+	bounds             [4]float64
+	shake, shakeLength float64
+	shakeTime          float64
+	shakeX, shakeY     float64
+}
+
+var cameraHGE *hge.HGE
+
+func init() {
+	cameraHGE = hge.New()
+}
+
+// Creates a camera looking at 0, 0 through the given viewport.
+func New(x, y, w, h int) *Camera {
+	return &Camera{Viewport: Viewport{x, y, w, h}, Zoom: 1}
+}
+
+// Creates a camera for the whole screen, with the middle of the screen at
+// the middle of the view the same as with no camera.
+func NewScreen() *Camera {
+	w, h := cameraHGE.GetInt(hge.SCREENWIDTH), cameraHGE.GetInt(hge.SCREENHEIGHT)
+
+	c := New(0, 0, w, h)
+	c.X, c.Y = float64(w)/2, float64(h)/2
+
+	return c
+}
+
+// Moves the camera straight to a world point.
+func (c *Camera) LookAt(x, y float64) {
+	c.X, c.Y = x, y
+	c.targetX, c.targetY = x, y
+	c.clamp()
+}
+
+// Makes the camera follow a world point from the next Update. Call it every
+// frame with where the followed thing is.
+func (c *Camera) Follow(x, y float64) {
+	c.following = true
+	c.targetX, c.targetY = x, y
+}
+
+// Stops following.
+func (c *Camera) Unfollow() {
+	c.following = false
+}
+
+// Keeps the view inside a part of the world. If the part is smaller than the
+// view, the view is centered on it. Rotation isn't taken into account.
+func (c *Camera) SetBounds(minX, minY, maxX, maxY float64) {
+	c.bounded = true
+	c.bounds = [4]float64{minX, minY, maxX, maxY}
+	c.clamp()
+}
+
+// Lets the view go anywhere.
+func (c *Camera) ClearBounds() {
+	c.bounded = false
+}
+
+// Shakes the view by up to intensity pixels, dying down over duration
+// seconds.
+func (c *Camera) Shake(intensity, duration float64) {
+	c.shake, c.shakeLength, c.shakeTime = intensity, duration, duration
+}
+
+// Moves the camera towards what it's following and moves the shake on.
+func (c *Camera) Update(dt float64) {
+	if c.following {
+		x, y := c.X, c.Y
+
+		// Only move far enough to put the target back on the dead zone edge
+		hw, hh := c.DeadZoneW/2, c.DeadZoneH/2
+		if c.targetX < x-hw {
+			x = c.targetX + hw
+		} else if c.targetX > x+hw {
+			x = c.targetX - hw
+		}
+		if c.targetY < y-hh {
+			y = c.targetY + hh
+		} else if c.targetY > y+hh {
+			y = c.targetY - hh
+		}
+
+		if c.Smoothing > 0 {
+			t := 1 - math.Exp(-c.Smoothing*dt)
+			x = c.X + (x-c.X)*t
+			y = c.Y + (y-c.Y)*t
+		}
+
+		c.X, c.Y = x, y
+		c.clamp()
+	}
+
+	c.shakeX, c.shakeY = 0, 0
+	if c.shakeTime > 0 {
+		c.shakeTime -= dt
+		if c.shakeTime > 0 {
+			s := c.shake * c.shakeTime / c.shakeLength
+			c.shakeX = rand.Float64(-s, s)
+			c.shakeY = rand.Float64(-s, s)
+		}
+	}
+}
+
+func (c *Camera) zoom() float64 {
+	if c.Zoom <= 0 {
+		return 1
+	}
+
+	return c.Zoom
+}
+
+func (c *Camera) clamp() {
+	if !c.bounded {
+		return
+	}
+
+	z := c.zoom()
+	hw, hh := float64(c.Viewport.W)/2/z, float64(c.Viewport.H)/2/z
+	c.X = clampAxis(c.X, hw, c.bounds[0], c.bounds[2])
+	c.Y = clampAxis(c.Y, hh, c.bounds[1], c.bounds[3])
+}
+
+func clampAxis(v, half, min, max float64) float64 {
+	if max-min < half*2 {
+		return (min + max) / 2
+	}
+
+	return math.Max(min+half, math.Min(max-half, v))
+}
+
+// Returns the part of the world the view covers. A rotated view covers the
+// box around its corners.
+func (c *Camera) Visible() (minX, minY, maxX, maxY float64) {
+	z := c.zoom()
+	hw, hh := float64(c.Viewport.W)/2/z, float64(c.Viewport.H)/2/z
+
+	if c.Rotation != 0 {
+		sin, cos := math.Sincos(c.Rotation)
+		sin, cos = math.Abs(sin), math.Abs(cos)
+		hw, hh = hw*cos+hh*sin, hw*sin+hh*cos
+	}
+
+	return c.X - hw, c.Y - hh, c.X + hw, c.Y + hh
+}
+
+// Converts a world point to a point on the screen.
+func (c *Camera) WorldToScreen(wx, wy float64) (sx, sy float64) {
+	z := c.zoom()
+	sin, cos := math.Sincos(c.Rotation)
+	x, y := (wx-c.X)*z, (wy-c.Y)*z
+	cx, cy := c.Viewport.center()
+
+	// The same rotation SetTransform applies
+	return cos*x + sin*y + cx + c.shakeX, -sin*x + cos*y + cy + c.shakeY
+}
+
+// Converts a point on the screen to a world point.
+func (c *Camera) ScreenToWorld(sx, sy float64) (wx, wy float64) {
+	z := c.zoom()
+	sin, cos := math.Sincos(c.Rotation)
+	cx, cy := c.Viewport.center()
+	x, y := sx-cx-c.shakeX, sy-cy-c.shakeY
+
+	return (cos*x-sin*y)/z + c.X, (sin*x+cos*y)/z + c.Y
+}
+
+// Returns where the mouse is in the world, and whether it's over this
+// camera's viewport.
+func (c *Camera) Mouse() (wx, wy float64, over bool) {
+	var m input.Mouse
+	sx, sy := m.Pos()
+	wx, wy = c.ScreenToWorld(sx, sy)
+
+	return wx, wy, c.Viewport.Contains(sx, sy)
+}
+
+// Sets the clipping and transform for the camera.
+func (c *Camera) Apply() {
+	cx, cy := c.Viewport.center()
+	z := c.zoom()
+
+	gfx.SetClipping(c.Viewport.X, c.Viewport.Y, c.Viewport.W, c.Viewport.H)
+	gfx.SetTransform(c.X, c.Y, cx-c.X+c.shakeX, cy-c.Y+c.shakeY, c.Rotation, z, z)
+}
+
+// Clears the clipping and transform.
+func Reset() {
+	gfx.SetTransform()
+	gfx.SetClipping()
+}
+
+// Returns the transform from the world to the screen.
+func (c *Camera) Matrix() gfx.Matrix {
+	cx, cy := c.Viewport.center()
+	z := c.zoom()
+
+	return gfx.TransformMatrix(c.X, c.Y, cx-c.X+c.shakeX, cy-c.Y+c.shakeY, c.Rotation, z, z)
+}
+
+// Calls render with the camera's clipping and transform pushed, then pops
+// them, so cameras can be used inside other transforms.
+func (c *Camera) Render(render func()) {
+	gfx.PushClipping(c.Viewport.X, c.Viewport.Y, c.Viewport.W, c.Viewport.H)
+	gfx.PushTransform(c.Matrix())
+	render()
+	gfx.PopTransform()
+	gfx.PopClipping()
+}
+
+// Renders through each camera in turn, for split screen.
+func RenderAll(cameras []*Camera, render func(c *Camera)) {
+	for _, c := range cameras {
+		c.Render(func() { render(c) })
+	}
+}
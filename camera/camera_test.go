@@ -0,0 +1,189 @@
+package camera
+
+import (
+	"math"
+	"testing"
+
+	"github.com/losinggeneration/hge"
+	"github.com/losinggeneration/hge/gfx"
+	"github.com/losinggeneration/hge/hgetest"
+)
+
+const epsilon = 1e-9
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < epsilon
+}
+
+func TestWorldToScreen(t *testing.T) {
+	tests := []struct {
+		name           string
+		c              Camera
+		wx, wy, sx, sy float64
+	}{
+		{"middle", Camera{Viewport: Viewport{0, 0, 100, 50}, X: 10, Y: 20, Zoom: 1}, 10, 20, 50, 25},
+		{"offset viewport", Camera{Viewport: Viewport{20, 10, 100, 50}, X: 10, Y: 20, Zoom: 1}, 10, 20, 70, 35},
+		{"zoomed", Camera{Viewport: Viewport{0, 0, 100, 50}, X: 10, Y: 20, Zoom: 2}, 15, 18, 60, 21},
+		{"no zoom is 1", Camera{Viewport: Viewport{0, 0, 100, 50}}, 5, 5, 55, 30},
+		{"turned", Camera{Viewport: Viewport{0, 0, 100, 50}, Zoom: 2, Rotation: math.Pi / 2}, 3, 0, 50, 19},
+		{"shaken", Camera{Viewport: Viewport{0, 0, 100, 50}, Zoom: 1, shakeX: 2, shakeY: -1}, 0, 0, 52, 24},
+	}
+
+	for _, test := range tests {
+		sx, sy := test.c.WorldToScreen(test.wx, test.wy)
+		if !near(sx, test.sx) || !near(sy, test.sy) {
+			t.Errorf("%s: %v,%v is at %v,%v on the screen, want %v,%v", test.name, test.wx, test.wy, sx, sy, test.sx, test.sy)
+		}
+
+		// The matrix gfx draws with puts it in the same place
+		if mx, my := test.c.Matrix().Apply(test.wx, test.wy); !near(mx, sx) || !near(my, sy) {
+			t.Errorf("%s: the matrix puts %v,%v at %v,%v, not %v,%v", test.name, test.wx, test.wy, mx, my, sx, sy)
+		}
+	}
+}
+
+func TestScreenToWorldRoundTrip(t *testing.T) {
+	for _, zoom := range []float64{0.5, 1, 3} {
+		for _, rot := range []float64{0, 0.3, math.Pi / 2, -2} {
+			c := New(10, 20, 200, 100)
+			c.X, c.Y, c.Zoom, c.Rotation = -40, 75, zoom, rot
+			c.shakeX, c.shakeY = 1.5, -0.5
+
+			for _, p := range [][2]float64{{0, 0}, {-40, 75}, {123.5, -8}, {1e4, 3}} {
+				sx, sy := c.WorldToScreen(p[0], p[1])
+				if wx, wy := c.ScreenToWorld(sx, sy); math.Abs(wx-p[0]) > 1e-6 || math.Abs(wy-p[1]) > 1e-6 {
+					t.Errorf("zoom %v rotation %v: %v went to %v,%v and back to %v,%v", zoom, rot, p, sx, sy, wx, wy)
+				}
+			}
+		}
+	}
+}
+
+func TestVisible(t *testing.T) {
+	tests := []struct {
+		name                   string
+		zoom, rot              float64
+		minX, minY, maxX, maxY float64
+	}{
+		{"plain", 1, 0, -50, -25, 50, 25},
+		{"zoomed in", 2, 0, -25, -12.5, 25, 12.5},
+		{"zoomed out", 0.5, 0, -100, -50, 100, 50},
+		{"quarter turn", 1, math.Pi / 2, -25, -50, 25, 50},
+		{"half turn", 1, math.Pi, -50, -25, 50, 25},
+		{"eighth turn", 2, math.Pi / 4, -37.5 / math.Sqrt2, -37.5 / math.Sqrt2, 37.5 / math.Sqrt2, 37.5 / math.Sqrt2},
+	}
+
+	for _, test := range tests {
+		c := New(0, 0, 100, 50)
+		c.Zoom, c.Rotation = test.zoom, test.rot
+
+		minX, minY, maxX, maxY := c.Visible()
+		if !near(minX, test.minX) || !near(minY, test.minY) || !near(maxX, test.maxX) || !near(maxY, test.maxY) {
+			t.Errorf("%s: visible %v,%v to %v,%v, want %v,%v to %v,%v", test.name, minX, minY, maxX, maxY, test.minX, test.minY, test.maxX, test.maxY)
+		}
+	}
+
+	// Every corner of a turned view is in what's visible
+	for rot := -3.0; rot < 3; rot += 0.25 {
+		c := New(0, 0, 100, 50)
+		c.X, c.Y, c.Zoom, c.Rotation = 30, -20, 1.5, rot
+
+		minX, minY, maxX, maxY := c.Visible()
+		for _, s := range [][2]float64{{0, 0}, {100, 0}, {100, 50}, {0, 50}} {
+			wx, wy := c.ScreenToWorld(s[0], s[1])
+			if wx < minX-epsilon || wx > maxX+epsilon || wy < minY-epsilon || wy > maxY+epsilon {
+				t.Errorf("rotation %v: corner %v is at %v,%v, outside %v,%v to %v,%v", rot, s, wx, wy, minX, minY, maxX, maxY)
+			}
+		}
+	}
+}
+
+func TestBounds(t *testing.T) {
+	tests := []struct {
+		name                       string
+		zoom                       float64
+		bounds                     [4]float64
+		lookX, lookY, wantX, wantY float64
+	}{
+		{"inside", 1, [4]float64{0, 0, 1000, 1000}, 500, 400, 500, 400},
+		{"top left", 1, [4]float64{0, 0, 1000, 1000}, -10, -10, 50, 25},
+		{"bottom right", 1, [4]float64{0, 0, 1000, 1000}, 2000, 990, 950, 975},
+		{"zoomed in", 2, [4]float64{0, 0, 1000, 1000}, 0, 0, 25, 12.5},
+		{"zoomed out", 0.5, [4]float64{0, 0, 1000, 1000}, 0, 1000, 100, 950},
+		{"narrower than the view", 1, [4]float64{0, 0, 40, 1000}, 0, 0, 20, 25},
+		{"smaller than the view", 1, [4]float64{-10, -10, 30, 10}, 500, 500, 10, 0},
+	}
+
+	for _, test := range tests {
+		c := New(0, 0, 100, 50)
+		c.Zoom = test.zoom
+		c.SetBounds(test.bounds[0], test.bounds[1], test.bounds[2], test.bounds[3])
+
+		c.LookAt(test.lookX, test.lookY)
+		if !near(c.X, test.wantX) || !near(c.Y, test.wantY) {
+			t.Errorf("%s: looking at %v,%v shows %v,%v, want %v,%v", test.name, test.lookX, test.lookY, c.X, c.Y, test.wantX, test.wantY)
+		}
+
+		// Following stops at the same place
+		c.LookAt((test.bounds[0]+test.bounds[2])/2, (test.bounds[1]+test.bounds[3])/2)
+		c.Follow(test.lookX, test.lookY)
+		c.Update(1)
+		if !near(c.X, test.wantX) || !near(c.Y, test.wantY) {
+			t.Errorf("%s: following %v,%v shows %v,%v, want %v,%v", test.name, test.lookX, test.lookY, c.X, c.Y, test.wantX, test.wantY)
+		}
+	}
+
+	c := New(0, 0, 100, 50)
+	c.SetBounds(0, 0, 1000, 1000)
+	c.ClearBounds()
+	if c.LookAt(-10, -10); c.X != -10 || c.Y != -10 {
+		t.Errorf("without bounds looking at -10,-10 shows %v,%v", c.X, c.Y)
+	}
+}
+
+func TestViewportClipping(t *testing.T) {
+	fill := func(x, y, w, h float32, color hge.Dword) {
+		q := gfx.Quad{Blend: gfx.BLEND_DEFAULT}
+		for i, p := range [4][2]float32{{x, y}, {x + w, y}, {x + w, y + h}, {x, y + h}} {
+			q.V[i] = gfx.Vertex{X: p[0], Y: p[1], Z: 0.5, Color: color}
+		}
+		q.Render()
+	}
+
+	left, right := New(0, 8, 16, 8), New(16, 8, 16, 8)
+	img, err := hgetest.Render(hgetest.Scene{
+		Width: 32, Height: 32,
+		Render: func(int) {
+			// Far bigger than either viewport
+			RenderAll([]*Camera{left, right}, func(c *Camera) {
+				color := hge.Dword(0xFFFF0000)
+				if c == right {
+					color = 0xFF0000FF
+				}
+				fill(-1000, -1000, 2000, 2000, color)
+			})
+
+			// Nothing's left clipped after Apply and Reset
+			left.Apply()
+			Reset()
+			fill(0, 28, 4, 4, 0xFF00FF00)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []struct {
+		x, y    int
+		r, g, b uint8
+	}{
+		{0, 8, 0xFF, 0, 0}, {15, 15, 0xFF, 0, 0},
+		{16, 8, 0, 0, 0xFF}, {31, 15, 0, 0, 0xFF},
+		{0, 7, 0, 0, 0}, {31, 16, 0, 0, 0}, {16, 0, 0, 0, 0},
+		{2, 30, 0, 0xFF, 0},
+	} {
+		if c := img.RGBAAt(p.x, p.y); c.R != p.r || c.G != p.g || c.B != p.b {
+			t.Errorf("pixel %d,%d is %v, want %d,%d,%d", p.x, p.y, c, p.r, p.g, p.b)
+		}
+	}
+}
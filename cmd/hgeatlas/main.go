@@ -0,0 +1,130 @@
+// Command hgeatlas packs images into texture atlases for the atlas package.
+//
+// Usage:
+//
+//	hgeatlas [flags] file-or-directory...
+//
+// Directories are searched for PNG files. Each image is named by its path
+// relative to the directory it was found in, or its file name when given
+// directly, without the extension and with forward slashes. The manifest is
+// written to the -o file and the pages next to it.
+//
+// Packing doesn't need a graphics device, so the tool can be built with the
+// headless tag on machines without hge-unix.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/losinggeneration/hge/atlas"
+)
+
+func main() {
+	var opts atlas.Options
+
+	out := flag.String("o", "atlas.json", "the manifest to write, pages are named after it")
+	flag.IntVar(&opts.MaxWidth, "width", atlas.DefaultPageSize, "the largest page width")
+	flag.IntVar(&opts.MaxHeight, "height", atlas.DefaultPageSize, "the largest page height")
+	flag.IntVar(&opts.Padding, "padding", 1, "transparent pixels between images")
+	flag.IntVar(&opts.Extrude, "extrude", 0, "times to repeat the edge pixels of each image")
+	flag.BoolVar(&opts.PowerOfTwo, "pot", false, "round page sizes up to powers of two")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: hgeatlas [flags] file-or-directory...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var images []atlas.Image
+	for _, arg := range flag.Args() {
+		found, err := readImages(arg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "hgeatlas:", err)
+			os.Exit(1)
+		}
+		images = append(images, found...)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(*out), filepath.Ext(*out))
+	pages, m, err := atlas.Pack(name, images, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "hgeatlas:", err)
+		os.Exit(1)
+	}
+
+	if err := atlas.Save(*out, pages, m); err != nil {
+		fmt.Fprintln(os.Stderr, "hgeatlas:", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%d images on %d pages\n", len(images), len(pages))
+}
+
+func readImages(arg string) ([]atlas.Image, error) {
+	info, err := os.Stat(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		img, err := readImage(arg)
+		if err != nil {
+			return nil, err
+		}
+		return []atlas.Image{{Name: imageName(filepath.Base(arg)), Image: img}}, nil
+	}
+
+	var images []atlas.Image
+	err = filepath.WalkDir(arg, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.EqualFold(filepath.Ext(p), ".png") {
+			return err
+		}
+
+		img, err := readImage(p)
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(arg, p)
+		if err != nil {
+			return err
+		}
+		images = append(images, atlas.Image{Name: imageName(rel), Image: img})
+
+		return nil
+	})
+
+	return images, err
+}
+
+func readImage(filename string) (image.Image, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	return img, nil
+}
+
+func imageName(p string) string {
+	return filepath.ToSlash(strings.TrimSuffix(p, filepath.Ext(p)))
+}
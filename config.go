@@ -0,0 +1,193 @@
+package hge
+
+import (
+	"errors"
+	"sort"
+)
+
+// A set of system states that are checked and applied together, so either
+// all of them are set or none are.
+type Config struct {
+	Bools   map[BoolState]bool
+	Funcs   map[FuncState]StateFunc
+	Hwnds   map[HwndState]Hwnd
+	Ints    map[IntState]int
+	Strings map[StringState]string
+}
+
+// An Option sets states in a Config.
+type Option func(*Config)
+
+// Creates a config with the options applied in order.
+func NewConfig(opts ...Option) *Config {
+	c := &Config{
+		Bools:   make(map[BoolState]bool),
+		Funcs:   make(map[FuncState]StateFunc),
+		Hwnds:   make(map[HwndState]Hwnd),
+		Ints:    make(map[IntState]int),
+		Strings: make(map[StringState]string),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Sets a bool state.
+func WithBool(state BoolState, value bool) Option {
+	return func(c *Config) { c.Bools[state] = value }
+}
+
+// Sets a function state.
+func WithFunc(state FuncState, value StateFunc) Option {
+	return func(c *Config) { c.Funcs[state] = value }
+}
+
+// Sets a window handle state.
+func WithHwnd(state HwndState, value Hwnd) Option {
+	return func(c *Config) { c.Hwnds[state] = value }
+}
+
+// Sets an int state.
+func WithInt(state IntState, value int) Option {
+	return func(c *Config) { c.Ints[state] = value }
+}
+
+// Sets a string state.
+func WithString(state StringState, value string) Option {
+	return func(c *Config) { c.Strings[state] = value }
+}
+
+// Sets SCREENWIDTH and SCREENHEIGHT.
+func WithScreenSize(width, height int) Option {
+	return func(c *Config) {
+		c.Ints[SCREENWIDTH] = width
+		c.Ints[SCREENHEIGHT] = height
+	}
+}
+
+// Sets WINDOWED.
+func WithWindowed(windowed bool) Option {
+	return WithBool(WINDOWED, windowed)
+}
+
+// Sets TITLE.
+func WithTitle(title string) Option {
+	return WithString(TITLE, title)
+}
+
+// Sets FRAMEFUNC.
+func WithFrameFunc(f StateFunc) Option {
+	return WithFunc(FRAMEFUNC, f)
+}
+
+// Sets RENDERFUNC.
+func WithRenderFunc(f StateFunc) Option {
+	return WithFunc(RENDERFUNC, f)
+}
+
+// Checks every state in the config could be set. When initiated is true the
+// states that can only be set before Initiate are errors. All the problems
+// found are joined into the returned error.
+func (c *Config) Validate(initiated bool) error {
+	var errs []error
+
+	for _, s := range c.boolStates() {
+		errs = append(errs, checkBool(s, c.Bools[s], initiated))
+	}
+	for _, s := range c.funcStates() {
+		errs = append(errs, checkFunc(s, c.Funcs[s], initiated))
+	}
+	for _, s := range c.hwndStates() {
+		errs = append(errs, checkHwnd(s, c.Hwnds[s], initiated))
+	}
+	for _, s := range c.intStates() {
+		errs = append(errs, checkInt(s, c.Ints[s], initiated))
+	}
+	for _, s := range c.stringStates() {
+		errs = append(errs, checkString(s, c.Strings[s], initiated))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Applies the config. Nothing is set unless every state in it is valid.
+func (h *HGE) Configure(c *Config) error {
+	if err := c.Validate(Initiated()); err != nil {
+		return err
+	}
+
+	b := h.Backend()
+	for _, s := range c.boolStates() {
+		b.SetStateBool(s, c.Bools[s])
+	}
+	for _, s := range c.funcStates() {
+		b.SetStateFunc(s, c.Funcs[s])
+	}
+	for _, s := range c.hwndStates() {
+		b.SetStateHwnd(s, c.Hwnds[s])
+	}
+	for _, s := range c.intStates() {
+		b.SetStateInt(s, c.Ints[s])
+	}
+	for _, s := range c.stringStates() {
+		b.SetStateString(s, c.Strings[s])
+	}
+
+	return nil
+}
+
+// The states are applied in order so the backend always sees the same
+// sequence of calls.
+
+func (c *Config) boolStates() []BoolState {
+	var states []BoolState
+	for s := range c.Bools {
+		states = append(states, s)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i] < states[j] })
+
+	return states
+}
+
+func (c *Config) funcStates() []FuncState {
+	var states []FuncState
+	for s := range c.Funcs {
+		states = append(states, s)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i] < states[j] })
+
+	return states
+}
+
+func (c *Config) hwndStates() []HwndState {
+	var states []HwndState
+	for s := range c.Hwnds {
+		states = append(states, s)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i] < states[j] })
+
+	return states
+}
+
+func (c *Config) intStates() []IntState {
+	var states []IntState
+	for s := range c.Ints {
+		states = append(states, s)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i] < states[j] })
+
+	return states
+}
+
+func (c *Config) stringStates() []StringState {
+	var states []StringState
+	for s := range c.Strings {
+		states = append(states, s)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i] < states[j] })
+
+	return states
+}
@@ -0,0 +1,378 @@
+package hge
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The environment variable naming a config file for Initiate to read.
+const ConfigEnv = "HGE_CONFIG"
+
+// The prefix of the environment variables that set states, for example
+// HGE_SCREENWIDTH=1024 or HGE_WINDOWED=false.
+const EnvPrefix = "HGE_"
+
+// The section states are read from and written to in INI and TOML-like
+// config files.
+const ConfigSection = "hge"
+
+var ErrConfigState = errors.New("hge: state can't be set from a config")
+
+// Reads a config file. Files ending in .json hold an object of state names
+// to values:
+//
+//	{"SCREENWIDTH": 1024, "WINDOWED": true, "TITLE": "My Game"}
+//
+// Any other file is read as INI or TOML-like name = value lines. Keys before
+// any section or in the [hge] section are states, other sections are
+// skipped, ; and # start comments and strings may be quoted. A comment can
+// follow a value after a space:
+//
+//	[hge]
+//	screenwidth = 1024 ; the window's width
+//	windowed = true
+//	title = "My Game # 2"
+//
+// State names aren't case sensitive. Function and window handle states, the
+// read only states and unknown names are errors.
+func ReadConfig(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	c := NewConfig()
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		err = c.readJSON(data)
+	} else {
+		err = c.readINI(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	return c, nil
+}
+
+// Returns the config the environment asks for: the file named by HGE_CONFIG,
+// if any, with the HGE_<STATE> variables on top of it.
+func EnvConfig() (*Config, error) {
+	c := NewConfig()
+
+	if filename := os.Getenv(ConfigEnv); filename != "" {
+		fc, err := ReadConfig(filename)
+		if err != nil {
+			return nil, err
+		}
+		WithConfig(fc)(c)
+	}
+
+	if err := c.ReadEnv(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Sets the states named by HGE_<STATE> environment variables. Other HGE_
+// variables, like HGE_BACKEND and HGE_CONFIG, are left alone, as are ones
+// naming states a config can't set, like HGE_FRAMEFUNC.
+func (c *Config) ReadEnv() error {
+	var errs []error
+
+	for _, kv := range os.Environ() {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+
+		name = strings.TrimPrefix(name, EnvPrefix)
+		if state, ok := StateByName(name); !ok || !configurable(state) {
+			continue
+		}
+
+		if err := c.Set(name, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s%s: %w", EnvPrefix, name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Sets the state with the given name from its text form. Bools take the
+// values strconv.ParseBool does as well as yes, no, on and off.
+func (c *Config) Set(name, value string) error {
+	state, ok := StateByName(strings.ToUpper(strings.TrimSpace(name)))
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownState, name)
+	}
+
+	switch s := state.(type) {
+	case BoolState:
+		b, err := parseBool(value)
+		if err != nil {
+			return valueError(s, value)
+		}
+		c.Bools[s] = b
+
+	case IntState:
+		if isReadOnlyInt(s) {
+			return stateError(ErrReadOnly, s)
+		}
+		i, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return valueError(s, value)
+		}
+		c.Ints[s] = i
+
+	case StringState:
+		c.Strings[s] = value
+
+	default:
+		return fmt.Errorf("%w: %v", ErrConfigState, state)
+	}
+
+	return nil
+}
+
+// Reports whether a config can set the state. Funcs and handles have no
+// text form, and read-only ints can't be set at all.
+func configurable(state interface{}) bool {
+	switch s := state.(type) {
+	case BoolState, StringState:
+		return true
+	case IntState:
+		return !isReadOnlyInt(s)
+	}
+
+	return false
+}
+
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yes", "on":
+		return true, nil
+	case "no", "off":
+		return false, nil
+	}
+
+	return strconv.ParseBool(strings.TrimSpace(value))
+}
+
+func (c *Config) readJSON(data []byte) error {
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+
+	var errs []error
+	for name, v := range m {
+		var value string
+		switch v := v.(type) {
+		case string:
+			value = v
+		case bool:
+			value = strconv.FormatBool(v)
+		case float64:
+			value = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			errs = append(errs, fmt.Errorf("%w: %s = %v", ErrInvalidValue, name, v))
+			continue
+		}
+
+		if err := c.Set(name, value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Config) readINI(data []byte) error {
+	var errs []error
+
+	inSection := true
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || text[0] == ';' || text[0] == '#' {
+			continue
+		}
+
+		if text[0] == '[' && text[len(text)-1] == ']' {
+			inSection = strings.EqualFold(strings.TrimSpace(text[1:len(text)-1]), ConfigSection)
+			continue
+		}
+		if !inSection {
+			continue
+		}
+
+		name, value, ok := strings.Cut(text, "=")
+		if !ok {
+			errs = append(errs, fmt.Errorf("line %d: expected name = value", line))
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+			value = unquoteValue(value)
+		} else {
+			value = stripComment(value)
+		}
+
+		if err := c.Set(name, value); err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", line, err))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Takes the quotes off a value, and any comment after them.
+func unquoteValue(value string) string {
+	end := -1
+	if q, err := strconv.QuotedPrefix(value); err == nil {
+		end = len(q)
+	} else if i := strings.IndexByte(value[1:], value[0]); i >= 0 {
+		end = i + 2
+	}
+	if end > 0 && stripComment(strings.TrimSpace(value[end:])) == "" {
+		value = value[:end]
+	}
+
+	if v, err := strconv.Unquote(value); err == nil {
+		return v
+	} else if value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+
+	return value
+}
+
+// Cuts a ; or # comment off the end of a value. Only one at the start or
+// after a space is a comment, so values like "level#2" are kept whole.
+func stripComment(value string) string {
+	for i := 0; i < len(value); i++ {
+		if value[i] != ';' && value[i] != '#' {
+			continue
+		}
+		if i == 0 || value[i-1] == ' ' || value[i-1] == '\t' {
+			return strings.TrimSpace(value[:i])
+		}
+	}
+
+	return value
+}
+
+// Writes the bool, int and string states in the config to a file, in the
+// format ReadConfig would pick for it. Files ending in .toml get quoted
+// strings, anything else that isn't .json is written as INI.
+func (c *Config) WriteFile(filename string) error {
+	var buf bytes.Buffer
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		m := make(map[string]interface{})
+		for s, v := range c.Bools {
+			m[s.String()] = v
+		}
+		for s, v := range c.Ints {
+			m[s.String()] = v
+		}
+		for s, v := range c.Strings {
+			m[s.String()] = v
+		}
+
+		data, err := json.MarshalIndent(m, "", "\t")
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+
+	case ".toml":
+		c.writeINI(&buf, " = ", strconv.Quote)
+
+	default:
+		c.writeINI(&buf, "=", func(s string) string { return s })
+	}
+
+	return os.WriteFile(filename, buf.Bytes(), 0644)
+}
+
+func (c *Config) writeINI(buf *bytes.Buffer, sep string, quote func(string) string) {
+	var lines []string
+
+	for s, v := range c.Bools {
+		lines = append(lines, s.String()+sep+strconv.FormatBool(v))
+	}
+	for s, v := range c.Ints {
+		lines = append(lines, s.String()+sep+strconv.Itoa(v))
+	}
+	for s, v := range c.Strings {
+		lines = append(lines, s.String()+sep+quote(v))
+	}
+	sort.Strings(lines)
+
+	buf.WriteString("[" + ConfigSection + "]\n")
+	for _, line := range lines {
+		buf.WriteString(line + "\n")
+	}
+}
+
+// Adds every state set in c to the config being built, replacing any set
+// by earlier options.
+func WithConfig(c *Config) Option {
+	return func(dst *Config) {
+		for s, v := range c.Bools {
+			dst.Bools[s] = v
+		}
+		for s, v := range c.Funcs {
+			dst.Funcs[s] = v
+		}
+		for s, v := range c.Hwnds {
+			dst.Hwnds[s] = v
+		}
+		for s, v := range c.Ints {
+			dst.Ints[s] = v
+		}
+		for s, v := range c.Strings {
+			dst.Strings[s] = v
+		}
+	}
+}
+
+// Returns the bool, int and string states currently in effect that a config
+// can set, so they can be written back out with WriteFile. In windowed mode
+// SCREENBPP is the desktop's depth, which may be one SetInt won't take, so
+// it's left out.
+func (h *HGE) EffectiveConfig() *Config {
+	c := NewConfig()
+
+	for s := range boolStateNames {
+		c.Bools[s] = h.GetBool(s)
+	}
+	for s := range intStateNames {
+		if !configurable(s) || s == SCREENBPP && c.Bools[WINDOWED] {
+			continue
+		}
+		c.Ints[s] = h.GetInt(s)
+	}
+	for s := range stringStateNames {
+		c.Strings[s] = h.GetString(s)
+	}
+
+	return c
+}
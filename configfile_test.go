@@ -0,0 +1,103 @@
+package hge
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestReadEnvSkipsStatesConfigsCantSet(t *testing.T) {
+	t.Setenv("HGE_SCREENWIDTH", "640")
+	t.Setenv("HGE_WINDOWED", "yes")
+	t.Setenv("HGE_FRAMEFUNC", "main.frame")
+	t.Setenv("HGE_HWND", "1")
+	t.Setenv("HGE_POWERSTATUS", "50")
+
+	c := NewConfig()
+	if err := c.ReadEnv(); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Ints[SCREENWIDTH] != 640 || !c.Bools[WINDOWED] {
+		t.Errorf("states from the environment weren't set: %v %v", c.Ints, c.Bools)
+	}
+	if len(c.Funcs) != 0 || len(c.Hwnds) != 0 {
+		t.Errorf("func or handle states were set: %v %v", c.Funcs, c.Hwnds)
+	}
+	if _, ok := c.Ints[POWERSTATUS]; ok {
+		t.Error("POWERSTATUS was set")
+	}
+
+	t.Setenv("HGE_SCREENHEIGHT", "tall")
+	if err := NewConfig().ReadEnv(); err == nil {
+		t.Error("a bad value didn't fail")
+	}
+}
+
+func TestEffectiveConfigScreenBPP(t *testing.T) {
+	if err := UseBackend("headless"); err != nil {
+		t.Skip(err)
+	}
+
+	h := New()
+	defer h.Free()
+
+	for _, windowed := range []bool{true, false} {
+		if err := h.SetBool(WINDOWED, windowed); err != nil {
+			t.Fatal(err)
+		}
+
+		c := h.EffectiveConfig()
+		if _, ok := c.Ints[SCREENBPP]; ok == windowed {
+			t.Errorf("windowed %v: SCREENBPP written is %v", windowed, ok)
+		}
+
+		// What's written has to be read back
+		filename := filepath.Join(t.TempDir(), "hge.ini")
+		if err := c.WriteFile(filename); err != nil {
+			t.Fatal(err)
+		}
+		read, err := ReadConfig(filename)
+		if err == nil {
+			err = read.Validate(false)
+		}
+		if err != nil {
+			t.Errorf("windowed %v: %v", windowed, err)
+		}
+	}
+}
+
+func TestReadINIComments(t *testing.T) {
+	data := `; a whole line comment
+# and another
+[hge]
+screenwidth = 1024 # QA
+screenheight = 768	; tabbed
+windowed = true;not a comment
+title = "My Game # 2" ; quoted
+logfile = level#2.log
+icon = 'ship.png' # single quoted
+inifile = # nothing
+
+[other]
+screenbpp = 16
+`
+
+	c := NewConfig()
+	if err := c.readINI([]byte(data)); err == nil {
+		t.Error("windowed = true;not a comment isn't a bool, but it read")
+	}
+
+	if c.Ints[SCREENWIDTH] != 1024 || c.Ints[SCREENHEIGHT] != 768 {
+		t.Errorf("screen %dx%d, want 1024x768", c.Ints[SCREENWIDTH], c.Ints[SCREENHEIGHT])
+	}
+	if _, ok := c.Ints[SCREENBPP]; ok {
+		t.Error("SCREENBPP from another section was read")
+	}
+
+	want := map[StringState]string{TITLE: "My Game # 2", ICON: "ship.png", LOGFILE: "level#2.log", INIFILE: ""}
+	for s, v := range want {
+		if got, ok := c.Strings[s]; !ok || got != v {
+			t.Errorf("%s is %q, want %q", s, got, v)
+		}
+	}
+}
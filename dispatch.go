@@ -0,0 +1,159 @@
+package hge
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// The engine has to be used from the OS thread that initiated it. Initiate
+// locks the goroutine calling it to its thread, and other goroutines hand
+// their calls to it with Do and DoAsync. Queued calls are run at the start of
+// every frame, or whenever the engine goroutine calls RunPending.
+var dispatch struct {
+	sync.Mutex
+	engine  uint64 // the goroutine that initiated the engine, 0 when it isn't
+	pending []func()
+}
+
+// Returns the id of the calling goroutine. Go doesn't hand these out, but
+// the first line of a stack trace is "goroutine <id> [...".
+func goid() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// Called by Initiate on the goroutine that'll run the engine.
+func lockEngineThread() {
+	runtime.LockOSThread()
+
+	dispatch.Lock()
+	dispatch.engine = goid()
+	dispatch.Unlock()
+}
+
+// Called by Shutdown. Anything still queued is run first so no goroutine is
+// left waiting in Do.
+func unlockEngineThread() {
+	dispatch.Lock()
+	pending := dispatch.pending
+	dispatch.engine, dispatch.pending = 0, nil
+	dispatch.Unlock()
+
+	for _, f := range pending {
+		f()
+	}
+
+	runtime.UnlockOSThread()
+}
+
+// Reports whether the caller is on the engine thread. Before Initiate and
+// after Shutdown every goroutine is.
+func OnEngineThread() bool {
+	dispatch.Lock()
+	engine := dispatch.engine
+	dispatch.Unlock()
+
+	return engine == 0 || engine == goid()
+}
+
+// Runs f on the engine thread and waits for it to finish. Called on the
+// engine thread, or while the engine isn't initiated, f is run straight away.
+// If f panics the panic is passed on to the caller of Do.
+func Do(f func()) {
+	var (
+		done    = make(chan struct{})
+		failure interface{}
+	)
+
+	call := func() {
+		defer close(done)
+		defer func() { failure = recover() }()
+		f()
+	}
+
+	if !queue(call) {
+		f()
+		return
+	}
+
+	<-done
+	if failure != nil {
+		panic(failure)
+	}
+}
+
+// Queues f to run on the engine thread without waiting for it. While the
+// engine isn't initiated f is run straight away.
+func DoAsync(f func()) {
+	dispatch.Lock()
+	if dispatch.engine != 0 {
+		dispatch.pending = append(dispatch.pending, f)
+		f = nil
+	}
+	dispatch.Unlock()
+
+	if f != nil {
+		f()
+	}
+}
+
+// Queues f when there's an engine thread and the caller isn't on it.
+func queue(f func()) bool {
+	id := goid()
+
+	dispatch.Lock()
+	defer dispatch.Unlock()
+
+	if dispatch.engine == 0 || dispatch.engine == id {
+		return false
+	}
+
+	dispatch.pending = append(dispatch.pending, f)
+	return true
+}
+
+// Runs the calls other goroutines have queued with Do and DoAsync. It's
+// called at the start of every frame, but the engine goroutine can call it
+// whenever it's busy outside the frame function, like while loading. Calls
+// from other goroutines are ignored.
+func RunPending() {
+	dispatch.Lock()
+	if dispatch.engine == 0 || dispatch.engine != goid() {
+		dispatch.Unlock()
+		return
+	}
+	pending := dispatch.pending
+	dispatch.pending = nil
+	dispatch.Unlock()
+
+	for _, f := range pending {
+		f()
+	}
+}
+
+// Panics if the engine is used from a goroutine other than the one that
+// initiated it. It only checks in builds with the hgedebug tag.
+func checkThread() {
+	if !debugThread || OnEngineThread() {
+		return
+	}
+
+	caller := "hge"
+	if pc, _, _, ok := runtime.Caller(2); ok {
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			caller = fn.Name()
+		}
+	}
+
+	panic(fmt.Sprintf("hge: %s called off the engine thread, use hge.Do or hge.DoAsync", caller))
+}
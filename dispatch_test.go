@@ -0,0 +1,155 @@
+package hge
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// Initiates the headless engine on the calling goroutine, with a frame
+// function that keeps going until stop is closed.
+func initiateHeadless(t *testing.T, stop chan struct{}) (*HGE, *Headless) {
+	t.Helper()
+
+	if err := UseBackend("headless"); err != nil {
+		t.Skip(err)
+	}
+
+	h := New()
+	err := h.Initiate(WithWindowed(true), WithBool(USESOUND, false), WithFrameFunc(func() int {
+		select {
+		case <-stop:
+			return 1
+		default:
+			return 0
+		}
+	}))
+	if err != nil {
+		h.Free()
+		t.Fatal(err)
+	}
+
+	return h, h.Backend().(*Headless)
+}
+
+func TestDoInline(t *testing.T) {
+	if !OnEngineThread() {
+		t.Fatal("on another goroutine's engine thread before Initiate")
+	}
+
+	id := goid()
+	var ran []int
+	Do(func() {
+		if goid() != id {
+			t.Error("Do ran on another goroutine")
+		}
+		ran = append(ran, 1)
+	})
+	DoAsync(func() { ran = append(ran, 2) })
+	ran = append(ran, 3)
+
+	// Nothing is queued, so nothing waits for a frame
+	if !reflect.DeepEqual(ran, []int{1, 2, 3}) {
+		t.Errorf("ran %v, want each straight away", ran)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "inline" {
+				t.Errorf("recovered %v, want the panic from f", r)
+			}
+		}()
+		Do(func() { panic("inline") })
+	}()
+}
+
+func TestDoFromAnotherGoroutine(t *testing.T) {
+	stop := make(chan struct{})
+	h, headless := initiateHeadless(t, stop)
+	defer h.Free()
+	defer h.Shutdown()
+
+	engine := goid()
+	var ranOn []uint64
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if OnEngineThread() {
+				t.Error("another goroutine is on the engine thread")
+			}
+			// Only the engine goroutine touches ranOn, so it isn't locked
+			Do(func() { ranOn = append(ranOn, goid()) })
+
+			defer func() {
+				if r := recover(); r != "on the engine" {
+					t.Errorf("recovered %v, want the panic from f", r)
+				}
+			}()
+			Do(func() { panic("on the engine") })
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(stop)
+	}()
+
+	// The frames pump the calls until every goroutine is done
+	for headless.Step() {
+	}
+
+	if len(ranOn) != 4 {
+		t.Fatalf("%d calls ran, want 4", len(ranOn))
+	}
+	for _, id := range ranOn {
+		if id != engine {
+			t.Errorf("a call ran on goroutine %d, not the engine's %d", id, engine)
+		}
+	}
+}
+
+func TestDoAsyncOrder(t *testing.T) {
+	stop := make(chan struct{})
+	h, _ := initiateHeadless(t, stop)
+	defer h.Free()
+
+	var ran []int
+	queued := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			DoAsync(func() { ran = append(ran, i) })
+		}
+		// Off the engine goroutine nothing runs
+		RunPending()
+		close(queued)
+	}()
+	<-queued
+
+	if len(ran) != 0 {
+		t.Fatalf("ran %v before the engine ran them", ran)
+	}
+
+	// The engine goroutine's own calls wait their turn too
+	DoAsync(func() { ran = append(ran, 5) })
+	RunPending()
+	if want := []int{0, 1, 2, 3, 4, 5}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("ran %v, want %v", ran, want)
+	}
+
+	// Whatever is still queued at Shutdown is run then
+	go func() {
+		DoAsync(func() { ran = append(ran, 6) })
+		close(stop)
+	}()
+	<-stop
+	h.Shutdown()
+	if ran[len(ran)-1] != 6 {
+		t.Errorf("ran %v, want the call queued before Shutdown", ran)
+	}
+	if !OnEngineThread() {
+		t.Error("the engine thread is still set after Shutdown")
+	}
+}
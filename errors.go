@@ -0,0 +1,61 @@
+package hge
+
+import "errors"
+
+// The reasons a loader can fail. Loaders return them wrapped in a
+// *LoadError, so check for them with errors.Is.
+var (
+	ErrNotFound = errors.New("hge: not found")
+	ErrFormat   = errors.New("hge: unrecognized format")
+	ErrDevice   = errors.New("hge: device not available")
+)
+
+// LoadError records which file a loader failed on and why. Loaders working
+// from memory leave Filename empty.
+type LoadError struct {
+	Op       string // the loader, for example "gfx.LoadTexture"
+	Filename string
+	Message  string // the backend's error message at the time, if any
+	Err      error  // ErrNotFound, ErrFormat, ErrDevice or an error it wraps
+}
+
+func (e *LoadError) Error() string {
+	s := e.Op
+	if e.Filename != "" {
+		s += " " + e.Filename
+	}
+	s += ": " + e.Err.Error()
+	if e.Message != "" {
+		s += " (" + e.Message + ")"
+	}
+
+	return s
+}
+
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+// Returns a *LoadError for op failing to load filename with err, along with
+// the backend's current error message.
+func (h *HGE) NewLoadError(op, filename string, err error) error {
+	return &LoadError{op, filename, h.Backend().ErrorMessage(), err}
+}
+
+// Works out why a backend couldn't load filename. When the device it needs
+// isn't ready that's to blame, otherwise it's ErrNotFound if the file can't
+// be read at all and ErrFormat if it can.
+func (h *HGE) LoadFailed(op, filename string, deviceReady bool) error {
+	// Checking the file can change the message, so it's kept first
+	e := &LoadError{op, filename, h.Backend().ErrorMessage(), ErrFormat}
+
+	if !deviceReady {
+		e.Err = ErrDevice
+	} else if data := h.Backend().ResourceLoad(filename); data == nil {
+		e.Err = ErrNotFound
+	} else {
+		h.Backend().ResourceFree(data)
+	}
+
+	return e
+}
@@ -0,0 +1,244 @@
+package gfx
+
+import (
+	"sort"
+	"unsafe"
+)
+
+// SpriteBatch sort modes
+const (
+	SORT_NONE        = iota // draw in the order quads were added
+	SORT_TEXTURE            // group quads by texture and blend mode
+	SORT_BACKTOFRONT        // draw the quads with the largest z first
+	SORT_FRONTTOBACK        // draw the quads with the smallest z first
+)
+
+// The number of quads a SpriteBatch holds when none is given, the same as
+// HGE's vertex buffer.
+const DEFAULT_BATCH_SIZE = 1000
+
+// What a SpriteBatch has drawn since its stats were last reset.
+type BatchStats struct {
+	Quads     int // quads added
+	DrawCalls int // batches sent to the backend
+	Flushes   int // times the batch was emptied
+
+	// Why the quads being drawn changed batch
+	TextureChanges  int
+	BlendChanges    int
+	CapacityFlushes int
+}
+
+type batchQuad struct {
+	v     [4]Vertex
+	tex   *Texture
+	blend int
+}
+
+// SpriteBatch collects quads and draws runs of them that share a texture and
+// blend mode with one StartBatch and FinishBatch each. While a batch is
+// begun, Quad.Render adds to it instead of drawing straight away, so sprites
+// and fonts are batched without changes. Drawing lines, triples or starting a
+// batch by hand, changing the clipping or transform, clearing and ending the
+// scene all flush it first so things are still drawn in order.
+type SpriteBatch struct {
+	// One of the SORT constants. Sorting happens when the batch is flushed,
+	// so only quads added since the last flush are sorted together.
+	Sort int
+
+	quads    []batchQuad
+	capacity int
+	stats    BatchStats
+}
+
+var activeBatch *SpriteBatch
+
+// Creates a sprite batch holding up to capacity quads between flushes. A
+// capacity of 0 or less uses DEFAULT_BATCH_SIZE.
+func NewSpriteBatch(capacity int) *SpriteBatch {
+	if capacity <= 0 {
+		capacity = DEFAULT_BATCH_SIZE
+	}
+
+	return &SpriteBatch{
+		quads:    make([]batchQuad, 0, capacity),
+		capacity: capacity,
+	}
+}
+
+// Makes Quad.Render add to this batch until End is called. Any other batch
+// that was begun is ended.
+func (b *SpriteBatch) Begin() {
+	if activeBatch != nil && activeBatch != b {
+		activeBatch.End()
+	}
+
+	activeBatch = b
+}
+
+// Flushes the batch and goes back to drawing quads straight away.
+func (b *SpriteBatch) End() {
+	b.Flush()
+
+	if activeBatch == b {
+		activeBatch = nil
+	}
+}
+
+// Adds a quad to the batch. Without sorting, a quad with a different texture
+// or blend mode than the ones waiting flushes them first.
+func (b *SpriteBatch) Add(q *Quad) {
+	b.AddVertices(q.V, q.Texture, q.Blend)
+}
+
+// Adds a quad made of v to the batch.
+func (b *SpriteBatch) AddVertices(v [4]Vertex, tex *Texture, blend int) {
+	if clippedAway() {
+		return
+	}
+	transformVertices(v[:])
+	blend = blendMode(blend)
+
+	if n := len(b.quads); n > 0 {
+		if n >= b.capacity {
+			b.stats.CapacityFlushes++
+			b.Flush()
+		} else if b.Sort == SORT_NONE {
+			last := &b.quads[n-1]
+			if last.tex.handle() != tex.handle() {
+				b.stats.TextureChanges++
+				b.Flush()
+			} else if last.blend != blend {
+				b.stats.BlendChanges++
+				b.Flush()
+			}
+		}
+	}
+
+	b.quads = append(b.quads, batchQuad{v, tex, blend})
+	b.stats.Quads++
+}
+
+// Returns the number of quads waiting to be drawn.
+func (b *SpriteBatch) Len() int {
+	return len(b.quads)
+}
+
+// Draws the quads waiting in the batch.
+func (b *SpriteBatch) Flush() {
+	if len(b.quads) == 0 {
+		return
+	}
+
+	b.sort()
+	b.stats.Flushes++
+
+	for start := 0; start < len(b.quads); {
+		end := start + 1
+		for end < len(b.quads) && b.quads[end].tex.handle() == b.quads[start].tex.handle() && b.quads[end].blend == b.quads[start].blend {
+			end++
+		}
+
+		if end < len(b.quads) {
+			if b.quads[end].tex.handle() != b.quads[start].tex.handle() {
+				b.stats.TextureChanges++
+			} else {
+				b.stats.BlendChanges++
+			}
+		}
+
+		if !b.draw(b.quads[start:end]) {
+			break
+		}
+		start = end
+	}
+
+	for i := range b.quads {
+		b.quads[i].tex = nil
+	}
+	b.quads = b.quads[:0]
+}
+
+// Draws quads that share a texture and blend mode, in as few batches as the
+// backend's vertex buffer allows.
+func (b *SpriteBatch) draw(quads []batchQuad) bool {
+	for len(quads) > 0 {
+		v, max := backend().StartBatch(PRIM_QUADS, quads[0].tex.handle(), quads[0].blend)
+		if v == nil || max <= 0 {
+			return false
+		}
+
+		n := min(len(quads), max)
+		buf := unsafe.Slice((*Vertex)(unsafe.Pointer(v)), n*4)
+		for i := 0; i < n; i++ {
+			copy(buf[i*4:i*4+4], quads[i].v[:])
+		}
+
+		backend().FinishBatch(n)
+		b.stats.DrawCalls++
+		quads = quads[n:]
+	}
+
+	return true
+}
+
+func (b *SpriteBatch) sort() {
+	q := b.quads
+
+	switch b.Sort {
+	case SORT_TEXTURE:
+		sort.SliceStable(q, func(i, j int) bool {
+			ti, tj := q[i].tex.handle(), q[j].tex.handle()
+			if ti != tj {
+				return ti < tj
+			}
+			return q[i].blend < q[j].blend
+		})
+
+	case SORT_BACKTOFRONT:
+		sort.SliceStable(q, func(i, j int) bool { return q[i].z() > q[j].z() })
+
+	case SORT_FRONTTOBACK:
+		sort.SliceStable(q, func(i, j int) bool { return q[i].z() < q[j].z() })
+	}
+}
+
+func (q *batchQuad) z() float32 {
+	return (q.v[0].Z + q.v[1].Z + q.v[2].Z + q.v[3].Z) / 4
+}
+
+// Returns what the batch has drawn since the stats were last reset.
+func (b *SpriteBatch) Stats() BatchStats {
+	return b.stats
+}
+
+// Clears the stats, usually once a frame.
+func (b *SpriteBatch) ResetStats() {
+	b.stats = BatchStats{}
+}
+
+// Draws whatever's waiting in the batch that's begun, so something drawn
+// another way ends up on top of it.
+func flushBatch() {
+	if activeBatch != nil {
+		activeBatch.Flush()
+	}
+}
+
+// Like StartBatch, but returns the vertex buffer as a slice with room for
+// max_prim primitives, so it can be indexed safely.
+func StartBatchSlice(prim_type int, tex *Texture, blend int) (ver []Vertex, ok bool) {
+	flushBatch()
+	if clippedAway() {
+		return nil, false
+	}
+
+	v, mp := backend().StartBatch(prim_type, tex.handle(), blendMode(blend))
+	if v == nil || mp <= 0 {
+		return nil, false
+	}
+
+	rawBatch.v, rawBatch.primType = v, prim_type
+
+	return unsafe.Slice((*Vertex)(unsafe.Pointer(v)), mp*prim_type), true
+}
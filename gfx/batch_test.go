@@ -0,0 +1,203 @@
+package gfx_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/losinggeneration/hge"
+	"github.com/losinggeneration/hge/gfx"
+	"github.com/losinggeneration/hge/hgetest"
+)
+
+// Renders a frame with two textures and returns what it drew.
+func recordBatches(t *testing.T, render func(a, b *gfx.Texture)) gfx.CommandList {
+	t.Helper()
+
+	var a, b *gfx.Texture
+	var frame gfx.CommandList
+
+	_, err := hgetest.Render(hgetest.Scene{
+		Width: 32, Height: 32,
+		Setup: func() error {
+			a, b = gfx.NewTexture(4, 4), gfx.NewTexture(4, 4)
+			gfx.StartRecording(func(l gfx.CommandList) { frame = l })
+			return nil
+		},
+		Render: func(int) { render(a, b) },
+	})
+	gfx.StopRecording()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return frame
+}
+
+// Describes the draw calls as texture, blend and the ids of their quads.
+func batches(frame gfx.CommandList, a, b hge.HTexture) []string {
+	names := map[hge.HTexture]string{0: "-", a: "a", b: "b"}
+
+	var s []string
+	for _, c := range frame {
+		switch c.Kind {
+		case gfx.CMD_LINE:
+			s = append(s, "line")
+		case gfx.CMD_QUAD, gfx.CMD_BATCH:
+			ids := make([]hge.Dword, 0, len(c.V)/4)
+			for i := 0; i < len(c.V); i += 4 {
+				ids = append(ids, c.V[i].Color&0xFF)
+			}
+			s = append(s, fmt.Sprintf("%s %d %v", names[c.Texture], c.Blend, ids))
+		}
+	}
+
+	return s
+}
+
+// The handles of a and b, the first two textures a frame drew with.
+func textures(frame gfx.CommandList) (a, b hge.HTexture) {
+	for _, c := range frame {
+		if c.Kind != gfx.CMD_BATCH || c.Texture == 0 {
+			continue
+		}
+		if a == 0 {
+			a = c.Texture
+		} else if c.Texture != a {
+			return min(a, c.Texture), max(a, c.Texture)
+		}
+	}
+
+	return a, b
+}
+
+func TestSpriteBatch(t *testing.T) {
+	const add = gfx.BLEND_COLORADD | gfx.BLEND_ALPHABLEND
+
+	tests := []struct {
+		name  string
+		sort  int
+		want  []string
+		stats gfx.BatchStats
+	}{
+		{
+			"in order", gfx.SORT_NONE,
+			[]string{"a 2 [1 2]", "b 2 [3]", fmt.Sprintf("b %d [4]", add), "line", "a 2 [5]", "b 2 [6]"},
+			gfx.BatchStats{Quads: 6, DrawCalls: 5, Flushes: 5, TextureChanges: 2, BlendChanges: 1},
+		},
+		{
+			"by texture", gfx.SORT_TEXTURE,
+			[]string{"a 2 [1 2]", "b 2 [3]", fmt.Sprintf("b %d [4]", add), "line", "a 2 [5]", "b 2 [6]"},
+			gfx.BatchStats{Quads: 6, DrawCalls: 5, Flushes: 2, TextureChanges: 2, BlendChanges: 1},
+		},
+	}
+
+	for _, test := range tests {
+		var stats gfx.BatchStats
+		frame := recordBatches(t, func(a, b *gfx.Texture) {
+			batch := gfx.NewSpriteBatch(0)
+			batch.Sort = test.sort
+
+			quad := func(id int, tex *gfx.Texture, blend int) {
+				q := square(float32(id*4), 0, 4, 0xFF000000|hge.Dword(id))
+				q.Texture, q.Blend = tex, blend
+				q.Render()
+			}
+
+			batch.Begin()
+			quad(1, a, gfx.BLEND_DEFAULT)
+			quad(2, a, gfx.BLEND_DEFAULT)
+			quad(3, b, gfx.BLEND_DEFAULT)
+			quad(4, b, add)
+			// A line has to go on top of what's waiting
+			gfx.NewLine(0, 10, 30, 10).Render()
+			quad(5, a, gfx.BLEND_DEFAULT)
+			quad(6, b, gfx.BLEND_DEFAULT)
+			batch.End()
+
+			stats = batch.Stats()
+		})
+
+		a, b := textures(frame)
+		got := batches(frame, a, b)
+		if fmt.Sprint(got) != fmt.Sprint(test.want) {
+			t.Errorf("%s: drew %q, want %q", test.name, got, test.want)
+		}
+		if stats != test.stats {
+			t.Errorf("%s: stats %+v, want %+v", test.name, stats, test.stats)
+		}
+	}
+}
+
+func TestSpriteBatchSortTexture(t *testing.T) {
+	var stats gfx.BatchStats
+	frame := recordBatches(t, func(a, b *gfx.Texture) {
+		batch := gfx.NewSpriteBatch(0)
+		batch.Sort = gfx.SORT_TEXTURE
+		batch.Begin()
+		for i, tex := range []*gfx.Texture{b, a, b, a, nil, a, b} {
+			q := square(float32(i*4), 0, 4, 0xFF000000|hge.Dword(i))
+			q.Texture, q.Blend = tex, gfx.BLEND_DEFAULT
+			if i == 5 {
+				q.Blend = gfx.BLEND_COLORADD
+			}
+			batch.Add(q)
+		}
+		batch.End()
+
+		stats = batch.Stats()
+	})
+
+	// Grouped by texture and then blend, keeping the order they were added
+	a, b := textures(frame)
+	want := []string{"- 2 [4]", "a 1 [5]", "a 2 [1 3]", "b 2 [0 2 6]"}
+	if got := batches(frame, a, b); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("drew %q, want %q", got, want)
+	}
+	if want := (gfx.BatchStats{Quads: 7, DrawCalls: 4, Flushes: 1, TextureChanges: 2, BlendChanges: 1}); stats != want {
+		t.Errorf("stats %+v, want %+v", stats, want)
+	}
+}
+
+func TestSpriteBatchFull(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int
+		quads    int
+		draws    []int
+		stats    gfx.BatchStats
+	}{
+		// The batch holds fewer quads than the backend
+		{"capacity", 3, 7, []int{3, 3, 1}, gfx.BatchStats{Quads: 7, DrawCalls: 3, Flushes: 3, CapacityFlushes: 2}},
+		// The headless backend's vertex buffer holds 1000 quads
+		{"vertex buffer", 2500, 2500, []int{1000, 1000, 500}, gfx.BatchStats{Quads: 2500, DrawCalls: 3, Flushes: 1}},
+	}
+
+	for _, test := range tests {
+		var stats gfx.BatchStats
+		frame := recordBatches(t, func(a, b *gfx.Texture) {
+			batch := gfx.NewSpriteBatch(test.capacity)
+			batch.Begin()
+			for i := 0; i < test.quads; i++ {
+				q := square(float32(i%32), 0, 1, 0xFFFFFFFF)
+				q.Texture = a
+				batch.Add(q)
+			}
+			batch.End()
+
+			stats = batch.Stats()
+		})
+
+		var draws []int
+		for _, c := range frame {
+			if c.Kind == gfx.CMD_BATCH {
+				draws = append(draws, len(c.V)/4)
+			}
+		}
+		if fmt.Sprint(draws) != fmt.Sprint(test.draws) {
+			t.Errorf("%s: drew batches of %v quads, want %v", test.name, draws, test.draws)
+		}
+		if stats != test.stats {
+			t.Errorf("%s: stats %+v, want %+v", test.name, stats, test.stats)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package gfx
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"math/bits"
+)
+
+// BMP isn't in the standard library, so the uncompressed kinds, which are
+// the ones image editors save, are decoded here.
+
+var errBMP = errors.New("gfx: unsupported BMP")
+
+const (
+	bmpRGB       = 0
+	bmpBitfields = 3
+
+	// Bigger images are taken to be corrupt rather than allocated
+	bmpMaxSize   = 1 << 16
+	bmpMaxPixels = 1 << 26
+)
+
+func init() {
+	image.RegisterFormat("bmp", "BM", decodeBMP, decodeBMPConfig)
+}
+
+type bmpHeader struct {
+	offset     int
+	width      int
+	height     int
+	topDown    bool
+	bpp        int
+	masks      [4]uint32 // red, green, blue, alpha
+	palette    color.Palette
+	headerSize int
+}
+
+func readBMPHeader(r io.Reader) (*bmpHeader, error) {
+	var file [14]byte
+	if _, err := io.ReadFull(r, file[:]); err != nil {
+		return nil, err
+	}
+	if file[0] != 'B' || file[1] != 'M' {
+		return nil, errBMP
+	}
+
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	n := binary.LittleEndian.Uint32(size[:])
+	if n < 40 || n > 1024 {
+		return nil, errBMP
+	}
+	info := make([]byte, n)
+	copy(info, size[:])
+	if _, err := io.ReadFull(r, info[4:]); err != nil {
+		return nil, err
+	}
+
+	le := binary.LittleEndian
+	h := &bmpHeader{
+		offset:     int(le.Uint32(file[10:])),
+		width:      int(int32(le.Uint32(info[4:]))),
+		height:     int(int32(le.Uint32(info[8:]))),
+		bpp:        int(le.Uint16(info[14:])),
+		headerSize: 14 + len(info),
+	}
+	if h.height < 0 {
+		h.height, h.topDown = -h.height, true
+	}
+	if h.width <= 0 || h.height <= 0 || le.Uint16(info[12:]) != 1 {
+		return nil, errBMP
+	}
+	if h.width > bmpMaxSize || h.height > bmpMaxSize || h.width*h.height > bmpMaxPixels {
+		return nil, errBMP
+	}
+
+	compression := le.Uint32(info[16:])
+	switch {
+	case compression == bmpRGB && h.bpp == 16:
+		h.masks = [4]uint32{0x7C00, 0x03E0, 0x001F, 0}
+	case compression == bmpRGB && (h.bpp == 24 || h.bpp == 32):
+		h.masks = [4]uint32{0xFF0000, 0xFF00, 0xFF, 0}
+	case compression == bmpRGB && h.bpp <= 8:
+	case compression == bmpBitfields && (h.bpp == 16 || h.bpp == 32):
+		// The masks follow a plain info header, or are part of a bigger one
+		var m []byte
+		if len(info) >= 56 {
+			m = info[40:56]
+		} else {
+			m = make([]byte, 12)
+			if _, err := io.ReadFull(r, m); err != nil {
+				return nil, err
+			}
+			h.headerSize += 12
+		}
+		for i := 0; i < len(m)/4; i++ {
+			h.masks[i] = le.Uint32(m[i*4:])
+		}
+	default:
+		return nil, errBMP
+	}
+
+	if h.bpp <= 8 {
+		switch h.bpp {
+		case 1, 4, 8:
+		default:
+			return nil, errBMP
+		}
+
+		n := int(le.Uint32(info[32:]))
+		if n == 0 || n > 1<<h.bpp {
+			n = 1 << h.bpp
+		}
+		p := make([]byte, n*4)
+		if _, err := io.ReadFull(r, p); err != nil {
+			return nil, err
+		}
+		h.headerSize += len(p)
+
+		h.palette = make(color.Palette, n)
+		for i := range h.palette {
+			h.palette[i] = color.RGBA{p[i*4+2], p[i*4+1], p[i*4], 0xFF}
+		}
+	}
+
+	return h, nil
+}
+
+func decodeBMPConfig(r io.Reader) (image.Config, error) {
+	h, err := readBMPHeader(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+
+	if h.palette != nil {
+		return image.Config{ColorModel: h.palette, Width: h.width, Height: h.height}, nil
+	}
+
+	return image.Config{ColorModel: color.NRGBAModel, Width: h.width, Height: h.height}, nil
+}
+
+func decodeBMP(r io.Reader) (image.Image, error) {
+	h, err := readBMPHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.offset > h.headerSize {
+		if _, err := io.CopyN(io.Discard, r, int64(h.offset-h.headerSize)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Rows are padded to 4 bytes
+	stride := (h.width*h.bpp + 31) / 32 * 4
+	row := make([]byte, stride)
+
+	if h.palette != nil {
+		img := image.NewPaletted(image.Rect(0, 0, h.width, h.height), h.palette)
+		for i := 0; i < h.height; i++ {
+			if _, err := io.ReadFull(r, row); err != nil {
+				return nil, err
+			}
+
+			y := h.height - 1 - i
+			if h.topDown {
+				y = i
+			}
+			for x := 0; x < h.width; x++ {
+				bit := x * h.bpp
+				idx := row[bit/8] >> (8 - h.bpp - bit%8) & (1<<h.bpp - 1)
+				if int(idx) >= len(h.palette) {
+					idx = 0
+				}
+				img.Pix[y*img.Stride+x] = idx
+			}
+		}
+
+		return img, nil
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, h.width, h.height))
+	bpp := h.bpp / 8
+	for i := 0; i < h.height; i++ {
+		if _, err := io.ReadFull(r, row); err != nil {
+			return nil, err
+		}
+
+		y := h.height - 1 - i
+		if h.topDown {
+			y = i
+		}
+		for x := 0; x < h.width; x++ {
+			var v uint32
+			for b := bpp - 1; b >= 0; b-- {
+				v = v<<8 | uint32(row[x*bpp+b])
+			}
+
+			p := img.Pix[img.PixOffset(x, y):]
+			p[0] = bmpChannel(v, h.masks[0])
+			p[1] = bmpChannel(v, h.masks[1])
+			p[2] = bmpChannel(v, h.masks[2])
+			p[3] = 0xFF
+			if h.masks[3] != 0 {
+				p[3] = bmpChannel(v, h.masks[3])
+			}
+		}
+	}
+
+	return img, nil
+}
+
+// Scales the bits of v under mask to 0..255.
+func bmpChannel(v, mask uint32) uint8 {
+	if mask == 0 {
+		return 0
+	}
+
+	shift := bits.TrailingZeros32(mask)
+	max := mask >> shift
+	return uint8((v & mask >> shift) * 255 / max)
+}
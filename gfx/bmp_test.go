@@ -0,0 +1,311 @@
+package gfx_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+
+	_ "github.com/losinggeneration/hge/gfx"
+)
+
+type bmpFile struct {
+	width, height int32
+	bpp           uint16
+	compression   uint32
+	headerSize    uint32 // 40 when left zero
+	planes        uint16 // 1 when left zero
+	colors        uint32 // the palette size written in the header
+	// Written into an info header of 56 bytes or more
+	headerMasks []uint32
+	// Written after the info header, the palette or the masks
+	extra []byte
+	// The rows as they're stored, padded to 4 bytes
+	pixels []byte
+}
+
+func (f bmpFile) bytes() []byte {
+	le := binary.LittleEndian
+	headerSize, planes := f.headerSize, f.planes
+	if headerSize == 0 {
+		headerSize = 40
+	}
+	if planes == 0 {
+		planes = 1
+	}
+
+	info := make([]byte, max(headerSize, 40))
+	le.PutUint32(info[0:], headerSize)
+	le.PutUint32(info[4:], uint32(f.width))
+	le.PutUint32(info[8:], uint32(f.height))
+	le.PutUint16(info[12:], planes)
+	le.PutUint16(info[14:], f.bpp)
+	le.PutUint32(info[16:], f.compression)
+	le.PutUint32(info[32:], f.colors)
+	if len(info) >= 56 {
+		copy(info[40:], bmpMasks(f.headerMasks...))
+	}
+
+	var b bytes.Buffer
+	b.WriteString("BM")
+	binary.Write(&b, le, uint32(14+len(info)+len(f.extra)+len(f.pixels)))
+	binary.Write(&b, le, uint32(0))
+	binary.Write(&b, le, uint32(14+len(info)+len(f.extra)))
+	b.Write(info)
+	b.Write(f.extra)
+	b.Write(f.pixels)
+
+	return b.Bytes()
+}
+
+// A palette of BGRX entries.
+func bmpPalette(c ...color.NRGBA) []byte {
+	var p []byte
+	for _, c := range c {
+		p = append(p, c.B, c.G, c.R, 0)
+	}
+
+	return p
+}
+
+func bmpMasks(masks ...uint32) []byte {
+	b := make([]byte, len(masks)*4)
+	for i, m := range masks {
+		binary.LittleEndian.PutUint32(b[i*4:], m)
+	}
+
+	return b
+}
+
+var (
+	black = color.NRGBA{0, 0, 0, 0xFF}
+	white = color.NRGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	red   = color.NRGBA{0xFF, 0, 0, 0xFF}
+	green = color.NRGBA{0, 0xFF, 0, 0xFF}
+	blue  = color.NRGBA{0, 0, 0xFF, 0xFF}
+)
+
+func TestDecodeBMP(t *testing.T) {
+	tests := []struct {
+		name string
+		file bmpFile
+		want [][]color.NRGBA // rows from the top
+	}{
+		{
+			"1 bit",
+			bmpFile{
+				width: 2, height: 2, bpp: 1,
+				extra: bmpPalette(black, white),
+				// Bottom row first
+				pixels: []byte{0x80, 0, 0, 0, 0x40, 0, 0, 0},
+			},
+			[][]color.NRGBA{{black, white}, {white, black}},
+		},
+		{
+			"4 bit with a short palette",
+			bmpFile{
+				width: 3, height: 1, bpp: 4, colors: 3,
+				extra:  bmpPalette(red, green, blue),
+				pixels: []byte{0x21, 0x00, 0, 0},
+			},
+			[][]color.NRGBA{{blue, green, red}},
+		},
+		{
+			"8 bit with a full palette",
+			bmpFile{
+				width: 2, height: 1, bpp: 8,
+				extra:  append(bmpPalette(black, green), make([]byte, 254*4)...),
+				pixels: []byte{1, 0, 0, 0},
+			},
+			[][]color.NRGBA{{green, black}},
+		},
+		{
+			"8 bit top down",
+			bmpFile{
+				width: 1, height: -2, bpp: 8, colors: 2,
+				extra:  bmpPalette(red, blue),
+				pixels: []byte{0, 0, 0, 0, 1, 0, 0, 0},
+			},
+			[][]color.NRGBA{{red}, {blue}},
+		},
+		{
+			"16 bit 555",
+			bmpFile{
+				width: 2, height: 1, bpp: 16,
+				pixels: []byte{0x00, 0x7C, 0x1F, 0x00},
+			},
+			[][]color.NRGBA{{red, blue}},
+		},
+		{
+			"16 bit 565 bitfields",
+			bmpFile{
+				width: 2, height: 1, bpp: 16, compression: 3,
+				extra:  bmpMasks(0xF800, 0x07E0, 0x001F),
+				pixels: []byte{0x00, 0xF8, 0xE0, 0x07},
+			},
+			[][]color.NRGBA{{red, green}},
+		},
+		{
+			"24 bit",
+			bmpFile{
+				width: 1, height: 2, bpp: 24,
+				pixels: []byte{0xFF, 0, 0, 0, 0, 0, 0xFF, 0},
+			},
+			[][]color.NRGBA{{red}, {blue}},
+		},
+		{
+			"24 bit top down",
+			bmpFile{
+				width: 1, height: -2, bpp: 24,
+				pixels: []byte{0xFF, 0, 0, 0, 0, 0, 0xFF, 0},
+			},
+			[][]color.NRGBA{{blue}, {red}},
+		},
+		{
+			"32 bit",
+			bmpFile{
+				width: 2, height: 1, bpp: 32,
+				pixels: []byte{0, 0xFF, 0, 0x12, 0xFF, 0xFF, 0xFF, 0x34},
+			},
+			[][]color.NRGBA{{green, white}},
+		},
+		{
+			"32 bit bitfields",
+			bmpFile{
+				width: 1, height: 1, bpp: 32, compression: 3,
+				extra:  bmpMasks(0xFF, 0xFF00, 0xFF0000),
+				pixels: []byte{0xFF, 0, 0, 0},
+			},
+			[][]color.NRGBA{{red}},
+		},
+		{
+			"32 bit bitfields with alpha in the header",
+			bmpFile{
+				width: 1, height: 1, bpp: 32, compression: 3, headerSize: 56,
+				headerMasks: []uint32{0xFF0000, 0xFF00, 0xFF, 0xFF000000},
+				pixels:      []byte{0, 0, 0xFF, 0x80},
+			},
+			[][]color.NRGBA{{{0xFF, 0, 0, 0x80}}},
+		},
+	}
+
+	for _, test := range tests {
+		data := test.file.bytes()
+
+		img, format, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if format != "bmp" {
+			t.Errorf("%s: format = %q, want bmp", test.name, format)
+		}
+
+		size := image.Pt(len(test.want[0]), len(test.want))
+		if got := img.Bounds().Size(); got != size {
+			t.Errorf("%s: size = %v, want %v", test.name, got, size)
+			continue
+		}
+
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil || cfg.Width != size.X || cfg.Height != size.Y {
+			t.Errorf("%s: config = %dx%d, %v, want %v", test.name, cfg.Width, cfg.Height, err, size)
+		}
+
+		for y, row := range test.want {
+			for x, want := range row {
+				if got := color.NRGBAModel.Convert(img.At(x, y)); got != want {
+					t.Errorf("%s: pixel %d,%d = %v, want %v", test.name, x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestDecodeBMPErrors(t *testing.T) {
+	valid := bmpFile{width: 1, height: 1, bpp: 24, pixels: []byte{0, 0, 0, 0}}
+
+	// Everything but the pixels being cut short is wrong in the headers, so
+	// DecodeConfig fails as well
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"truncated file header", valid.bytes()[:10]},
+		{"truncated info header", valid.bytes()[:30]},
+		{"small info header", func() []byte {
+			f := valid
+			f.headerSize = 12
+			return f.bytes()
+		}()},
+		{"huge info header", func() []byte {
+			b := valid.bytes()
+			binary.LittleEndian.PutUint32(b[14:], 0xFFFFFFF0)
+			return b
+		}()},
+		{"zero width", func() []byte {
+			f := valid
+			f.width = 0
+			return f.bytes()
+		}()},
+		{"huge width", func() []byte {
+			f := valid
+			f.width = 1 << 30
+			return f.bytes()
+		}()},
+		{"too many pixels", func() []byte {
+			f := valid
+			f.width, f.height = 1<<16, -(1 << 16)
+			return f.bytes()
+		}()},
+		{"smallest height", func() []byte {
+			f := valid
+			f.height = -1 << 31
+			return f.bytes()
+		}()},
+		{"two planes", func() []byte {
+			f := valid
+			f.planes = 2
+			return f.bytes()
+		}()},
+		{"run length encoded", func() []byte {
+			f := valid
+			f.bpp, f.compression = 8, 1
+			return f.bytes()
+		}()},
+		{"2 bit", func() []byte {
+			f := valid
+			f.bpp = 2
+			return f.bytes()
+		}()},
+		{"24 bit bitfields", func() []byte {
+			f := valid
+			f.compression = 3
+			return f.bytes()
+		}()},
+		{"truncated palette", func() []byte {
+			f := valid
+			f.bpp, f.colors, f.extra, f.pixels = 8, 16, bmpPalette(red), nil
+			return f.bytes()
+		}()},
+		{"truncated masks", func() []byte {
+			f := valid
+			f.bpp, f.compression, f.pixels = 16, 3, []byte{0xFF}
+			return f.bytes()
+		}()},
+	}
+
+	for _, test := range tests {
+		if _, _, err := image.DecodeConfig(bytes.NewReader(test.data)); err == nil {
+			t.Errorf("%s: DecodeConfig didn't fail", test.name)
+		}
+		if _, _, err := image.Decode(bytes.NewReader(test.data)); err == nil {
+			t.Errorf("%s: Decode didn't fail", test.name)
+		}
+	}
+
+	if _, _, err := image.Decode(bytes.NewReader(valid.bytes()[:14+40+2])); err == nil {
+		t.Error("truncated pixels: Decode didn't fail")
+	}
+}
@@ -1,13 +1,8 @@
 package gfx
 
-/*
-#cgo pkg-config: hge-unix-c
-#include "hge_c.h"
-*/
-import "C"
-
 import (
-	"fmt"
+	"errors"
+	"image"
 	"runtime"
 	"unsafe"
 
@@ -16,28 +11,28 @@ import (
 
 // HGE Blending constants
 const (
-	BLEND_COLORADD   = C.BLEND_COLORADD
-	BLEND_COLORMUL   = C.BLEND_COLORMUL
-	BLEND_ALPHABLEND = C.BLEND_ALPHABLEND
-	BLEND_ALPHAADD   = C.BLEND_ALPHAADD
-	BLEND_ZWRITE     = C.BLEND_ZWRITE
-	BLEND_NOZWRITE   = C.BLEND_NOZWRITE
-
-	BLEND_DEFAULT   = C.BLEND_DEFAULT
-	BLEND_DEFAULT_Z = C.BLEND_DEFAULT_Z
+	BLEND_COLORADD   = 1
+	BLEND_COLORMUL   = 0
+	BLEND_ALPHABLEND = 2
+	BLEND_ALPHAADD   = 0
+	BLEND_ZWRITE     = 4
+	BLEND_NOZWRITE   = 0
+
+	BLEND_DEFAULT   = BLEND_COLORMUL | BLEND_ALPHABLEND | BLEND_NOZWRITE
+	BLEND_DEFAULT_Z = BLEND_COLORMUL | BLEND_ALPHABLEND | BLEND_ZWRITE
 )
 
 // HGE_FPS system state special constants
 const (
-	FPS_UNLIMITED = C.HGE_FPS_UNLIMITED
-	FPS_VSYNC     = C.HGE_FPS_VSYNC
+	FPS_UNLIMITED = 0
+	FPS_VSYNC     = -1
 )
 
 // HGE Primitive type constants
 const (
-	PRIM_LINES   = C.HGE_PRIM_LINES
-	PRIM_TRIPLES = C.HGE_PRIM_TRIPLES
-	PRIM_QUADS   = C.HGE_PRIM_QUADS
+	PRIM_LINES   = 2
+	PRIM_TRIPLES = 3
+	PRIM_QUADS   = 4
 )
 
 // HGE Vertex structure
@@ -68,22 +63,6 @@ type Quad struct {
 	Blend int
 }
 
-type cTriple struct {
-	v     [3]Vertex
-	tex   C.HTEXTURE
-	Blend int
-}
-
-type cQuad struct {
-	V     [4]Vertex
-	tex   C.HTEXTURE
-	Blend int
-}
-
-func boolToCInt(b bool) C.BOOL {
-	return C.BOOL(hge.BoolToCInt(b))
-}
-
 var gfxHGE *hge.HGE
 
 func init() {
@@ -91,24 +70,37 @@ func init() {
 }
 
 func BeginScene(a ...interface{}) bool {
+	var target hge.HTarget
 	if len(a) == 1 {
-		if target, ok := a[0].(Target); ok {
-			return C.HGE_Gfx_BeginScene(gfxHGE.HGE, target.target) == 1
+		if t, ok := a[0].(Target); ok {
+			target = t.target
 		}
-		if target, ok := a[0].(*Target); ok {
-			return C.HGE_Gfx_BeginScene(gfxHGE.HGE, target.target) == 1
+		if t, ok := a[0].(*Target); ok {
+			target = t.target
 		}
 	}
 
-	return C.HGE_Gfx_BeginScene(gfxHGE.HGE, 0) == 1
+	if !backend().BeginScene(target) {
+		return false
+	}
+	resetStacks()
+
+	return true
 }
 
 func EndScene() {
-	C.HGE_Gfx_EndScene(gfxHGE.HGE)
+	flushQueues()
+	flushBatch()
+	backend().EndScene()
 }
 
 func Clear(color hge.Dword) {
-	C.HGE_Gfx_Clear(gfxHGE.HGE, C.DWORD(color))
+	flushBatch()
+	if clippedAway() {
+		return
+	}
+
+	backend().Clear(color)
 }
 
 func NewLine(x1, y1, x2, y2 float64, a ...interface{}) Line {
@@ -138,48 +130,83 @@ func NewLine(x1, y1, x2, y2 float64, a ...interface{}) Line {
 }
 
 func (l Line) Render() {
-	C.HGE_Gfx_RenderLine(gfxHGE.HGE, C.float(l.X1), C.float(l.Y1), C.float(l.X2), C.float(l.Y2), C.DWORD(l.Color), C.float(l.Z))
+	flushBatch()
+	if clippedAway() {
+		return
+	}
+
+	x1, y1, x2, y2 := l.X1, l.Y1, l.X2, l.Y2
+	if t := transforms[len(transforms)-1]; t.software {
+		x1, y1 = t.m.Apply(x1, y1)
+		x2, y2 = t.m.Apply(x2, y2)
+	}
+
+	backend().RenderLine(x1, y1, x2, y2, l.Color, l.Z)
 }
 
 func (t *Triple) Render() {
-	var ct *cTriple
-
-	if t.Texture != nil {
-		ct = &cTriple{t.V, t.Texture.texture, t.Blend}
-	} else {
-		ct = &cTriple{t.V, 0, t.Blend}
+	flushBatch()
+	if clippedAway() {
+		return
 	}
-	C.HGE_Gfx_RenderTriple(gfxHGE.HGE, (*C.HGE_Triple_t)(unsafe.Pointer(ct)))
+
+	v := t.V
+	transformVertices(v[:])
+
+	backend().RenderTriple((*[3]hge.BackendVertex)(unsafe.Pointer(&v)), t.Texture.handle(), blendMode(t.Blend))
 }
 
+// Draws the quad, or adds it to the sprite batch that's begun.
 func (q *Quad) Render() {
-	var cq *cQuad
-
-	if q.Texture != nil {
-		cq = &cQuad{q.V, q.Texture.texture, q.Blend}
-	} else {
-		cq = &cQuad{q.V, 0, q.Blend}
+	if activeBatch != nil {
+		activeBatch.Add(q)
+		return
 	}
-	C.HGE_Gfx_RenderQuad(gfxHGE.HGE, (*C.HGE_Quad_t)(unsafe.Pointer(cq)))
+	if clippedAway() {
+		return
+	}
+
+	v := q.V
+	transformVertices(v[:])
+
+	backend().RenderQuad((*[4]hge.BackendVertex)(unsafe.Pointer(&v)), q.Texture.handle(), blendMode(q.Blend))
 }
 
 func StartBatch(prim_type int, tex *Texture, blend int) (ver *Vertex, max_prim int, ok bool) {
-	mp := C.int(0)
+	flushBatch()
+	if clippedAway() {
+		return nil, 0, false
+	}
 
-	v := C.HGE_Gfx_StartBatch(gfxHGE.HGE, C.int(prim_type), tex.texture, C.int(blend), &mp)
+	v, mp := backend().StartBatch(prim_type, tex.handle(), blendMode(blend))
 
 	if v == nil {
 		return nil, 0, false
 	}
 
-	return (*Vertex)(unsafe.Pointer(v)), int(mp), true
+	rawBatch.v, rawBatch.primType = v, prim_type
+
+	return (*Vertex)(unsafe.Pointer(v)), mp, true
 }
 
 func FinishBatch(prim int) {
-	C.HGE_Gfx_FinishBatch(gfxHGE.HGE, C.int(prim))
+	finishRawBatch(prim)
+	backend().FinishBatch(prim)
+}
+
+// Returns a copy of what's been drawn to the screen, or nil if the backend
+// can't read it back.
+func ScreenImage() *image.RGBA {
+	if b, ok := gfxHGE.Backend().(hge.ImageBackend); ok {
+		return b.ScreenImage()
+	}
+
+	return nil
 }
 
 func SetClipping(a ...interface{}) {
+	flushBatch()
+
 	var x, y, w, hi int
 
 	for i := 0; i < len(a); i++ {
@@ -205,10 +232,14 @@ func SetClipping(a ...interface{}) {
 		}
 	}
 
-	C.HGE_Gfx_SetClipping(gfxHGE.HGE, C.int(x), C.int(y), C.int(w), C.int(hi))
+	// This replaces the clipping pushed last, rather than pushing
+	clips[len(clips)-1] = clipState{image.Rect(x, y, x+w, y+hi), w > 0 && hi > 0}
+	applyClipping()
 }
 
 func SetTransform(a ...interface{}) {
+	flushBatch()
+
 	var (
 		x, y, dx, dy        float64
 		rot, hscale, vscale float64
@@ -273,62 +304,94 @@ func SetTransform(a ...interface{}) {
 		}
 	}
 
-	C.HGE_Gfx_SetTransform(gfxHGE.HGE, C.float(x), C.float(y), C.float(dx), C.float(dy), C.float(rot), C.float(hscale), C.float(vscale))
+	// This replaces the transform pushed last, rather than pushing
+	transforms[len(transforms)-1] = transformState{m: TransformMatrix(x, y, dx, dy, rot, hscale, vscale)}
+	backend().SetTransform(x, y, dx, dy, rot, hscale, vscale)
 }
 
 // HGE Handle type
 type Target struct {
-	target C.HTARGET
+	target hge.HTarget
 }
 
 func NewTarget(width, height int, zbuffer bool) *Target {
 	t := new(Target)
-	t.target = C.HGE_Target_Create(gfxHGE.HGE, C.int(width), C.int(height), boolToCInt(zbuffer))
+	t.target = gfxHGE.Backend().TargetCreate(width, height, zbuffer)
 
 	if t.target == 0 {
 		return nil
 	}
+	trackTarget(t, width, height, zbuffer)
 
+	// Finalizers run on their own goroutine, so the free is handed to the
+	// engine thread
 	runtime.SetFinalizer(t, func(target *Target) {
-		target.Free()
+		hge.DoAsync(target.Free)
 	})
 
 	return t
 }
 
+// Frees the target. Freeing it again does nothing.
 func (t *Target) Free() {
-	fmt.Println("Freeing Target", t)
-	C.HGE_Target_Free(gfxHGE.HGE, t.target)
+	if t.target == 0 {
+		return
+	}
+
+	hge.Logger(hge.LogGfx).Debug("Freeing target", "target", t.target)
+	runtime.SetFinalizer(t, nil)
+	untrackTarget(t.target)
+	gfxHGE.Backend().TargetFree(t.target)
+	t.target = 0
+}
+
+// Returns a copy of what's been drawn to the target, or nil if the backend
+// can't read it back.
+func (t *Target) Image() *image.RGBA {
+	if b, ok := gfxHGE.Backend().(hge.ImageBackend); ok {
+		return b.TargetImage(t.target)
+	}
+
+	return nil
 }
 
 func (t *Target) Texture() *Texture {
-	return &Texture{C.HGE_Target_GetTexture(gfxHGE.HGE, t.target)}
+	return &Texture{texture: gfxHGE.Backend().TargetTexture(t.target)}
 }
 
 // HGE Handle type
 type Texture struct {
-	texture C.HTEXTURE
+	texture hge.HTexture
+	// The size of the image in a texture padded to a power of two
+	width, height int
+}
+
+// The backend handle of the texture, a nil texture has a zero handle
+func (t *Texture) handle() hge.HTexture {
+	if t == nil {
+		return 0
+	}
+
+	return t.texture
 }
 
 func NewTexture(width, height int) *Texture {
 	t := new(Texture)
-	t.texture = C.HGE_Texture_Create(gfxHGE.HGE, C.int(width), C.int(height))
+	t.texture = gfxHGE.Backend().TextureCreate(width, height)
 
 	if t.texture == 0 {
 		return nil
 	}
+	trackTexture(t, width, height)
 
 	runtime.SetFinalizer(t, func(texture *Texture) {
-		texture.Free()
+		hge.DoAsync(texture.Free)
 	})
 
 	return t
 }
 
-func LoadTexture(filename string, a ...interface{}) *Texture {
-	fname := C.CString(filename)
-	defer C.free(unsafe.Pointer(fname))
-
+func LoadTexture(filename string, a ...interface{}) (*Texture, error) {
 	size := hge.Dword(0)
 	mipmap := false
 
@@ -346,21 +409,36 @@ func LoadTexture(filename string, a ...interface{}) *Texture {
 	}
 
 	t := new(Texture)
-	t.texture = C.HGE_Texture_Load(gfxHGE.HGE, fname, C.DWORD(size), boolToCInt(mipmap))
+	t.texture = gfxHGE.Backend().TextureLoad(filename, size, mipmap)
 	if t.texture == 0 {
-		return nil
+		err := gfxHGE.LoadFailed("gfx.LoadTexture", filename, hge.Initiated())
+		// The backend may not have a decoder for the format Go has
+		if errors.Is(err, hge.ErrFormat) {
+			if t, e := LoadImageTexture(filename); e == nil {
+				return t, nil
+			}
+		}
+		return nil, err
 	}
 
 	runtime.SetFinalizer(t, func(texture *Texture) {
-		texture.Free()
+		hge.DoAsync(texture.Free)
 	})
 
-	return t
+	return t, nil
 }
 
+// Frees the texture. Freeing it again does nothing.
 func (t *Texture) Free() {
-	fmt.Println("Freeing texture", t)
-	C.HGE_Texture_Free(gfxHGE.HGE, t.texture)
+	if t.texture == 0 {
+		return
+	}
+
+	hge.Logger(hge.LogGfx).Debug("Freeing texture", "texture", t.texture)
+	runtime.SetFinalizer(t, nil)
+	untrackTexture(t.texture)
+	gfxHGE.Backend().TextureFree(t.texture)
+	t.texture = 0
 }
 
 func (t *Texture) Width(a ...interface{}) int {
@@ -371,7 +449,11 @@ func (t *Texture) Width(a ...interface{}) int {
 		}
 	}
 
-	return int(C.HGE_Texture_GetWidth(gfxHGE.HGE, t.texture, boolToCInt(original)))
+	if original && t.width > 0 {
+		return t.width
+	}
+
+	return gfxHGE.Backend().TextureWidth(t.texture, original)
 }
 
 func (t *Texture) Height(a ...interface{}) int {
@@ -382,7 +464,11 @@ func (t *Texture) Height(a ...interface{}) int {
 		}
 	}
 
-	return int(C.HGE_Texture_GetHeight(gfxHGE.HGE, t.texture, boolToCInt(original)))
+	if original && t.height > 0 {
+		return t.height
+	}
+
+	return gfxHGE.Backend().TextureHeight(t.texture, original)
 }
 
 func (t *Texture) Lock(a ...interface{}) *hge.Dword {
@@ -417,10 +503,9 @@ func (t *Texture) Lock(a ...interface{}) *hge.Dword {
 		}
 	}
 
-	d := C.HGE_Texture_Lock(gfxHGE.HGE, t.texture, boolToCInt(readonly), C.int(left), C.int(top), C.int(width), C.int(height))
-	return (*hge.Dword)(d)
+	return gfxHGE.Backend().TextureLock(t.texture, readonly, left, top, width, height)
 }
 
 func (t *Texture) Unlock() {
-	C.HGE_Texture_Unlock(gfxHGE.HGE, t.texture)
+	gfxHGE.Backend().TextureUnlock(t.texture)
 }
@@ -0,0 +1,28 @@
+//go:build !headless
+
+package gfx
+
+/*
+#cgo pkg-config: hge-unix-c
+#include "hge_c.h"
+*/
+import "C"
+
+// The blend, FPS and primitive constants in gfx.go are hard-coded so
+// headless builds don't need hge_c.h. These stop compiling if one drifts
+// from the header.
+var (
+	_ = [1]struct{}{}[BLEND_COLORADD-C.BLEND_COLORADD]
+	_ = [1]struct{}{}[BLEND_COLORMUL-C.BLEND_COLORMUL]
+	_ = [1]struct{}{}[BLEND_ALPHABLEND-C.BLEND_ALPHABLEND]
+	_ = [1]struct{}{}[BLEND_ALPHAADD-C.BLEND_ALPHAADD]
+	_ = [1]struct{}{}[BLEND_ZWRITE-C.BLEND_ZWRITE]
+	_ = [1]struct{}{}[BLEND_NOZWRITE-C.BLEND_NOZWRITE]
+	_ = [1]struct{}{}[BLEND_DEFAULT-C.BLEND_DEFAULT]
+	_ = [1]struct{}{}[BLEND_DEFAULT_Z-C.BLEND_DEFAULT_Z]
+	_ = [1]struct{}{}[FPS_UNLIMITED-C.HGE_FPS_UNLIMITED]
+	_ = [1]struct{}{}[FPS_VSYNC-C.HGE_FPS_VSYNC]
+	_ = [1]struct{}{}[PRIM_LINES-C.HGE_PRIM_LINES]
+	_ = [1]struct{}{}[PRIM_TRIPLES-C.HGE_PRIM_TRIPLES]
+	_ = [1]struct{}{}[PRIM_QUADS-C.HGE_PRIM_QUADS]
+)
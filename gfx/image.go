@@ -0,0 +1,230 @@
+package gfx
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"unsafe"
+
+	"github.com/losinggeneration/hge"
+	"github.com/losinggeneration/hge/resource"
+)
+
+// How images are turned into textures. Pass one, or a pointer to one, to
+// NewTextureFromImage and the functions loading images.
+type TextureOptions struct {
+	// Pixels of ColorKey's color, whatever their alpha, are made
+	// transparent when UseColorKey is set
+	ColorKey    hge.Dword
+	UseColorKey bool
+
+	// Multiplies colors by their alpha, to draw with premultiplied blending
+	Premultiply bool
+
+	// Pads the texture on the right and bottom to a power of two size, for
+	// hardware that needs it. Width(true) and Height(true) still return the
+	// size of the image.
+	PowerOfTwo bool
+}
+
+func textureOptions(a []interface{}) TextureOptions {
+	for i := 0; i < len(a); i++ {
+		if o, ok := a[i].(TextureOptions); ok {
+			return o
+		}
+		if o, ok := a[i].(*TextureOptions); ok && o != nil {
+			return *o
+		}
+	}
+
+	return TextureOptions{}
+}
+
+// Returns the image as it goes into the texture, with the options applied.
+func (o *TextureOptions) apply(img image.Image) *image.NRGBA {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if o.PowerOfTwo {
+		w, h = powerOfTwo(w), powerOfTwo(h)
+	}
+
+	n := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(n, b.Sub(b.Min), img, b.Min, draw.Src)
+	if !o.UseColorKey && !o.Premultiply {
+		return n
+	}
+
+	key := [3]uint8{uint8(o.ColorKey >> 16), uint8(o.ColorKey >> 8), uint8(o.ColorKey)}
+	for i := 0; i < len(n.Pix); i += 4 {
+		p := n.Pix[i : i+4 : i+4]
+		if o.UseColorKey && p[0] == key[0] && p[1] == key[1] && p[2] == key[2] {
+			// Black, so filtering doesn't bleed the key's color in
+			p[0], p[1], p[2], p[3] = 0, 0, 0, 0
+		} else if o.Premultiply {
+			a := uint16(p[3])
+			p[0] = uint8((uint16(p[0])*a + 127) / 255)
+			p[1] = uint8((uint16(p[1])*a + 127) / 255)
+			p[2] = uint8((uint16(p[2])*a + 127) / 255)
+		}
+	}
+
+	return n
+}
+
+func powerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+
+	return p
+}
+
+// Creates a texture the size of img holding a copy of it. TextureOptions can
+// be passed to change how it's copied. If the texture has to be made again
+// when the graphics device is restored, it's filled from img again.
+func NewTextureFromImage(img image.Image, a ...interface{}) (*Texture, error) {
+	o := textureOptions(a)
+	b := img.Bounds()
+
+	var src image.Image = img
+	if o != (TextureOptions{}) {
+		src = o.apply(img)
+	}
+	sb := src.Bounds()
+
+	t := NewTexture(sb.Dx(), sb.Dy())
+	if t == nil {
+		return nil, gfxHGE.NewLoadError("gfx.NewTextureFromImage", "", hge.ErrDevice)
+	}
+
+	rect := image.Rect(0, 0, sb.Dx(), sb.Dy())
+	if err := t.Upload(rect, src); err != nil {
+		t.Free()
+		return nil, err
+	}
+	t.OnRestore(func(t *Texture) {
+		t.Upload(rect, src)
+	})
+	if o.PowerOfTwo {
+		t.width, t.height = b.Dx(), b.Dy()
+	}
+
+	return t, nil
+}
+
+// Decodes an image from r into a new texture. Any format registered with the
+// image package can be read, gfx registers PNG, JPEG, GIF and BMP.
+// TextureOptions can be passed the same as NewTextureFromImage.
+func LoadTextureFromReader(r io.Reader, a ...interface{}) (*Texture, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, &hge.LoadError{Op: "gfx.LoadTextureFromReader", Message: err.Error(), Err: hge.ErrFormat}
+	}
+
+	return NewTextureFromImage(img, a...)
+}
+
+// Decodes an image held in memory into a new texture.
+func LoadTextureFromBytes(data []byte, a ...interface{}) (*Texture, error) {
+	return LoadTextureFromReader(bytes.NewReader(data), a...)
+}
+
+// Loads an image through the resource system and decodes it with Go's image
+// package, rather than the backend's decoder.
+func LoadImage(filename string) (image.Image, error) {
+	data, err := resource.LoadBytes(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, &hge.LoadError{Op: "gfx.LoadImage", Filename: filename, Message: err.Error(), Err: hge.ErrFormat}
+	}
+
+	return img, nil
+}
+
+// Loads an image into a new texture with Go's image package, so it works
+// the same with every backend. TextureOptions can be passed the same as
+// NewTextureFromImage.
+func LoadImageTexture(filename string, a ...interface{}) (*Texture, error) {
+	img, err := LoadImage(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := NewTextureFromImage(img, a...)
+	if e, ok := err.(*hge.LoadError); ok {
+		e.Op, e.Filename = "gfx.LoadImageTexture", filename
+	}
+
+	return t, err
+}
+
+// Copies img into the rect of the texture, starting from the top left of
+// img. Whatever falls outside the texture or img is left alone.
+func (t *Texture) Upload(rect image.Rectangle, img image.Image) error {
+	w, h := t.Width(true), t.Height(true)
+
+	src := img.Bounds()
+	rect = rect.Intersect(image.Rect(0, 0, w, h))
+	rect = rect.Intersect(src.Sub(src.Min).Add(rect.Min))
+	if rect.Empty() {
+		return nil
+	}
+
+	// Texels are unpremultiplied, Go images mostly aren't
+	n := image.NewNRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(n, n.Rect, img, src.Min, draw.Src)
+
+	// The whole texture is locked as backends differ on how a locked
+	// rectangle is laid out
+	p := t.Lock(false)
+	if p == nil {
+		return gfxHGE.NewLoadError("gfx.Texture.Upload", "", hge.ErrDevice)
+	}
+	defer t.Unlock()
+
+	pitch := t.Width()
+	buf := unsafe.Slice(p, pitch*t.Height())
+	for y := 0; y < rect.Dy(); y++ {
+		row := buf[(rect.Min.Y+y)*pitch+rect.Min.X:]
+		for x := 0; x < rect.Dx(); x++ {
+			s := n.Pix[n.PixOffset(x, y):]
+			row[x] = hge.Dword(s[3])<<24 | hge.Dword(s[0])<<16 | hge.Dword(s[1])<<8 | hge.Dword(s[2])
+		}
+	}
+
+	return nil
+}
+
+// Returns a copy of the texture, or nil if it can't be locked.
+func (t *Texture) ToImage() *image.NRGBA {
+	w, h := t.Width(true), t.Height(true)
+
+	p := t.Lock(true)
+	if p == nil {
+		return nil
+	}
+	defer t.Unlock()
+
+	pitch := t.Width()
+	buf := unsafe.Slice(p, pitch*t.Height())
+
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := buf[y*pitch+x]
+			d := img.Pix[img.PixOffset(x, y):]
+			d[0], d[1], d[2], d[3] = uint8(c>>16), uint8(c>>8), uint8(c), uint8(c>>24)
+		}
+	}
+
+	return img
+}
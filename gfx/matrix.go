@@ -0,0 +1,111 @@
+package gfx
+
+import "math"
+
+// A 2D affine transform. A point x, y is moved to
+//
+//	A*x + C*y + TX, B*x + D*y + TY
+type Matrix struct {
+	A, B, C, D, TX, TY float64
+}
+
+// Returns the transform that leaves points where they are.
+func Identity() Matrix {
+	return Matrix{A: 1, D: 1}
+}
+
+// Returns a transform moving points by x, y.
+func Translate(x, y float64) Matrix {
+	return Matrix{A: 1, D: 1, TX: x, TY: y}
+}
+
+// Returns a transform scaling points around the origin.
+func Scale(sx, sy float64) Matrix {
+	return Matrix{A: sx, D: sy}
+}
+
+// Returns a transform rotating points around the origin, clockwise on the
+// screen like sprite.RenderEx.
+func Rotate(rot float64) Matrix {
+	sin, cos := math.Sincos(rot)
+	return Matrix{A: cos, B: sin, C: -sin, D: cos}
+}
+
+// Returns the transform SetTransform sets with the same arguments: scaling
+// by hscale and vscale and rotating by rot around x, y, then moving by dx,
+// dy. A zero vscale is the identity, the same as SetTransform.
+func TransformMatrix(x, y, dx, dy, rot, hscale, vscale float64) Matrix {
+	if vscale == 0 {
+		return Identity()
+	}
+
+	sin, cos := math.Sincos(rot)
+	m := Matrix{
+		A: hscale * cos, B: -hscale * sin,
+		C: vscale * sin, D: vscale * cos,
+	}
+	m.TX = x + dx - m.A*x - m.C*y
+	m.TY = y + dy - m.B*x - m.D*y
+
+	return m
+}
+
+// Returns the transform applying n first and then m.
+func (m Matrix) Mul(n Matrix) Matrix {
+	return Matrix{
+		A:  m.A*n.A + m.C*n.B,
+		B:  m.B*n.A + m.D*n.B,
+		C:  m.A*n.C + m.C*n.D,
+		D:  m.B*n.C + m.D*n.D,
+		TX: m.A*n.TX + m.C*n.TY + m.TX,
+		TY: m.B*n.TX + m.D*n.TY + m.TY,
+	}
+}
+
+// Moves a point by the transform.
+func (m Matrix) Apply(x, y float64) (float64, float64) {
+	return m.A*x + m.C*y + m.TX, m.B*x + m.D*y + m.TY
+}
+
+// Returns the transform that undoes m, or false if m flattens points onto a
+// line and can't be undone.
+func (m Matrix) Invert() (Matrix, bool) {
+	det := m.A*m.D - m.B*m.C
+	if det == 0 {
+		return Matrix{}, false
+	}
+
+	i := Matrix{
+		A: m.D / det, B: -m.B / det,
+		C: -m.C / det, D: m.A / det,
+	}
+	i.TX = -(i.A*m.TX + i.C*m.TY)
+	i.TY = -(i.B*m.TX + i.D*m.TY)
+
+	return i, true
+}
+
+func (m Matrix) isIdentity() bool {
+	return m == Identity()
+}
+
+// Splits the transform into the rotation, scales and offset SetTransform
+// takes. Transforms that shear can't be, and return false.
+func (m Matrix) decompose() (dx, dy, rot, hscale, vscale float64, ok bool) {
+	hscale = math.Hypot(m.A, m.B)
+	vlen := math.Hypot(m.C, m.D)
+	if hscale == 0 || vlen == 0 {
+		return 0, 0, 0, 0, 0, false
+	}
+
+	// The columns have to be at right angles
+	if math.Abs(m.A*m.C+m.B*m.D) > 1e-9*hscale*vlen {
+		return 0, 0, 0, 0, 0, false
+	}
+
+	rot = math.Atan2(-m.B, m.A)
+	sin, cos := math.Sincos(rot)
+	vscale = m.C*sin + m.D*cos
+
+	return m.TX, m.TY, rot, hscale, vscale, true
+}
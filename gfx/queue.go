@@ -0,0 +1,167 @@
+package gfx
+
+import "sort"
+
+// RenderQueue holds draws submitted with a layer and z until the scene ends,
+// then draws them sorted: lower layers first and, within a layer, the largest
+// z first, the same as the z-buffer would show them. Draws with the same
+// layer and z keep the order they were submitted in, except that the quads
+// they render are grouped by texture so they batch. Gameplay code can submit
+// draws from anywhere, in any order, without ZBUFFER on.
+//
+// The transform, clipping and blend mode in effect when a draw is submitted
+// are put back when it's drawn, so a draw submitted through a camera is still
+// drawn through it.
+type RenderQueue struct {
+	items   []queueItem
+	batch   *SpriteBatch
+	pending bool
+}
+
+type queueItem struct {
+	layer int
+	z     float64
+	draw  func()
+
+	transform Matrix
+	clip      clipState
+	blend     int
+	hasBlend  bool
+}
+
+// The queues with draws waiting, in the order they were first submitted to,
+// which EndScene flushes.
+var pendingQueues []*RenderQueue
+
+var defaultQueue = NewRenderQueue()
+
+// Creates an empty render queue.
+func NewRenderQueue() *RenderQueue {
+	return &RenderQueue{batch: NewSpriteBatch(0)}
+}
+
+// Submits a draw to the package's render queue.
+func Submit(layer int, z float64, draw func()) {
+	defaultQueue.Submit(layer, z, draw)
+}
+
+// Adds a draw to the queue. draw is called when the scene ends, so it has to
+// hold on to anything it needs, like where a sprite is.
+func (q *RenderQueue) Submit(layer int, z float64, draw func()) {
+	item := queueItem{
+		layer:     layer,
+		z:         z,
+		draw:      draw,
+		transform: Transform(),
+		clip:      clips[len(clips)-1],
+	}
+	item.blend, item.hasBlend = Blend()
+
+	q.items = append(q.items, item)
+	if !q.pending {
+		q.pending = true
+		pendingQueues = append(pendingQueues, q)
+	}
+}
+
+// Returns the number of draws waiting.
+func (q *RenderQueue) Len() int {
+	return len(q.items)
+}
+
+// Throws away the draws waiting.
+func (q *RenderQueue) Reset() {
+	clear(q.items)
+	q.items = q.items[:0]
+}
+
+// Draws what's waiting now, rather than when the scene ends.
+func (q *RenderQueue) Flush() {
+	if len(q.items) == 0 {
+		return
+	}
+
+	// Draws can submit more draws, which wait for the next flush
+	items := q.items
+	q.items = nil
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].layer != items[j].layer {
+			return items[i].layer < items[j].layer
+		}
+		return items[i].z > items[j].z
+	})
+
+	prevBatch := activeBatch
+	transform, clip := transforms[len(transforms)-1], clips[len(clips)-1]
+	blend := blends
+
+	for start := 0; start < len(items); {
+		end := start + 1
+		for end < len(items) && items[end].layer == items[start].layer && items[end].z == items[start].z {
+			end++
+		}
+
+		q.batch.Sort = SORT_TEXTURE
+		q.batch.Begin()
+		for i := start; i < end; i++ {
+			items[i].restore()
+			items[i].draw()
+		}
+		q.batch.End()
+
+		start = end
+	}
+
+	if transforms[len(transforms)-1].m != transform.m {
+		transforms[len(transforms)-1] = transform
+		applyTransform()
+	}
+	if clips[len(clips)-1] != clip {
+		clips[len(clips)-1] = clip
+		applyClipping()
+	}
+	blends = blend
+	if prevBatch != nil {
+		prevBatch.Begin()
+	}
+
+	clear(items)
+	if q.items == nil {
+		q.items = items[:0]
+	}
+}
+
+// Puts back the state the draw was submitted with, only changing what's
+// different so the batch isn't flushed for nothing.
+func (item *queueItem) restore() {
+	if transforms[len(transforms)-1].m != item.transform {
+		transforms[len(transforms)-1] = transformState{m: item.transform}
+		applyTransform()
+	}
+	if clips[len(clips)-1] != item.clip {
+		clips[len(clips)-1] = item.clip
+		applyClipping()
+	}
+
+	b, ok := Blend()
+	if ok != item.hasBlend || b != item.blend {
+		flushBatch()
+		blends = nil
+		if item.hasBlend {
+			blends = []int{item.blend}
+		}
+	}
+}
+
+// Flushes the queues with draws waiting, which EndScene does before the
+// scene ends.
+func flushQueues() {
+	for len(pendingQueues) > 0 {
+		q := pendingQueues[0]
+		pendingQueues = pendingQueues[1:]
+
+		q.pending = false
+		q.Flush()
+	}
+}
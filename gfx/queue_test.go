@@ -0,0 +1,163 @@
+package gfx_test
+
+import (
+	"image"
+	"testing"
+
+	"github.com/losinggeneration/hge"
+	"github.com/losinggeneration/hge/gfx"
+	"github.com/losinggeneration/hge/hgetest"
+)
+
+// A quad drawn by the queue, found by the id in its color.
+type drawn struct {
+	id    int
+	tex   hge.HTexture
+	blend int
+	dx    float64 // the transform's x offset it was drawn with
+}
+
+func drawnQuads(frame gfx.CommandList) []drawn {
+	var d []drawn
+	var dx float64
+	for _, c := range frame {
+		switch c.Kind {
+		case gfx.CMD_TRANSFORM:
+			dx = c.Args[2]
+		case gfx.CMD_QUAD, gfx.CMD_BATCH:
+			for i := 0; i < len(c.V); i += 4 {
+				d = append(d, drawn{int(c.V[i].Color & 0xFF), c.Texture, c.Blend, dx})
+			}
+		}
+	}
+
+	return d
+}
+
+type stacks struct {
+	transform gfx.Matrix
+	clip      image.Rectangle
+	clipped   bool
+	blend     int
+	blended   bool
+}
+
+func currentStacks() stacks {
+	var s stacks
+	var x, y, w, h int
+	s.transform = gfx.Transform()
+	x, y, w, h, s.clipped = gfx.Clipping()
+	s.clip = image.Rect(x, y, x+w, y+h)
+	s.blend, s.blended = gfx.Blend()
+
+	return s
+}
+
+func TestRenderQueueFlush(t *testing.T) {
+	var a, b *gfx.Texture
+	var frame gfx.CommandList
+	var before, flushed, ended stacks
+
+	submit := func(into func(int, float64, func()), id, layer int, z float64, tex *gfx.Texture) {
+		s := square(float32(id*3), 0, 2, 0xFF000000|hge.Dword(id))
+		s.Texture = tex
+		into(layer, z, s.Render)
+	}
+
+	_, err := hgetest.Render(hgetest.Scene{
+		Width: 32, Height: 32,
+		Frames: 2,
+		Setup: func() error {
+			// a has the lower handle, so it's grouped first
+			a, b = gfx.NewTexture(4, 4), gfx.NewTexture(4, 4)
+			gfx.StartRecording(func(l gfx.CommandList) {
+				if frame == nil {
+					frame = l
+				}
+			})
+			return nil
+		},
+		Frame: func(frame int) {
+			if frame == 1 {
+				ended = currentStacks()
+			}
+		},
+		Render: func(frame int) {
+			if frame != 0 {
+				return
+			}
+
+			gfx.PushTransform(gfx.Translate(0, 2))
+			gfx.PushClipping(0, 0, 30, 30)
+			before = currentStacks()
+
+			q := gfx.NewRenderQueue()
+			submit(q.Submit, 1, 1, 0.5, a)
+			submit(q.Submit, 2, 0, 0.2, b)
+			gfx.PushTransform(gfx.Translate(10, 0))
+			submit(q.Submit, 3, 0, 0.8, a)
+			gfx.PopTransform()
+			submit(q.Submit, 4, 0, 0.2, a)
+			submit(q.Submit, 5, 0, 0.2, b)
+			gfx.PushBlend(gfx.BLEND_COLORADD)
+			submit(q.Submit, 6, 1, 0.5, a)
+			gfx.PopBlend()
+			submit(q.Submit, 7, -1, 0, nil)
+
+			q.Flush()
+			flushed = currentStacks()
+
+			// The package's queue is flushed by EndScene
+			gfx.PushBlend(gfx.BLEND_ALPHAADD)
+			submit(gfx.Submit, 9, 2, 0, b)
+			gfx.PopBlend()
+			submit(gfx.Submit, 8, 2, 0.5, a)
+		},
+	})
+	gfx.StopRecording()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Layers first, then the largest z, then in the order they were
+	// submitted, except quads with the same layer and z are grouped by
+	// texture. A different blend mode is drawn on its own, in order.
+	want := []struct {
+		id    int
+		tex   string
+		blend int
+		dx    float64
+	}{
+		{7, "", gfx.BLEND_DEFAULT, 0},
+		{3, "a", gfx.BLEND_DEFAULT, 10},
+		{4, "a", gfx.BLEND_DEFAULT, 0},
+		{2, "b", gfx.BLEND_DEFAULT, 0},
+		{5, "b", gfx.BLEND_DEFAULT, 0},
+		{1, "a", gfx.BLEND_DEFAULT, 0},
+		{6, "a", gfx.BLEND_COLORADD, 0},
+		{8, "a", gfx.BLEND_DEFAULT, 0},
+		{9, "b", gfx.BLEND_ALPHAADD, 0},
+	}
+	got := drawnQuads(frame)
+	if len(got) != len(want) {
+		t.Fatalf("drew %v, want %v", got, want)
+	}
+
+	// The handles come from the quads drawn with them
+	handles := map[string]hge.HTexture{"": 0, "a": got[1].tex, "b": got[3].tex}
+	if handles["a"] == 0 || handles["a"] >= handles["b"] {
+		t.Fatalf("textures %v, want a before b", handles)
+	}
+	for i, w := range want {
+		if g := got[i]; g.id != w.id || g.tex != handles[w.tex] || g.blend != w.blend || g.dx != w.dx {
+			t.Errorf("quad %d is %+v, want %+v", i, g, w)
+		}
+	}
+
+	if flushed != before {
+		t.Errorf("after Flush the stacks are %+v, want %+v", flushed, before)
+	}
+	if ended != before {
+		t.Errorf("after EndScene the stacks are %+v, want %+v", ended, before)
+	}
+}
@@ -0,0 +1,406 @@
+package gfx
+
+import (
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"github.com/losinggeneration/hge"
+)
+
+// The kinds of draw call a Command can be
+type CommandKind int
+
+const (
+	CMD_BEGINSCENE CommandKind = iota
+	CMD_ENDSCENE
+	CMD_CLEAR
+	CMD_LINE
+	CMD_TRIPLE
+	CMD_QUAD
+	CMD_BATCH
+	CMD_CLIPPING
+	CMD_TRANSFORM
+)
+
+var commandNames = [...]string{
+	CMD_BEGINSCENE: "beginscene",
+	CMD_ENDSCENE:   "endscene",
+	CMD_CLEAR:      "clear",
+	CMD_LINE:       "line",
+	CMD_TRIPLE:     "triple",
+	CMD_QUAD:       "quad",
+	CMD_BATCH:      "batch",
+	CMD_CLIPPING:   "clipping",
+	CMD_TRANSFORM:  "transform",
+}
+
+func (k CommandKind) String() string {
+	if k >= 0 && int(k) < len(commandNames) {
+		return commandNames[k]
+	}
+
+	return fmt.Sprintf("CommandKind(%d)", int(k))
+}
+
+// Kinds are saved by name, so recordings stay readable.
+func (k CommandKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+func (k *CommandKind) UnmarshalText(text []byte) error {
+	for i, name := range commandNames {
+		if name == string(text) {
+			*k = CommandKind(i)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("gfx: unknown command %q", text)
+}
+
+// A draw call as the backend was given it, after the transform, clipping and
+// blend stacks and sprite batches have done their work.
+type Command struct {
+	Kind CommandKind
+
+	Target  hge.HTarget  `json:",omitempty"` // the target a scene began on, 0 for the screen
+	Texture hge.HTexture `json:",omitempty"` // the texture of a triple, quad or batch
+	Blend   int          `json:",omitempty"`
+	Color   hge.Dword    `json:",omitempty"` // the color the scene was cleared to
+	Prim    int          `json:",omitempty"` // the primitive type of a batch
+
+	// The vertices of a line, triple, quad or batch. A line has two, with
+	// its color and z.
+	V []Vertex `json:",omitempty"`
+
+	// Clipping is x, y, w, h and a transform x, y, dx, dy, rot, hscale,
+	// vscale, the same as the calls that set them.
+	Args []float64 `json:",omitempty"`
+}
+
+// Reports whether the command draws something.
+func (c *Command) IsDraw() bool {
+	switch c.Kind {
+	case CMD_CLEAR, CMD_LINE, CMD_TRIPLE, CMD_QUAD, CMD_BATCH:
+		return true
+	}
+
+	return false
+}
+
+// Describes the command on one line.
+func (c Command) String() string {
+	var b strings.Builder
+	b.WriteString(c.Kind.String())
+
+	switch c.Kind {
+	case CMD_BEGINSCENE:
+		fmt.Fprintf(&b, " target=%d", c.Target)
+	case CMD_CLEAR:
+		fmt.Fprintf(&b, " color=%08X", uint32(c.Color))
+	case CMD_TRIPLE, CMD_QUAD, CMD_BATCH:
+		fmt.Fprintf(&b, " tex=%d blend=%d", c.Texture, c.Blend)
+		if c.Kind == CMD_BATCH {
+			fmt.Fprintf(&b, " prim=%d", c.Prim)
+		}
+	}
+
+	for _, v := range c.V {
+		fmt.Fprintf(&b, " (%g,%g,%g %08X %g,%g)", v.X, v.Y, v.Z, uint32(v.Color), v.TX, v.TY)
+	}
+	for _, a := range c.Args {
+		fmt.Fprintf(&b, " %g", a)
+	}
+
+	return b.String()
+}
+
+// The commands drawn in a frame, from the first BeginScene to the EndScene of
+// the screen, including any drawing to targets in between. Command lists
+// encode with encoding/json.
+type CommandList []Command
+
+// Returns the number of commands that draw something.
+func (l CommandList) DrawCalls() int {
+	n := 0
+	for i := range l {
+		if l[i].IsDraw() {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Lists the commands one per line.
+func (l CommandList) String() string {
+	var b strings.Builder
+	for i := range l {
+		fmt.Fprintf(&b, "%d: %s\n", i, l[i])
+	}
+
+	return b.String()
+}
+
+// Returns where other differs from l, a line starting with - for a command
+// of l and + for one of other. No lines means they're the same.
+func (l CommandList) Diff(other CommandList) []string {
+	var diff []string
+
+	for i := 0; i < max(len(l), len(other)); i++ {
+		var a, b string
+		if i < len(l) {
+			a = l[i].String()
+		}
+		if i < len(other) {
+			b = other[i].String()
+		}
+		if a == b {
+			continue
+		}
+
+		if i < len(l) {
+			diff = append(diff, fmt.Sprintf("-%d: %s", i, a))
+		}
+		if i < len(other) {
+			diff = append(diff, fmt.Sprintf("+%d: %s", i, b))
+		}
+	}
+
+	return diff
+}
+
+// Maps the handles in a recording to the ones the backend replaying it
+// has. Handles that aren't in the maps are used as they are.
+type Handles struct {
+	Textures map[hge.HTexture]hge.HTexture
+	Targets  map[hge.HTarget]hge.HTarget
+}
+
+func (h *Handles) texture(tex hge.HTexture) hge.HTexture {
+	if h != nil {
+		if t, ok := h.Textures[tex]; ok {
+			return t
+		}
+	}
+
+	return tex
+}
+
+func (h *Handles) target(target hge.HTarget) hge.HTarget {
+	if h != nil {
+		if t, ok := h.Targets[target]; ok {
+			return t
+		}
+	}
+
+	return target
+}
+
+// Sends the commands to a backend as they were recorded. h can be nil when
+// the handles are the same. Replaying goes straight to the backend, so the
+// gfx stacks and sprite batches aren't used or changed.
+func (l CommandList) Replay(b hge.GfxBackend, h *Handles) {
+	for i := range l {
+		c := &l[i]
+
+		switch c.Kind {
+		case CMD_BEGINSCENE:
+			b.BeginScene(h.target(c.Target))
+		case CMD_ENDSCENE:
+			b.EndScene()
+		case CMD_CLEAR:
+			b.Clear(c.Color)
+		case CMD_LINE:
+			if len(c.V) == 2 {
+				b.RenderLine(float64(c.V[0].X), float64(c.V[0].Y), float64(c.V[1].X), float64(c.V[1].Y), c.V[0].Color, float64(c.V[0].Z))
+			}
+		case CMD_TRIPLE:
+			if len(c.V) == 3 {
+				b.RenderTriple((*[3]hge.BackendVertex)(unsafe.Pointer(&c.V[0])), h.texture(c.Texture), c.Blend)
+			}
+		case CMD_QUAD:
+			if len(c.V) == 4 {
+				b.RenderQuad((*[4]hge.BackendVertex)(unsafe.Pointer(&c.V[0])), h.texture(c.Texture), c.Blend)
+			}
+		case CMD_BATCH:
+			replayBatch(b, c, h.texture(c.Texture))
+		case CMD_CLIPPING:
+			if len(c.Args) == 4 {
+				b.SetClipping(int(c.Args[0]), int(c.Args[1]), int(c.Args[2]), int(c.Args[3]))
+			}
+		case CMD_TRANSFORM:
+			if a := c.Args; len(a) == 7 {
+				b.SetTransform(a[0], a[1], a[2], a[3], a[4], a[5], a[6])
+			}
+		}
+	}
+}
+
+// Draws a recorded batch, in more than one if the backend's vertex buffer is
+// smaller than the one it was recorded with.
+func replayBatch(b hge.GfxBackend, c *Command, tex hge.HTexture) {
+	if c.Prim <= 0 {
+		return
+	}
+
+	for v := c.V; len(v) >= c.Prim; {
+		buf, max := b.StartBatch(c.Prim, tex, c.Blend)
+		if buf == nil || max <= 0 {
+			return
+		}
+
+		n := min(len(v)/c.Prim, max)
+		copy(unsafe.Slice((*Vertex)(unsafe.Pointer(buf)), n*c.Prim), v)
+		b.FinishBatch(n)
+		v = v[n*c.Prim:]
+	}
+}
+
+// Records the draw calls gfx makes while it passes them on to the backend.
+type recorder struct {
+	hge.GfxBackend
+
+	onFrame func(CommandList)
+	frame   CommandList
+	last    CommandList
+	target  hge.HTarget
+
+	// The batch started but not finished yet
+	batch  Command
+	batchV *hge.BackendVertex
+}
+
+var recording *recorder
+
+// The backend gfx draws with, which records while recording is on.
+func backend() hge.GfxBackend {
+	if recording != nil {
+		return recording
+	}
+
+	return gfxHGE.Backend()
+}
+
+// Starts recording every draw call gfx makes. When a frame ends, with
+// EndScene of the screen, its commands are passed to onFrame, which can be
+// nil, and kept for LastFrame. Recording doesn't change what's drawn.
+func StartRecording(onFrame func(CommandList)) {
+	flushBatch()
+
+	recording = &recorder{GfxBackend: gfxHGE.Backend(), onFrame: onFrame}
+}
+
+// Stops recording and returns what was recorded of a frame that hasn't
+// ended yet.
+func StopRecording() CommandList {
+	if recording == nil {
+		return nil
+	}
+	flushBatch()
+
+	l := recording.frame
+	recording = nil
+
+	return l
+}
+
+// Reports whether draw calls are being recorded.
+func IsRecording() bool {
+	return recording != nil
+}
+
+// Returns the commands of the last frame recorded, or nil before one has
+// ended.
+func LastFrame() CommandList {
+	if recording == nil {
+		return nil
+	}
+
+	return recording.last
+}
+
+func (r *recorder) add(c Command) {
+	r.frame = append(r.frame, c)
+}
+
+func (r *recorder) BeginScene(target hge.HTarget) bool {
+	if !r.GfxBackend.BeginScene(target) {
+		return false
+	}
+
+	r.target = target
+	r.add(Command{Kind: CMD_BEGINSCENE, Target: target})
+
+	return true
+}
+
+func (r *recorder) EndScene() {
+	r.GfxBackend.EndScene()
+	r.add(Command{Kind: CMD_ENDSCENE})
+
+	if r.target != 0 {
+		r.target = 0
+		return
+	}
+
+	r.last, r.frame = r.frame, nil
+	if r.onFrame != nil {
+		r.onFrame(r.last)
+	}
+}
+
+func (r *recorder) Clear(color hge.Dword) {
+	r.GfxBackend.Clear(color)
+	r.add(Command{Kind: CMD_CLEAR, Color: color})
+}
+
+func (r *recorder) RenderLine(x1, y1, x2, y2 float64, color hge.Dword, z float64) {
+	r.GfxBackend.RenderLine(x1, y1, x2, y2, color, z)
+	r.add(Command{Kind: CMD_LINE, V: []Vertex{
+		{X: float32(x1), Y: float32(y1), Z: float32(z), Color: color},
+		{X: float32(x2), Y: float32(y2), Z: float32(z), Color: color},
+	}})
+}
+
+func (r *recorder) RenderTriple(v *[3]hge.BackendVertex, tex hge.HTexture, blend int) {
+	r.GfxBackend.RenderTriple(v, tex, blend)
+	r.add(Command{Kind: CMD_TRIPLE, Texture: tex, Blend: blend, V: append([]Vertex(nil), (*[3]Vertex)(unsafe.Pointer(v))[:]...)})
+}
+
+func (r *recorder) RenderQuad(v *[4]hge.BackendVertex, tex hge.HTexture, blend int) {
+	r.GfxBackend.RenderQuad(v, tex, blend)
+	r.add(Command{Kind: CMD_QUAD, Texture: tex, Blend: blend, V: append([]Vertex(nil), (*[4]Vertex)(unsafe.Pointer(v))[:]...)})
+}
+
+func (r *recorder) StartBatch(primType int, tex hge.HTexture, blend int) (*hge.BackendVertex, int) {
+	v, max := r.GfxBackend.StartBatch(primType, tex, blend)
+	if v != nil {
+		r.batch = Command{Kind: CMD_BATCH, Texture: tex, Blend: blend, Prim: primType}
+		r.batchV = v
+	}
+
+	return v, max
+}
+
+func (r *recorder) FinishBatch(prim int) {
+	// The vertices are copied before the backend can reuse its buffer
+	if r.batchV != nil && prim > 0 {
+		r.batch.V = append([]Vertex(nil), unsafe.Slice((*Vertex)(unsafe.Pointer(r.batchV)), prim*r.batch.Prim)...)
+		r.add(r.batch)
+	}
+	r.batchV = nil
+
+	r.GfxBackend.FinishBatch(prim)
+}
+
+func (r *recorder) SetClipping(x, y, w, h int) {
+	r.GfxBackend.SetClipping(x, y, w, h)
+	r.add(Command{Kind: CMD_CLIPPING, Args: []float64{float64(x), float64(y), float64(w), float64(h)}})
+}
+
+func (r *recorder) SetTransform(x, y, dx, dy, rot, hscale, vscale float64) {
+	r.GfxBackend.SetTransform(x, y, dx, dy, rot, hscale, vscale)
+	r.add(Command{Kind: CMD_TRANSFORM, Args: []float64{x, y, dx, dy, rot, hscale, vscale}})
+}
@@ -0,0 +1,195 @@
+package gfx_test
+
+import (
+	"encoding/json"
+	"image"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/losinggeneration/hge"
+	"github.com/losinggeneration/hge/gfx"
+	"github.com/losinggeneration/hge/hgetest"
+)
+
+// Fills a locked 4x4 texture with a different color in each pixel.
+func fillTexture(p *hge.Dword) {
+	pix := unsafe.Slice(p, 16)
+	for i := range pix {
+		x, y := hge.Dword(i%4), hge.Dword(i/4)
+		pix[i] = 0xFF000000 | (x*60+40)<<16 | (y*60+40)<<8 | 0x80
+	}
+}
+
+// Records a frame drawing to a target and then to the screen with every
+// kind of draw call, and returns it with what was drawn.
+func recordFrame(t *testing.T) (gfx.CommandList, *image.RGBA) {
+	t.Helper()
+
+	var tex *gfx.Texture
+	var target *gfx.Target
+	var frame gfx.CommandList
+
+	img, err := hgetest.Render(hgetest.Scene{
+		Width: 32, Height: 32,
+		Background: 0xFF102030,
+		Setup: func() error {
+			tex = gfx.NewTexture(4, 4)
+			target = gfx.NewTarget(16, 16, false)
+			if tex == nil || target == nil {
+				t.Fatal("can't make the texture or target")
+			}
+			fillTexture(tex.Lock(false))
+			tex.Unlock()
+
+			gfx.StartRecording(func(l gfx.CommandList) { frame = l })
+			return nil
+		},
+		Frame: func(int) {
+			gfx.BeginScene(target)
+			gfx.Clear(0xFF00FF00)
+			square(4, 4, 8, 0xFFFF0000).Render()
+			gfx.EndScene()
+		},
+		Render: func(int) {
+			q := square(0, 0, 8, 0xFFFFFFFF)
+			q.Texture = tex
+			q.V[1].TX, q.V[2].TX, q.V[2].TY, q.V[3].TY = 1, 1, 1, 1
+			q.Render()
+
+			(&gfx.Triple{V: [3]gfx.Vertex{
+				{X: 10, Y: 0, Z: 0.5, Color: 0xFF0000FF},
+				{X: 20, Y: 0, Z: 0.5, Color: 0xFF00FF00},
+				{X: 10, Y: 10, Z: 0.5, Color: 0xFFFF0000},
+			}, Blend: gfx.BLEND_DEFAULT}).Render()
+
+			gfx.NewLine(0, 30, 31, 20, hge.Dword(0xFFFFFF00)).Render()
+
+			if v, ok := gfx.StartBatchSlice(gfx.PRIM_QUADS, nil, gfx.BLEND_DEFAULT); ok {
+				copy(v, square(22, 0, 4, 0xFF00FFFF).V[:])
+				copy(v[4:], square(27, 0, 4, 0xFFFF00FF).V[:])
+				gfx.FinishBatch(2)
+			}
+
+			// Only the top left of the square is inside the clipping
+			gfx.SetClipping(0, 12, 4, 4)
+			square(0, 12, 8, 0xFF808080).Render()
+			gfx.SetClipping()
+
+			gfx.SetTransform(0.0, 0.0, 16.0, 16.0, 0.5, 1.5, 1.0)
+			square(-2, -2, 4, 0xFFC0C0C0).Render()
+			gfx.SetTransform()
+
+			q = square(16, 16, 16, 0xFFFFFFFF)
+			q.Texture = target.Texture()
+			q.V[1].TX, q.V[2].TX, q.V[2].TY, q.V[3].TY = 1, 1, 1, 1
+			q.Render()
+		},
+	})
+	gfx.StopRecording()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if frame == nil {
+		t.Fatal("no frame was recorded")
+	}
+
+	return frame, img
+}
+
+func TestRecordReplay(t *testing.T) {
+	frame, img := recordFrame(t)
+
+	kinds := []gfx.CommandKind{
+		gfx.CMD_BEGINSCENE, gfx.CMD_CLEAR, gfx.CMD_QUAD, gfx.CMD_ENDSCENE,
+		gfx.CMD_BEGINSCENE, gfx.CMD_CLEAR, gfx.CMD_QUAD, gfx.CMD_TRIPLE, gfx.CMD_LINE, gfx.CMD_BATCH,
+		gfx.CMD_CLIPPING, gfx.CMD_QUAD, gfx.CMD_CLIPPING,
+		gfx.CMD_TRANSFORM, gfx.CMD_QUAD, gfx.CMD_TRANSFORM,
+		gfx.CMD_QUAD, gfx.CMD_ENDSCENE,
+	}
+	if len(frame) != len(kinds) {
+		t.Fatalf("recorded %d commands, want %d:\n%s", len(frame), len(kinds), frame)
+	}
+	for i, k := range kinds {
+		if frame[i].Kind != k {
+			t.Fatalf("command %d is %s, want %s:\n%s", i, frame[i].Kind, k, frame)
+		}
+	}
+	if n := frame.DrawCalls(); n != 10 {
+		t.Errorf("%d draw calls, want 10", n)
+	}
+
+	target, tex, targetTex := frame[0].Target, frame[6].Texture, frame[16].Texture
+	if target == 0 || frame[4].Target != 0 || tex == 0 || targetTex == 0 || tex == targetTex {
+		t.Fatalf("target %d, texture %d and target texture %d:\n%s", target, tex, targetTex, frame)
+	}
+	if b := frame[9]; b.Prim != gfx.PRIM_QUADS || len(b.V) != 8 {
+		t.Errorf("batch of %d vertices of prim %d, want 2 quads", len(b.V), b.Prim)
+	}
+
+	// The drawing is there to compare against
+	for _, p := range []struct {
+		x, y int
+		c    hge.Dword
+	}{
+		{2, 13, 0xFF808080}, {6, 13, 0xFF102030}, {2, 17, 0xFF102030},
+		{24, 24, 0xFFFF0000}, {17, 17, 0xFF00FF00}, {24, 2, 0xFF00FFFF},
+	} {
+		c := img.RGBAAt(p.x, p.y)
+		if got := hge.Dword(c.A)<<24 | hge.Dword(c.R)<<16 | hge.Dword(c.G)<<8 | hge.Dword(c.B); got != p.c {
+			t.Errorf("pixel %d,%d is %08X, want %08X", p.x, p.y, uint32(got), uint32(p.c))
+		}
+	}
+
+	// Saving and loading it changes nothing
+	data, err := json.Marshal(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"Kind":"batch"`) {
+		t.Errorf("kinds aren't saved by name: %s", data)
+	}
+	var loaded gfx.CommandList
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if diff := frame.Diff(loaded); len(diff) != 0 {
+		t.Errorf("the JSON round trip differs:\n%s", strings.Join(diff, "\n"))
+	}
+
+	changed := append(gfx.CommandList(nil), loaded...)
+	changed[1].Color = 0xFF000000
+	changed = changed[:len(changed)-1]
+	diff := frame.Diff(changed)
+	want := []string{"-1: " + frame[1].String(), "+1: " + changed[1].String(), "-17: " + frame[17].String()}
+	if strings.Join(diff, "\n") != strings.Join(want, "\n") {
+		t.Errorf("diff\n%s\nwant\n%s", strings.Join(diff, "\n"), strings.Join(want, "\n"))
+	}
+
+	// A backend of its own, with its handles mapped to the recorded ones
+	b := hge.NewHeadless()
+	b.SetStateInt(hge.SCREENWIDTH, 32)
+	b.SetStateInt(hge.SCREENHEIGHT, 32)
+	if !b.Initiate() {
+		t.Fatal(b.ErrorMessage())
+	}
+	defer b.Shutdown()
+
+	h := &gfx.Handles{
+		Textures: map[hge.HTexture]hge.HTexture{tex: b.TextureCreate(4, 4)},
+		Targets:  map[hge.HTarget]hge.HTarget{target: b.TargetCreate(16, 16, false)},
+	}
+	h.Textures[targetTex] = b.TargetTexture(h.Targets[target])
+	fillTexture(b.TextureLock(h.Textures[tex], false, 0, 0, 0, 0))
+	b.TextureUnlock(h.Textures[tex])
+
+	loaded.Replay(b, h)
+
+	replayed := b.ScreenImage()
+	for i := range img.Pix {
+		if img.Pix[i] != replayed.Pix[i] {
+			x, y := i/4%32, i/4/32
+			t.Fatalf("replayed pixel %d,%d is %v, want %v", x, y, replayed.RGBAAt(x, y), img.RGBAAt(x, y))
+		}
+	}
+}
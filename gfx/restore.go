@@ -0,0 +1,150 @@
+package gfx
+
+import (
+	"sync"
+	"weak"
+
+	"github.com/losinggeneration/hge"
+)
+
+// Targets and textures made from Go, so they can be put back when the
+// graphics device is restored. They're kept by handle with weak pointers, so
+// being tracked doesn't keep them from being freed. Finalizers free them off
+// the engine thread when it isn't running, so the maps are locked.
+var (
+	liveMu       sync.Mutex
+	liveTargets  = make(map[hge.HTarget]*targetRecord)
+	liveTextures = make(map[hge.HTexture]*textureRecord)
+)
+
+type targetRecord struct {
+	t       weak.Pointer[Target]
+	w, h    int
+	zbuffer bool
+	restore func(*Target)
+}
+
+type textureRecord struct {
+	t       weak.Pointer[Texture]
+	w, h    int
+	restore func(*Texture)
+}
+
+func init() {
+	hge.OnGfxRestore(Restore)
+}
+
+func trackTarget(t *Target, w, h int, zbuffer bool) {
+	liveMu.Lock()
+	defer liveMu.Unlock()
+
+	liveTargets[t.target] = &targetRecord{t: weak.Make(t), w: w, h: h, zbuffer: zbuffer}
+}
+
+func trackTexture(t *Texture, w, h int) {
+	liveMu.Lock()
+	defer liveMu.Unlock()
+
+	liveTextures[t.texture] = &textureRecord{t: weak.Make(t), w: w, h: h}
+}
+
+func untrackTarget(h hge.HTarget) {
+	liveMu.Lock()
+	defer liveMu.Unlock()
+
+	delete(liveTargets, h)
+}
+
+func untrackTexture(h hge.HTexture) {
+	liveMu.Lock()
+	defer liveMu.Unlock()
+
+	delete(liveTextures, h)
+}
+
+// Sets a function to draw the target again after the graphics device is
+// restored, as what was drawn to it is lost. The function begins and ends
+// the scene on the target itself.
+func (t *Target) OnRestore(f func(*Target)) {
+	liveMu.Lock()
+	defer liveMu.Unlock()
+
+	if r, ok := liveTargets[t.target]; ok {
+		r.restore = f
+	}
+}
+
+// Sets a function to fill the texture again if it had to be made again
+// after the graphics device was restored. Textures made with
+// NewTextureFromImage fill themselves from their image unless this is set.
+func (t *Texture) OnRestore(f func(*Texture)) {
+	liveMu.Lock()
+	defer liveMu.Unlock()
+
+	if r, ok := liveTextures[t.texture]; ok {
+		r.restore = f
+	}
+}
+
+// Makes targets and textures lost with the graphics device again and calls
+// their restore functions. Textures are done first, so targets can draw them.
+// It's called when the device is restored, before the GFXRESTOREFUNC.
+func Restore() {
+	b := gfxHGE.Backend()
+
+	liveMu.Lock()
+	textures := make(map[hge.HTexture]*textureRecord, len(liveTextures))
+	for h, r := range liveTextures {
+		textures[h] = r
+	}
+	targets := make(map[hge.HTarget]*targetRecord, len(liveTargets))
+	for h, r := range liveTargets {
+		targets[h] = r
+	}
+	liveMu.Unlock()
+
+	// The restore functions are called unlocked, as they may make more
+	for h, r := range textures {
+		t := r.t.Value()
+		if t == nil || b.TextureWidth(h, false) != 0 {
+			continue
+		}
+
+		t.texture = b.TextureCreate(r.w, r.h)
+		untrackTexture(h)
+		if t.texture == 0 {
+			hge.Logger(hge.LogGfx).Warn("Can't restore texture", "width", r.w, "height", r.h)
+			continue
+		}
+		liveMu.Lock()
+		liveTextures[t.texture] = r
+		liveMu.Unlock()
+
+		if r.restore != nil {
+			r.restore(t)
+		}
+	}
+
+	for h, r := range targets {
+		t := r.t.Value()
+		if t == nil {
+			continue
+		}
+
+		if b.TargetTexture(h) == 0 {
+			t.target = b.TargetCreate(r.w, r.h, r.zbuffer)
+			untrackTarget(h)
+			if t.target == 0 {
+				hge.Logger(hge.LogGfx).Warn("Can't restore target", "width", r.w, "height", r.h)
+				continue
+			}
+			liveMu.Lock()
+			liveTargets[t.target] = r
+			liveMu.Unlock()
+		}
+
+		if r.restore != nil {
+			r.restore(t)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package gfx
+
+import (
+	"image"
+	"unsafe"
+
+	"github.com/losinggeneration/hge"
+)
+
+// The render state stacks. The last entry of each is what's in effect, and
+// SetTransform and SetClipping replace it rather than pushing.
+var (
+	transforms = []transformState{{m: Identity()}}
+	clips      = []clipState{{}}
+	blends     []int
+)
+
+// The backend starts each scene without a transform or clipping, so pushes
+// left over from the last scene are dropped to match it.
+func resetStacks() {
+	transforms = append(transforms[:0], transformState{m: Identity()})
+	clips = append(clips[:0], clipState{})
+	blends = blends[:0]
+}
+
+type transformState struct {
+	m Matrix
+	// Set when the backend can't do the transform, because it shears, so
+	// vertices are transformed before they're drawn
+	software bool
+}
+
+type clipState struct {
+	r image.Rectangle
+	// Without clipping everything on the screen or target is drawn
+	set bool
+}
+
+// Composes m with the current transform, so m is applied to what's drawn
+// first, and makes that the transform until PopTransform.
+func PushTransform(m Matrix) {
+	transforms = append(transforms, transformState{m: Transform().Mul(m)})
+	applyTransform()
+}
+
+// Goes back to the transform before the last PushTransform.
+func PopTransform() {
+	if len(transforms) > 1 {
+		transforms = transforms[:len(transforms)-1]
+		applyTransform()
+	}
+}
+
+// Returns the transform in effect.
+func Transform() Matrix {
+	return transforms[len(transforms)-1].m
+}
+
+func applyTransform() {
+	flushBatch()
+
+	t := &transforms[len(transforms)-1]
+	t.software = false
+
+	if t.m.isIdentity() {
+		backend().SetTransform(0, 0, 0, 0, 0, 0, 0)
+	} else if dx, dy, rot, hscale, vscale, ok := t.m.decompose(); ok {
+		backend().SetTransform(0, 0, dx, dy, rot, hscale, vscale)
+	} else {
+		backend().SetTransform(0, 0, 0, 0, 0, 0, 0)
+		t.software = true
+	}
+}
+
+// Intersects the clipping rectangle with the current one, in screen
+// coordinates, until PopClipping.
+func PushClipping(x, y, w, h int) {
+	c := clipState{image.Rect(x, y, x+w, y+h), true}
+	if top := clips[len(clips)-1]; top.set {
+		c.r = c.r.Intersect(top.r)
+	}
+
+	clips = append(clips, c)
+	applyClipping()
+}
+
+// Goes back to the clipping before the last PushClipping.
+func PopClipping() {
+	if len(clips) > 1 {
+		clips = clips[:len(clips)-1]
+		applyClipping()
+	}
+}
+
+// Returns the clipping rectangle in effect, or false if there's no clipping.
+func Clipping() (x, y, w, h int, ok bool) {
+	c := clips[len(clips)-1]
+	return c.r.Min.X, c.r.Min.Y, c.r.Dx(), c.r.Dy(), c.set
+}
+
+func applyClipping() {
+	flushBatch()
+
+	c := clips[len(clips)-1]
+	if !c.set || c.r.Empty() {
+		// An empty rectangle would turn clipping off, nothing is drawn
+		// instead
+		backend().SetClipping(0, 0, 0, 0)
+		return
+	}
+
+	backend().SetClipping(c.r.Min.X, c.r.Min.Y, c.r.Dx(), c.r.Dy())
+}
+
+// Reports whether the clipping leaves nothing to draw to.
+func clippedAway() bool {
+	c := clips[len(clips)-1]
+	return c.set && c.r.Empty()
+}
+
+// Makes quads, triples and sprite batches draw with blend, instead of their
+// own blend mode, until PopBlend. A whole part of a scene can be drawn
+// additively this way.
+func PushBlend(blend int) {
+	flushBatch()
+	blends = append(blends, blend)
+}
+
+// Goes back to the blend mode before the last PushBlend.
+func PopBlend() {
+	if len(blends) > 0 {
+		flushBatch()
+		blends = blends[:len(blends)-1]
+	}
+}
+
+// Returns the blend mode pushed with PushBlend, or false if there isn't one.
+func Blend() (blend int, ok bool) {
+	if len(blends) == 0 {
+		return 0, false
+	}
+
+	return blends[len(blends)-1], true
+}
+
+func blendMode(blend int) int {
+	if b, ok := Blend(); ok {
+		return b
+	}
+
+	return blend
+}
+
+// Transforms vertices in place when the backend can't.
+func transformVertices(v []Vertex) {
+	t := transforms[len(transforms)-1]
+	if !t.software {
+		return
+	}
+
+	for i := range v {
+		x, y := t.m.Apply(float64(v[i].X), float64(v[i].Y))
+		v[i].X, v[i].Y = float32(x), float32(y)
+	}
+}
+
+// The vertex buffer of a batch started with StartBatch, so it can be
+// transformed when it's finished.
+var rawBatch struct {
+	v        *hge.BackendVertex
+	primType int
+}
+
+func finishRawBatch(prim int) {
+	if rawBatch.v != nil && prim > 0 {
+		transformVertices(unsafe.Slice((*Vertex)(unsafe.Pointer(rawBatch.v)), prim*rawBatch.primType))
+	}
+	rawBatch.v = nil
+}
@@ -0,0 +1,67 @@
+package gfx_test
+
+import (
+	"testing"
+
+	"github.com/losinggeneration/hge"
+	"github.com/losinggeneration/hge/gfx"
+	"github.com/losinggeneration/hge/hgetest"
+)
+
+func square(x, y, size float32, color hge.Dword) *gfx.Quad {
+	q := &gfx.Quad{Blend: gfx.BLEND_DEFAULT}
+	for i, p := range [4][2]float32{{x, y}, {x + size, y}, {x + size, y + size}, {x, y + size}} {
+		q.V[i] = gfx.Vertex{X: p[0], Y: p[1], Z: 0.5, Color: color}
+	}
+
+	return q
+}
+
+// Pushes left over at the end of a scene mustn't carry into the next one,
+// which the backend starts without a transform or clipping.
+func TestStacksResetEachScene(t *testing.T) {
+	var transform gfx.Matrix
+	var clipped, blended bool
+
+	img, err := hgetest.Render(hgetest.Scene{
+		Width: 16, Height: 16,
+		Frames: 2,
+		Render: func(frame int) {
+			if frame == 0 {
+				// A shear is done in software, and the empty clipping
+				// drops every draw
+				gfx.PushTransform(gfx.Matrix{A: 1, C: 0.5, D: 1, TX: 8})
+				gfx.PushClipping(4, 4, 0, 0)
+				gfx.PushBlend(gfx.BLEND_ALPHAADD)
+				return
+			}
+
+			transform = gfx.Transform()
+			_, _, _, _, clipped = gfx.Clipping()
+			_, blended = gfx.Blend()
+			square(0, 0, 4, 0xFFFF0000).Render()
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if transform != gfx.Identity() {
+		t.Errorf("transform = %v, want the identity", transform)
+	}
+	if clipped {
+		t.Error("clipping is still set")
+	}
+	if blended {
+		t.Error("blend is still pushed")
+	}
+
+	for _, p := range [][2]int{{0, 0}, {3, 3}} {
+		if c := img.RGBAAt(p[0], p[1]); c.R != 0xFF || c.G != 0 || c.B != 0 {
+			t.Errorf("pixel %v = %v, want red", p, c)
+		}
+	}
+	if c := img.RGBAAt(10, 2); c.R != 0 {
+		t.Errorf("pixel 10,2 = %v, want it left black", c)
+	}
+}
@@ -0,0 +1,416 @@
+package hge
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+func init() {
+	RegisterBackend("headless", func() Backend { return NewHeadless() }, false)
+}
+
+// The amount the headless clock advances each frame when FPS is unlimited or
+// vsync and no delta was set with SetDelta.
+const HeadlessDelta = 1.0 / 60.0
+
+// Headless is a pure Go backend with no window, no audio device and in-memory
+// textures. Its clock only advances when a frame is run, so frame and render
+// functions run deterministically. Get at it from an HGE instance with
+// h.Backend().(*hge.Headless).
+type Headless struct {
+	refs      int
+	initiated bool
+	errorMsg  string
+
+	bools   map[BoolState]bool
+	ints    map[IntState]int
+	strings map[StringState]string
+	funcs   map[FuncState]StateFunc
+	hwnds   map[HwndState]Hwnd
+
+	time, delta, fixedDelta float64
+	frames                  int
+	seed                    uint32
+
+	headlessInput
+	headlessGfx
+	headlessSound
+	headlessResource
+}
+
+// Creates a headless backend with the same default states HGE has.
+func NewHeadless() *Headless {
+	h := new(Headless)
+
+	h.bools = map[BoolState]bool{
+		WINDOWED:      false,
+		ZBUFFER:       false,
+		TEXTUREFILTER: true,
+		USESOUND:      true,
+		DONTSUSPEND:   false,
+		HIDEMOUSE:     true,
+		SHOWSPLASH:    true,
+	}
+	h.ints = map[IntState]int{
+		SCREENWIDTH:      800,
+		SCREENHEIGHT:     600,
+		SCREENBPP:        32,
+		SAMPLERATE:       44100,
+		FXVOLUME:         100,
+		MUSVOLUME:        100,
+		STREAMVOLUME:     100,
+		FPS:              0,
+		POWERSTATUS:      PWR_UNSUPPORTED,
+		ORIGSCREENWIDTH:  800,
+		ORIGSCREENHEIGHT: 600,
+	}
+	h.strings = map[StringState]string{
+		TITLE: "HGE",
+	}
+	h.funcs = make(map[FuncState]StateFunc)
+	h.hwnds = make(map[HwndState]Hwnd)
+
+	h.headlessInput.init()
+	h.headlessGfx.init()
+	h.headlessSound.init()
+	h.headlessResource.init()
+
+	return h
+}
+
+func (h *Headless) Create(ver int) {
+	h.refs++
+}
+
+func (h *Headless) Release() {
+	if h.refs > 0 {
+		h.refs--
+	}
+}
+
+func (h *Headless) Initiate() bool {
+	h.Log("HGE Started..")
+	h.Log("HGE version: " + strconv.FormatInt(VERSION>>8, 16) + "." + strconv.FormatInt(VERSION&0xFF, 16))
+	h.Log("Backend: headless")
+	h.Log("Application: " + h.strings[TITLE])
+
+	h.ints[ORIGSCREENWIDTH] = h.ints[SCREENWIDTH]
+	h.ints[ORIGSCREENHEIGHT] = h.ints[SCREENHEIGHT]
+
+	h.headlessGfx.initiate(h.ints[SCREENWIDTH], h.ints[SCREENHEIGHT], h.bools[ZBUFFER])
+
+	h.time, h.delta, h.frames = 0, 0, 0
+	h.initiated = true
+
+	h.Log("Init done.\n")
+
+	return true
+}
+
+func (h *Headless) Shutdown() {
+	h.Log("\nFinishing..")
+
+	h.ChannelStopAll()
+	h.ResourceRemoveAllPacks()
+	h.headlessGfx.shutdown()
+	h.initiated = false
+
+	h.Log("The End.")
+}
+
+func (h *Headless) Start() bool {
+	if !h.initiated {
+		h.postError("System_Start: System_Initiate wasn't called")
+		return false
+	}
+
+	if h.funcs[FRAMEFUNC] == nil {
+		h.postError("System_Start: No frame function defined")
+		return false
+	}
+
+	for h.Step() {
+	}
+
+	return true
+}
+
+// Runs a single frame: the clock advances, queued input and calls queued with
+// Do are processed and the frame and render functions are called. Returns false once the frame
+// function asks to stop, or if there's no frame function to run.
+func (h *Headless) Step() bool {
+	if !h.initiated || h.funcs[FRAMEFUNC] == nil {
+		return false
+	}
+
+	h.delta = h.frameDelta()
+	h.time += h.delta
+	h.frames++
+
+	h.headlessInput.update()
+	h.headlessSound.update(h.delta)
+
+	RunPending()
+	if h.funcs[FRAMEFUNC]() != 0 {
+		h.headlessInput.clear()
+		return false
+	}
+
+	if h.funcs[RENDERFUNC] != nil {
+		h.funcs[RENDERFUNC]()
+	}
+
+	h.headlessInput.clear()
+
+	return true
+}
+
+// Runs up to n frames and returns the number of frames that were run to
+// completion.
+func (h *Headless) Run(n int) int {
+	for i := 0; i < n; i++ {
+		if !h.Step() {
+			return i
+		}
+	}
+
+	return n
+}
+
+// Sets how far the clock advances each frame. Zero derives the delta from
+// the FPS state.
+func (h *Headless) SetDelta(dt float64) {
+	h.fixedDelta = dt
+}
+
+// Returns the number of frames run since Initiate.
+func (h *Headless) Frames() int {
+	return h.frames
+}
+
+// Simulates the application losing focus.
+func (h *Headless) FocusLost() {
+	h.callFunc(FOCUSLOSTFUNC)
+}
+
+// Simulates the application gaining focus.
+func (h *Headless) FocusGain() {
+	h.callFunc(FOCUSGAINFUNC)
+}
+
+// Simulates the device being lost and restored. Like a real device, what
+// was drawn to targets is lost.
+func (h *Headless) RestoreGfx() {
+	h.loseTargets()
+	runGfxRestoreHooks()
+	h.callFunc(GFXRESTOREFUNC)
+}
+
+// Simulates the user closing the window. Returns true if the exit function
+// allowed the application to close.
+func (h *Headless) Exit() bool {
+	if h.funcs[EXITFUNC] == nil {
+		return true
+	}
+
+	return h.funcs[EXITFUNC]() != 0
+}
+
+func (h *Headless) callFunc(state FuncState) int {
+	if h.funcs[state] == nil {
+		return 0
+	}
+
+	return h.funcs[state]()
+}
+
+func (h *Headless) frameDelta() float64 {
+	if h.fixedDelta > 0 {
+		return h.fixedDelta
+	}
+	if fps := h.ints[FPS]; fps > 0 {
+		return 1.0 / float64(fps)
+	}
+
+	return HeadlessDelta
+}
+
+func (h *Headless) postError(msg string) {
+	h.errorMsg = msg
+	h.Log(msg)
+}
+
+func (h *Headless) ErrorMessage() string {
+	return h.errorMsg
+}
+
+func (h *Headless) Log(str string) {
+	name := h.strings[LOGFILE]
+	if name == "" {
+		return
+	}
+
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	fmt.Fprintln(f, str)
+}
+
+func (h *Headless) Launch(url string) bool {
+	return false
+}
+
+func (h *Headless) SetStateBool(state BoolState, value bool) {
+	h.bools[state] = value
+
+	if state == TEXTUREFILTER {
+		h.filter = value
+		h.raster.Filter = value
+	}
+}
+
+func (h *Headless) SetStateFunc(state FuncState, value StateFunc) {
+	h.funcs[state] = value
+}
+
+func (h *Headless) SetStateHwnd(state HwndState, value Hwnd) {
+	if state == HWNDPARENT && !h.initiated {
+		h.hwnds[state] = value
+	}
+}
+
+func (h *Headless) SetStateInt(state IntState, value int) {
+	switch state {
+	case POWERSTATUS, ORIGSCREENWIDTH, ORIGSCREENHEIGHT:
+		return
+	}
+
+	h.ints[state] = value
+}
+
+func (h *Headless) SetStateString(state StringState, value string) {
+	h.strings[state] = value
+
+	// Like HGE, setting the log file starts it afresh
+	if state == LOGFILE && value != "" {
+		if f, err := os.Create(value); err == nil {
+			f.Close()
+		}
+	}
+}
+
+func (h *Headless) StateBool(state BoolState) bool {
+	return h.bools[state]
+}
+
+func (h *Headless) StateFunc(state FuncState) StateFunc {
+	return h.funcs[state]
+}
+
+func (h *Headless) StateHwnd(state HwndState) Hwnd {
+	return h.hwnds[state]
+}
+
+func (h *Headless) StateInt(state IntState) int {
+	return h.ints[state]
+}
+
+func (h *Headless) StateString(state StringState) string {
+	return h.strings[state]
+}
+
+func (h *Headless) IniSetInt(section, name string, value int) {
+	h.IniSetString(section, name, strconv.Itoa(value))
+}
+
+func (h *Headless) IniGetInt(section, name string, defVal int) int {
+	if v, ok := h.iniGet(section, name); ok {
+		if i, err := strconv.Atoi(v); err == nil {
+			return i
+		}
+	}
+
+	return defVal
+}
+
+func (h *Headless) IniSetFloat(section, name string, value float64) {
+	h.IniSetString(section, name, strconv.FormatFloat(value, 'f', 6, 32))
+}
+
+func (h *Headless) IniGetFloat(section, name string, defVal float64) float64 {
+	if v, ok := h.iniGet(section, name); ok {
+		if f, err := strconv.ParseFloat(v, 32); err == nil {
+			return f
+		}
+	}
+
+	return defVal
+}
+
+func (h *Headless) IniSetString(section, name, value string) {
+	filename := h.strings[INIFILE]
+	if filename == "" {
+		return
+	}
+
+	ini := readIniFile(filename)
+	ini.set(section, name, value)
+	ini.write(filename)
+}
+
+func (h *Headless) IniGetString(section, name, defVal string) string {
+	if v, ok := h.iniGet(section, name); ok {
+		return v
+	}
+
+	return defVal
+}
+
+func (h *Headless) iniGet(section, name string) (string, bool) {
+	filename := h.strings[INIFILE]
+	if filename == "" {
+		return "", false
+	}
+
+	return readIniFile(filename).get(section, name)
+}
+
+// Uses the same generator HGE does so sequences match for a given seed.
+func (h *Headless) RandomSeed(seed int) {
+	if seed == 0 {
+		h.seed = uint32(time.Duration(h.time * float64(time.Second)).Milliseconds())
+	} else {
+		h.seed = uint32(seed)
+	}
+}
+
+func (h *Headless) RandomInt(min, max int) int {
+	h.seed = 214013*h.seed + 2531011
+	return min + int((h.seed^h.seed>>15)%uint32(max-min+1))
+}
+
+func (h *Headless) RandomFloat(min, max float64) float64 {
+	h.seed = 214013*h.seed + 2531011
+	return min + float64(h.seed>>16)*(1.0/65535.0)*(max-min)
+}
+
+func (h *Headless) Time() float64 {
+	return h.time
+}
+
+func (h *Headless) Delta() float64 {
+	return h.delta
+}
+
+func (h *Headless) FPS() int {
+	if h.delta == 0 {
+		return 0
+	}
+
+	return int(1.0/h.delta + 0.5)
+}
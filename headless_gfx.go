@@ -0,0 +1,363 @@
+package hge
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/jpeg"
+	"image/png"
+	"os"
+)
+
+// The number of vertices a batch can hold, same as HGE's vertex buffer
+const headlessVertexBufferSize = 4000
+
+type headlessTexture struct {
+	img *image.RGBA
+	// The texture as ARGB Dwords while it's locked
+	lock     []Dword
+	readonly bool
+}
+
+type headlessTarget struct {
+	tex     HTexture
+	zbuffer []float32
+}
+
+type headlessBatch struct {
+	primType int
+	tex      HTexture
+	blend    int
+	open     bool
+}
+
+// Everything is drawn in memory with a Rasterizer, either to the screen's
+// framebuffer or to a target's texture.
+type headlessGfx struct {
+	textures map[HTexture]*headlessTexture
+	targets  map[HTarget]*headlessTarget
+	next     uintptr
+	target   HTarget
+	inScene  bool
+
+	screen  *image.RGBA
+	zbuffer []float32
+	raster  Rasterizer
+	filter  bool
+
+	batch     [headlessVertexBufferSize]BackendVertex
+	batchInfo headlessBatch
+	shots     int
+}
+
+func (g *headlessGfx) init() {
+	g.textures = make(map[HTexture]*headlessTexture)
+	g.targets = make(map[HTarget]*headlessTarget)
+	g.filter = true
+}
+
+func (g *headlessGfx) initiate(width, height int, zbuffer bool) {
+	g.screen = image.NewRGBA(image.Rect(0, 0, width, height))
+	g.zbuffer = nil
+	if zbuffer {
+		g.zbuffer = make([]float32, width*height)
+	}
+}
+
+func (g *headlessGfx) shutdown() {
+	g.textures = make(map[HTexture]*headlessTexture)
+	g.targets = make(map[HTarget]*headlessTarget)
+	g.target = 0
+	g.inScene = false
+	g.screen = nil
+	g.zbuffer = nil
+}
+
+func (g *headlessGfx) newTexture(width, height int) HTexture {
+	if width <= 0 || height <= 0 {
+		return 0
+	}
+
+	g.next++
+	tex := HTexture(g.next)
+	g.textures[tex] = &headlessTexture{img: image.NewRGBA(image.Rect(0, 0, width, height))}
+
+	return tex
+}
+
+func (g *headlessGfx) image(tex HTexture) *image.RGBA {
+	if t, ok := g.textures[tex]; ok {
+		return t.img
+	}
+
+	return nil
+}
+
+// Like HGE, the transform and clipping are reset for each scene.
+func (g *headlessGfx) BeginScene(target HTarget) bool {
+	if g.inScene || g.screen == nil {
+		return false
+	}
+
+	if target != 0 {
+		t, ok := g.targets[target]
+		if !ok {
+			return false
+		}
+
+		g.raster = Rasterizer{Dst: g.image(t.tex), ZBuffer: t.zbuffer, Filter: g.filter}
+	} else {
+		g.raster = Rasterizer{Dst: g.screen, ZBuffer: g.zbuffer, Filter: g.filter, Opaque: true}
+	}
+
+	g.target = target
+	g.inScene = true
+
+	return true
+}
+
+func (g *headlessGfx) EndScene() {
+	g.inScene = false
+	g.target = 0
+	g.batchInfo.open = false
+}
+
+func (g *headlessGfx) Clear(color Dword) {
+	if g.inScene {
+		g.raster.Clear(color)
+	}
+}
+
+func (g *headlessGfx) RenderLine(x1, y1, x2, y2 float64, color Dword, z float64) {
+	if g.inScene {
+		g.raster.RenderLine(x1, y1, x2, y2, color, z)
+	}
+}
+
+func (g *headlessGfx) RenderTriple(v *[3]BackendVertex, tex HTexture, blend int) {
+	if g.inScene {
+		g.raster.RenderTriple(v, g.image(tex), blend)
+	}
+}
+
+func (g *headlessGfx) RenderQuad(v *[4]BackendVertex, tex HTexture, blend int) {
+	if g.inScene {
+		g.raster.RenderQuad(v, g.image(tex), blend)
+	}
+}
+
+func (g *headlessGfx) StartBatch(primType int, tex HTexture, blend int) (*BackendVertex, int) {
+	if !g.inScene || primType <= 0 {
+		return nil, 0
+	}
+
+	g.batchInfo = headlessBatch{primType, tex, blend, true}
+
+	return &g.batch[0], headlessVertexBufferSize / primType
+}
+
+func (g *headlessGfx) FinishBatch(prim int) {
+	b := g.batchInfo
+	if !g.inScene || !b.open {
+		return
+	}
+
+	g.batchInfo.open = false
+	g.raster.RenderBatch(g.batch[:], b.primType, prim, g.image(b.tex), b.blend)
+}
+
+func (g *headlessGfx) SetClipping(x, y, w, h int) {
+	g.raster.SetClipping(x, y, w, h)
+}
+
+func (g *headlessGfx) SetTransform(x, y, dx, dy, rot, hscale, vscale float64) {
+	g.raster.SetTransform(x, y, dx, dy, rot, hscale, vscale)
+}
+
+func (g *headlessGfx) TargetCreate(width, height int, zbuffer bool) HTarget {
+	tex := g.newTexture(width, height)
+	if tex == 0 {
+		return 0
+	}
+
+	t := &headlessTarget{tex: tex}
+	if zbuffer {
+		t.zbuffer = make([]float32, width*height)
+	}
+
+	g.next++
+	target := HTarget(g.next)
+	g.targets[target] = t
+
+	return target
+}
+
+func (g *headlessGfx) TargetFree(target HTarget) {
+	if t, ok := g.targets[target]; ok {
+		delete(g.textures, t.tex)
+		delete(g.targets, target)
+	}
+}
+
+// Clears every target to transparent black.
+func (g *headlessGfx) loseTargets() {
+	for _, t := range g.targets {
+		clear(g.image(t.tex).Pix)
+	}
+}
+
+func (g *headlessGfx) TargetTexture(target HTarget) HTexture {
+	if t, ok := g.targets[target]; ok {
+		return t.tex
+	}
+
+	return 0
+}
+
+func (g *headlessGfx) ScreenImage() *image.RGBA {
+	if g.screen == nil {
+		return nil
+	}
+
+	return rasterImage(g.screen)
+}
+
+func (g *headlessGfx) TargetImage(target HTarget) *image.RGBA {
+	t, ok := g.targets[target]
+	if !ok {
+		return nil
+	}
+
+	return rasterImage(g.image(t.tex))
+}
+
+// Turns the raw channels the rasterizer draws into the premultiplied colors
+// an image.RGBA is meant to hold.
+func rasterImage(img *image.RGBA) *image.RGBA {
+	src := &image.NRGBA{Pix: img.Pix, Stride: img.Stride, Rect: img.Rect}
+	dst := image.NewRGBA(img.Bounds())
+	draw.Draw(dst, dst.Bounds(), src, img.Bounds().Min, draw.Src)
+
+	return dst
+}
+
+// Screenshots are written as PNG. Without a filename they're numbered
+// shot000.png, shot001.png and so on in the application's folder.
+func (h *Headless) Snapshot(filename string) {
+	img := h.ScreenImage()
+	if img == nil {
+		return
+	}
+
+	for filename == "" && h.shots < 1000 {
+		name := h.ResourceMakePath(fmt.Sprintf("shot%03d.png", h.shots))
+		h.shots++
+		if _, err := os.Stat(name); os.IsNotExist(err) {
+			filename = name
+		}
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		h.postError("Gfx_Snapshot: Can't create " + filename)
+		return
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, img); err != nil {
+		h.postError("Gfx_Snapshot: Can't write " + filename)
+	}
+}
+
+func (g *headlessGfx) TextureCreate(width, height int) HTexture {
+	return g.newTexture(width, height)
+}
+
+// The texture is decoded with Go's image package, so only the formats
+// registered with it (PNG and JPEG by default) can be loaded.
+func (h *Headless) TextureLoad(filename string, size Dword, mipmap bool) HTexture {
+	data := h.ResourceLoad(filename)
+	if data == nil {
+		return 0
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0
+	}
+
+	b := img.Bounds()
+	tex := h.newTexture(b.Dx(), b.Dy())
+	if tex == 0 {
+		return 0
+	}
+
+	// Texels are kept unpremultiplied, the same as a Direct3D texture
+	nrgba := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(nrgba, nrgba.Bounds(), img, b.Min, draw.Src)
+	copy(h.textures[tex].img.Pix, nrgba.Pix)
+
+	return tex
+}
+
+func (g *headlessGfx) TextureFree(tex HTexture) {
+	delete(g.textures, tex)
+}
+
+func (g *headlessGfx) TextureWidth(tex HTexture, original bool) int {
+	if t, ok := g.textures[tex]; ok {
+		return t.img.Rect.Dx()
+	}
+
+	return 0
+}
+
+func (g *headlessGfx) TextureHeight(tex HTexture, original bool) int {
+	if t, ok := g.textures[tex]; ok {
+		return t.img.Rect.Dy()
+	}
+
+	return 0
+}
+
+// Like HGE the returned pointer is to the top left of the locked area and
+// rows are the texture's width apart. The pixels are ARGB and are copied back
+// to the texture on unlock unless it was locked read only.
+func (g *headlessGfx) TextureLock(tex HTexture, readonly bool, left, top, width, height int) *Dword {
+	t, ok := g.textures[tex]
+	if !ok || t.lock != nil {
+		return nil
+	}
+
+	w, h := t.img.Rect.Dx(), t.img.Rect.Dy()
+	if left < 0 || top < 0 || left >= w || top >= h {
+		return nil
+	}
+
+	t.lock = make([]Dword, w*h)
+	t.readonly = readonly
+
+	for i := range t.lock {
+		p := t.img.Pix[i*4 : i*4+4]
+		t.lock[i] = Dword(p[3])<<24 | Dword(p[0])<<16 | Dword(p[1])<<8 | Dword(p[2])
+	}
+
+	return &t.lock[top*w+left]
+}
+
+func (g *headlessGfx) TextureUnlock(tex HTexture) {
+	t, ok := g.textures[tex]
+	if !ok || t.lock == nil {
+		return
+	}
+
+	if !t.readonly {
+		for i, c := range t.lock {
+			p := t.img.Pix[i*4 : i*4+4]
+			p[0], p[1], p[2], p[3] = uint8(c>>16), uint8(c>>8), uint8(c), uint8(c>>24)
+		}
+	}
+
+	t.lock = nil
+}
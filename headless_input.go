@@ -0,0 +1,231 @@
+package hge
+
+// Input event types, flags and the keys the headless backend needs to know
+// about. These mirror the constants in the input package.
+const (
+	inputKeyDown     = 1
+	inputKeyUp       = 2
+	inputMButtonDown = 3
+	inputMButtonUp   = 4
+	inputMouseMove   = 5
+	inputMouseWheel  = 6
+
+	inpShift  = 1
+	inpCtrl   = 2
+	inpAlt    = 4
+	inpRepeat = 64
+
+	keyShift = 0x10
+	keyCtrl  = 0x11
+	keyAlt   = 0x12
+)
+
+var keyNames = map[int]string{
+	0x01: "Left Mouse Button", 0x02: "Right Mouse Button", 0x04: "Middle Mouse Button",
+	0x08: "Backspace", 0x09: "Tab", 0x0D: "Enter", 0x10: "Shift", 0x11: "Ctrl", 0x12: "Alt",
+	0x13: "Pause", 0x14: "Caps Lock", 0x1B: "Escape", 0x20: "Space",
+	0x21: "Page Up", 0x22: "Page Down", 0x23: "End", 0x24: "Home",
+	0x25: "Left Arrow", 0x26: "Up Arrow", 0x27: "Right Arrow", 0x28: "Down Arrow",
+	0x2D: "Insert", 0x2E: "Delete",
+	0x5B: "Left Win", 0x5C: "Right Win", 0x5D: "Application",
+	0x60: "NumPad 0", 0x61: "NumPad 1", 0x62: "NumPad 2", 0x63: "NumPad 3", 0x64: "NumPad 4",
+	0x65: "NumPad 5", 0x66: "NumPad 6", 0x67: "NumPad 7", 0x68: "NumPad 8", 0x69: "NumPad 9",
+	0x6A: "Multiply", 0x6B: "Add", 0x6D: "Subtract", 0x6E: "Decimal", 0x6F: "Divide",
+	0x70: "F1", 0x71: "F2", 0x72: "F3", 0x73: "F4", 0x74: "F5", 0x75: "F6",
+	0x76: "F7", 0x77: "F8", 0x78: "F9", 0x79: "F10", 0x7A: "F11", 0x7B: "F12",
+	0x90: "Num Lock", 0x91: "Scroll Lock",
+	0xBA: "Semicolon", 0xBB: "Equals", 0xBC: "Comma", 0xBD: "Minus", 0xBE: "Period",
+	0xBF: "Slash", 0xC0: "Grave", 0xDB: "Left bracket", 0xDC: "Backslash",
+	0xDD: "Right bracket", 0xDE: "Apostrophe",
+}
+
+type headlessInput struct {
+	keys           [256]bool
+	keyz           [256]int
+	mouseX, mouseY float64
+	wheel          int
+	mouseOver      bool
+	key, chr       int
+	pending, queue []BackendInputEvent
+}
+
+func (in *headlessInput) init() {
+	in.mouseOver = true
+}
+
+// Queues an input event to be processed at the start of the next frame.
+// Key and mouse button events without a position get the current mouse
+// position, and modifier flags are filled in from the keys being held.
+func (in *headlessInput) PushEvent(e BackendInputEvent) {
+	in.pending = append(in.pending, e)
+}
+
+// Queues a key or mouse button press for the next frame.
+func (in *headlessInput) PressKey(key int) {
+	switch key {
+	case 0x01, 0x02, 0x04:
+		in.PushEvent(BackendInputEvent{Type: inputMButtonDown, Key: key})
+	default:
+		in.PushEvent(BackendInputEvent{Type: inputKeyDown, Key: key})
+	}
+}
+
+// Queues a key or mouse button release for the next frame.
+func (in *headlessInput) ReleaseKey(key int) {
+	switch key {
+	case 0x01, 0x02, 0x04:
+		in.PushEvent(BackendInputEvent{Type: inputMButtonUp, Key: key})
+	default:
+		in.PushEvent(BackendInputEvent{Type: inputKeyUp, Key: key})
+	}
+}
+
+// Queues a character being typed for the next frame.
+func (in *headlessInput) TypeChar(key, chr int) {
+	in.PushEvent(BackendInputEvent{Type: inputKeyDown, Key: key, Chr: chr})
+	in.PushEvent(BackendInputEvent{Type: inputKeyUp, Key: key, Chr: chr})
+}
+
+// Queues a mouse move for the next frame.
+func (in *headlessInput) MoveMouse(x, y float64) {
+	in.PushEvent(BackendInputEvent{Type: inputMouseMove, X: float32(x), Y: float32(y)})
+}
+
+// Queues a mouse wheel movement for the next frame.
+func (in *headlessInput) ScrollWheel(notches int) {
+	in.PushEvent(BackendInputEvent{Type: inputMouseWheel, Wheel: notches})
+}
+
+// Sets whether the mouse is over the (imaginary) window.
+func (in *headlessInput) SetMouseOver(over bool) {
+	in.mouseOver = over
+}
+
+func (in *headlessInput) update() {
+	for _, e := range in.pending {
+		if e.Flags == 0 {
+			if in.keys[keyShift] {
+				e.Flags |= inpShift
+			}
+			if in.keys[keyCtrl] {
+				e.Flags |= inpCtrl
+			}
+			if in.keys[keyAlt] {
+				e.Flags |= inpAlt
+			}
+		}
+
+		switch e.Type {
+		case inputKeyDown, inputMButtonDown:
+			e.X, e.Y = float32(in.mouseX), float32(in.mouseY)
+			if e.Key > 0 && e.Key < len(in.keys) {
+				if in.keys[e.Key] && e.Type == inputKeyDown {
+					e.Flags |= inpRepeat
+				}
+				in.keys[e.Key] = true
+				in.keyz[e.Key] |= 1
+			}
+			in.key = e.Key
+			if e.Chr != 0 {
+				in.chr = e.Chr
+			}
+
+		case inputKeyUp, inputMButtonUp:
+			e.X, e.Y = float32(in.mouseX), float32(in.mouseY)
+			if e.Key > 0 && e.Key < len(in.keys) {
+				in.keys[e.Key] = false
+				in.keyz[e.Key] |= 2
+			}
+
+		case inputMouseMove:
+			in.mouseX, in.mouseY = float64(e.X), float64(e.Y)
+
+		case inputMouseWheel:
+			e.X, e.Y = float32(in.mouseX), float32(in.mouseY)
+			in.wheel += e.Wheel
+		}
+
+		in.queue = append(in.queue, e)
+	}
+
+	in.pending = in.pending[:0]
+}
+
+func (in *headlessInput) clear() {
+	in.queue = in.queue[:0]
+	in.keyz = [256]int{}
+	in.wheel = 0
+	in.key, in.chr = 0, 0
+}
+
+func (in *headlessInput) MousePos() (x, y float64) {
+	return in.mouseX, in.mouseY
+}
+
+func (in *headlessInput) SetMousePos(x, y float64) {
+	in.mouseX, in.mouseY = x, y
+}
+
+func (in *headlessInput) MouseWheel() int {
+	return in.wheel
+}
+
+func (in *headlessInput) IsMouseOver() bool {
+	return in.mouseOver
+}
+
+func (in *headlessInput) KeyDown(key int) bool {
+	if key <= 0 || key >= len(in.keyz) {
+		return false
+	}
+
+	return in.keyz[key]&1 != 0
+}
+
+func (in *headlessInput) KeyUp(key int) bool {
+	if key <= 0 || key >= len(in.keyz) {
+		return false
+	}
+
+	return in.keyz[key]&2 != 0
+}
+
+func (in *headlessInput) KeyState(key int) bool {
+	if key <= 0 || key >= len(in.keys) {
+		return false
+	}
+
+	return in.keys[key]
+}
+
+func (in *headlessInput) KeyName(key int) string {
+	switch {
+	case key >= '0' && key <= '9', key >= 'A' && key <= 'Z':
+		return string(rune(key))
+	}
+
+	if name, ok := keyNames[key]; ok {
+		return name
+	}
+
+	return "?"
+}
+
+func (in *headlessInput) Key() int {
+	return in.key
+}
+
+func (in *headlessInput) Char() int {
+	return in.chr
+}
+
+func (in *headlessInput) Event(e *BackendInputEvent) bool {
+	if len(in.queue) == 0 {
+		return false
+	}
+
+	*e = in.queue[0]
+	in.queue = in.queue[1:]
+
+	return true
+}
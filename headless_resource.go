@@ -0,0 +1,156 @@
+package hge
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type headlessPack struct {
+	filename string
+	*zip.ReadCloser
+}
+
+type headlessResource struct {
+	packs   []headlessPack
+	found   []string
+	foundAt int
+}
+
+func (r *headlessResource) init() {
+}
+
+// Resources are looked for in the attached packs first and then on disk,
+// relative to the working directory.
+func (r *headlessResource) ResourceLoad(filename string) []byte {
+	name := strings.ToLower(filepath.ToSlash(filename))
+
+	for _, pack := range r.packs {
+		for _, f := range pack.File {
+			if strings.ToLower(f.Name) != name {
+				continue
+			}
+
+			rc, err := f.Open()
+			if err != nil {
+				return nil
+			}
+			defer rc.Close()
+
+			data, err := io.ReadAll(rc)
+			if err != nil {
+				return nil
+			}
+
+			return data
+		}
+	}
+
+	data, err := os.ReadFile(r.ResourceMakePath(filename))
+	if err != nil {
+		return nil
+	}
+
+	return data
+}
+
+func (r *headlessResource) ResourceFree(data []byte) {
+}
+
+// Packs are zip files. Password protected packs aren't supported.
+func (r *headlessResource) ResourceAttachPack(filename, password string) bool {
+	if password != "" {
+		return false
+	}
+
+	path := r.ResourceMakePath(filename)
+	for _, pack := range r.packs {
+		if pack.filename == path {
+			return true
+		}
+	}
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return false
+	}
+
+	r.packs = append(r.packs, headlessPack{path, zr})
+
+	return true
+}
+
+func (r *headlessResource) ResourceRemovePack(filename string) {
+	path := r.ResourceMakePath(filename)
+
+	for i, pack := range r.packs {
+		if pack.filename == path {
+			pack.Close()
+			r.packs = append(r.packs[:i], r.packs[i+1:]...)
+			return
+		}
+	}
+}
+
+func (r *headlessResource) ResourceRemoveAllPacks() {
+	for _, pack := range r.packs {
+		pack.Close()
+	}
+
+	r.packs = nil
+}
+
+func (r *headlessResource) ResourceMakePath(filename string) string {
+	if filepath.IsAbs(filename) {
+		return filename
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		wd = "."
+	}
+
+	if filename == "" {
+		return wd + string(filepath.Separator)
+	}
+
+	return filepath.Join(wd, filename)
+}
+
+func (r *headlessResource) ResourceEnumFiles(wildcard string) string {
+	return r.enum(wildcard, false)
+}
+
+func (r *headlessResource) ResourceEnumFolders(wildcard string) string {
+	return r.enum(wildcard, true)
+}
+
+func (r *headlessResource) enum(wildcard string, folders bool) string {
+	if wildcard != "" {
+		r.found, r.foundAt = nil, 0
+
+		if wildcard == "*" || wildcard == "*.*" {
+			wildcard = "*"
+		}
+
+		matches, _ := filepath.Glob(r.ResourceMakePath(wildcard))
+		for _, m := range matches {
+			fi, err := os.Stat(m)
+			if err != nil || fi.IsDir() != folders {
+				continue
+			}
+
+			r.found = append(r.found, filepath.Base(m))
+		}
+	}
+
+	if r.foundAt >= len(r.found) {
+		return ""
+	}
+
+	r.foundAt++
+
+	return r.found[r.foundAt-1]
+}
@@ -0,0 +1,342 @@
+package hge
+
+type headlessChannel struct {
+	volume, pan     int
+	pitch           float64
+	loop            bool
+	playing, paused bool
+	pos             float64
+	order, row      int
+	slideTime       float64
+	slideVolume     int
+	slidePan        int
+	slidePitch      float64
+	fromVolume      int
+	fromPan         int
+	fromPitch       float64
+	slideAt         float64
+	effect          HEffect
+	music           HMusic
+	stream          HStream
+}
+
+type headlessMusic struct {
+	ampl       int
+	order, row int
+	instrVol   map[int]int
+	channelVol map[int]int
+}
+
+// No audio device is opened. Sounds only need to exist to load, channels
+// keep their state and positions advance with the headless clock.
+type headlessSound struct {
+	effects  map[HEffect]bool
+	musics   map[HMusic]*headlessMusic
+	streams  map[HStream]bool
+	channels map[HChannel]*headlessChannel
+	next     uintptr
+}
+
+func (s *headlessSound) init() {
+	s.effects = make(map[HEffect]bool)
+	s.musics = make(map[HMusic]*headlessMusic)
+	s.streams = make(map[HStream]bool)
+	s.channels = make(map[HChannel]*headlessChannel)
+}
+
+func (s *headlessSound) handle() uintptr {
+	s.next++
+	return s.next
+}
+
+func (s *headlessSound) update(dt float64) {
+	for _, c := range s.channels {
+		if !c.playing || c.paused {
+			continue
+		}
+
+		c.pos += dt * c.pitch
+
+		if c.slideTime > 0 {
+			c.slideAt += dt
+			t := c.slideAt / c.slideTime
+			if t >= 1 {
+				t = 1
+				c.slideTime = 0
+			}
+
+			c.volume = c.fromVolume + int(float64(c.slideVolume-c.fromVolume)*t)
+			c.pan = c.fromPan + int(float64(c.slidePan-c.fromPan)*t)
+			c.pitch = c.fromPitch + (c.slidePitch-c.fromPitch)*t
+		}
+	}
+}
+
+func (s *headlessSound) play(c *headlessChannel) HChannel {
+	c.playing = true
+	if c.pitch == 0 {
+		c.pitch = 1
+	}
+
+	h := HChannel(s.handle())
+	s.channels[h] = c
+
+	return h
+}
+
+func (h *Headless) EffectLoad(filename string, size Dword) HEffect {
+	if !h.bools[USESOUND] || h.ResourceLoad(filename) == nil {
+		return 0
+	}
+
+	eff := HEffect(h.handle())
+	h.effects[eff] = true
+
+	return eff
+}
+
+func (s *headlessSound) EffectFree(eff HEffect) {
+	delete(s.effects, eff)
+}
+
+func (s *headlessSound) EffectPlay(eff HEffect) HChannel {
+	return s.EffectPlayEx(eff, 100, 0, 1.0, false)
+}
+
+func (s *headlessSound) EffectPlayEx(eff HEffect, volume, pan int, pitch float64, loop bool) HChannel {
+	if !s.effects[eff] {
+		return 0
+	}
+
+	return s.play(&headlessChannel{volume: volume, pan: pan, pitch: pitch, loop: loop, effect: eff})
+}
+
+func (h *Headless) MusicLoad(filename string, size Dword) HMusic {
+	if !h.bools[USESOUND] || h.ResourceLoad(filename) == nil {
+		return 0
+	}
+
+	mus := HMusic(h.handle())
+	h.musics[mus] = &headlessMusic{ampl: 50, instrVol: make(map[int]int), channelVol: make(map[int]int)}
+
+	return mus
+}
+
+func (s *headlessSound) MusicFree(mus HMusic) {
+	delete(s.musics, mus)
+}
+
+func (s *headlessSound) MusicPlay(mus HMusic, loop bool, volume, order, row int) HChannel {
+	m, ok := s.musics[mus]
+	if !ok {
+		return 0
+	}
+
+	if order != -1 {
+		m.order = order
+	}
+	if row != -1 {
+		m.row = row
+	}
+
+	return s.play(&headlessChannel{volume: volume, pitch: 1, loop: loop, music: mus})
+}
+
+func (s *headlessSound) MusicSetAmplification(mus HMusic, ampl int) {
+	if m, ok := s.musics[mus]; ok {
+		m.ampl = ampl
+	}
+}
+
+func (s *headlessSound) MusicAmplification(mus HMusic) int {
+	if m, ok := s.musics[mus]; ok {
+		return m.ampl
+	}
+
+	return -1
+}
+
+func (s *headlessSound) MusicLength(mus HMusic) int {
+	return 0
+}
+
+func (s *headlessSound) MusicSetPos(mus HMusic, order, row int) {
+	if m, ok := s.musics[mus]; ok {
+		m.order, m.row = order, row
+	}
+}
+
+func (s *headlessSound) MusicPos(mus HMusic) (order, row int, ok bool) {
+	m, ok := s.musics[mus]
+	if !ok {
+		return -1, -1, false
+	}
+
+	return m.order, m.row, true
+}
+
+func (s *headlessSound) MusicSetInstrVolume(mus HMusic, instr, volume int) {
+	if m, ok := s.musics[mus]; ok {
+		m.instrVol[instr] = volume
+	}
+}
+
+func (s *headlessSound) MusicInstrVolume(mus HMusic, instr int) int {
+	if m, ok := s.musics[mus]; ok {
+		if v, ok := m.instrVol[instr]; ok {
+			return v
+		}
+		return 100
+	}
+
+	return -1
+}
+
+func (s *headlessSound) MusicSetChannelVolume(mus HMusic, channel, volume int) {
+	if m, ok := s.musics[mus]; ok {
+		m.channelVol[channel] = volume
+	}
+}
+
+func (s *headlessSound) MusicChannelVolume(mus HMusic, channel int) int {
+	if m, ok := s.musics[mus]; ok {
+		if v, ok := m.channelVol[channel]; ok {
+			return v
+		}
+		return 100
+	}
+
+	return -1
+}
+
+func (h *Headless) StreamLoad(filename string, size Dword) HStream {
+	if !h.bools[USESOUND] || h.ResourceLoad(filename) == nil {
+		return 0
+	}
+
+	stream := HStream(h.handle())
+	h.streams[stream] = true
+
+	return stream
+}
+
+func (s *headlessSound) StreamFree(stream HStream) {
+	delete(s.streams, stream)
+}
+
+func (s *headlessSound) StreamPlay(stream HStream, loop bool, volume int) HChannel {
+	if !s.streams[stream] {
+		return 0
+	}
+
+	return s.play(&headlessChannel{volume: volume, pitch: 1, loop: loop, stream: stream})
+}
+
+func (s *headlessSound) ChannelSetPanning(chn HChannel, pan int) {
+	if c, ok := s.channels[chn]; ok {
+		c.pan = pan
+	}
+}
+
+func (s *headlessSound) ChannelSetVolume(chn HChannel, volume int) {
+	if c, ok := s.channels[chn]; ok {
+		c.volume = volume
+	}
+}
+
+func (s *headlessSound) ChannelSetPitch(chn HChannel, pitch float64) {
+	if c, ok := s.channels[chn]; ok {
+		c.pitch = pitch
+	}
+}
+
+func (s *headlessSound) ChannelPause(chn HChannel) {
+	if c, ok := s.channels[chn]; ok {
+		c.paused = true
+	}
+}
+
+func (s *headlessSound) ChannelResume(chn HChannel) {
+	if c, ok := s.channels[chn]; ok {
+		c.paused = false
+	}
+}
+
+func (s *headlessSound) ChannelStop(chn HChannel) {
+	delete(s.channels, chn)
+}
+
+func (s *headlessSound) ChannelPauseAll() {
+	for _, c := range s.channels {
+		c.paused = true
+	}
+}
+
+func (s *headlessSound) ChannelResumeAll() {
+	for _, c := range s.channels {
+		c.paused = false
+	}
+}
+
+func (s *headlessSound) ChannelStopAll() {
+	for chn := range s.channels {
+		delete(s.channels, chn)
+	}
+}
+
+func (s *headlessSound) ChannelIsPlaying(chn HChannel) bool {
+	c, ok := s.channels[chn]
+	return ok && c.playing && !c.paused
+}
+
+func (s *headlessSound) ChannelLength(chn HChannel) float64 {
+	return -1
+}
+
+func (s *headlessSound) ChannelPos(chn HChannel) float64 {
+	if c, ok := s.channels[chn]; ok {
+		return c.pos
+	}
+
+	return -1
+}
+
+func (s *headlessSound) ChannelSetPos(chn HChannel, seconds float64) {
+	if c, ok := s.channels[chn]; ok {
+		c.pos = seconds
+	}
+}
+
+// Like HGE, a volume below 0, a pan below -100 or a pitch below 0 leave that
+// value alone.
+func (s *headlessSound) ChannelSlideTo(chn HChannel, time float64, volume, pan int, pitch float64) {
+	c, ok := s.channels[chn]
+	if !ok {
+		return
+	}
+
+	if volume < 0 {
+		volume = c.volume
+	}
+	if pan < -100 {
+		pan = c.pan
+	}
+	if pitch < 0 {
+		pitch = c.pitch
+	}
+
+	if time <= 0 {
+		c.volume, c.pan, c.pitch = volume, pan, pitch
+		c.slideTime = 0
+		return
+	}
+
+	c.fromVolume, c.fromPan, c.fromPitch = c.volume, c.pan, c.pitch
+	c.slideVolume, c.slidePan, c.slidePitch = volume, pan, pitch
+	c.slideTime, c.slideAt = time, 0
+}
+
+func (s *headlessSound) ChannelIsSliding(chn HChannel) bool {
+	c, ok := s.channels[chn]
+	return ok && c.slideTime > 0
+}
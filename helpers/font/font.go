@@ -1,7 +1,6 @@
 package font
 
 import (
-	"C"
 	"errors"
 	"fmt"
 	"strconv"
@@ -102,7 +101,7 @@ func tokenizeChar(value string) (chr byte, x, y, w, h, a, c float64) {
 	return
 }
 
-func New(filename string, arg ...interface{}) *Font {
+func New(filename string, arg ...interface{}) (*Font, error) {
 	mipmap := false
 
 	if len(arg) == 1 {
@@ -122,18 +121,15 @@ func New(filename string, arg ...interface{}) *Font {
 	f.blend = gfx.BLEND_COLORMUL | gfx.BLEND_ALPHABLEND | gfx.BLEND_NOZWRITE
 	f.color = 0xFFFFFFFF
 
-	desc := resource.LoadString(filename)
-
-	if desc == nil {
-		h.Log("Font %s seems to be empty.", filename)
-		return nil
+	desc, err := resource.LoadString(filename)
+	if err != nil {
+		return nil, err
 	}
 
 	lines := getLines(*desc)
 
 	if len(lines) == 0 || lines[0] != fntHEADERTAG {
-		h.Log("Font %s has incorrect format.", filename)
-		return nil
+		return nil, h.NewLoadError("font.New", filename, hge.ErrFormat)
 	}
 
 	// parse the font description
@@ -150,7 +146,9 @@ func New(filename string, arg ...interface{}) *Font {
 		}
 
 		if option == fntBITMAPTAG {
-			f.texture = gfx.LoadTexture(value, 0, mipmap)
+			if f.texture, err = gfx.LoadTexture(value, 0, mipmap); err != nil {
+				return nil, h.NewLoadError("font.New", filename, err)
+			}
 		} else if option == fntCHARTAG {
 			chr, x, y, w, h, a, c := tokenizeChar(value)
 
@@ -163,7 +161,7 @@ func New(filename string, arg ...interface{}) *Font {
 		}
 	}
 
-	return f
+	return f, nil
 }
 
 func (f *Font) Render(x, y float64, align int, str string) {
@@ -115,7 +115,7 @@ func (g GUI) GetCtrl(id int) *GUIObject {
 	e := elementById(id, g.ctrls)
 
 	if e == nil {
-		hge.New().Log("No such GUI ctrl id (%d)", id)
+		hge.Logger(hge.LogGUI).Warn("No such GUI ctrl", "id", id)
 		return nil
 	}
 
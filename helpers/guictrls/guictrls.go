@@ -61,6 +61,7 @@ type GUIButton struct {
 
 	trigger, pressed, oldState bool
 	up, down                   sprite.Sprite
+	upSlice, downSlice         *sprite.NineSlice
 }
 
 func NewGUIButton(id int, x, y, w, h float64, tex *gfx.Texture, tx, ty float64) *GUIButton {
@@ -77,6 +78,15 @@ func NewGUIButton(id int, x, y, w, h float64, tex *gfx.Texture, tx, ty float64)
 	b.down = sprite.New(tex, tx+w, ty, w, h)
 
 	b.GUIObject.Render = func() {
+		if b.upSlice != nil {
+			if b.pressed && b.downSlice != nil {
+				b.downSlice.RenderRect(&b.GUIObject.Rect)
+			} else {
+				b.upSlice.RenderRect(&b.GUIObject.Rect)
+			}
+			return
+		}
+
 		if b.pressed {
 			b.down.Render(b.GUIObject.Rect.X1, b.GUIObject.Rect.Y1)
 		} else {
@@ -103,6 +113,12 @@ func NewGUIButton(id int, x, y, w, h float64, tex *gfx.Texture, tx, ty float64)
 	return b
 }
 
+// Draws the button with nine-slices stretched over its rect instead of its
+// sprites. A nil down draws up while the button is pressed too.
+func (b *GUIButton) SetBackground(up, down *sprite.NineSlice) {
+	b.upSlice, b.downSlice = up, down
+}
+
 func (b *GUIButton) SetMode(trigger bool) {
 	b.trigger = trigger
 }
@@ -262,7 +278,8 @@ type guiListboxItem struct {
 type GUIListBox struct {
 	gui.GUIObject
 
-	highlight sprite.Sprite
+	highlight  sprite.Sprite
+	background *sprite.NineSlice
 	*font.Font
 	color, highlightColor        hge.Dword
 	items, selectedItem, topItem int
@@ -287,6 +304,10 @@ func NewGUIListBox(id int, x, y, w, h float64, fnt *font.Font, color, highlightC
 	l.List = list.New()
 
 	l.GUIObject.Render = func() {
+		if l.background != nil {
+			l.background.RenderRect(&l.GUIObject.Rect)
+		}
+
 		item := l.List.Front()
 
 		for i := 0; i < l.topItem; i++ {
@@ -369,6 +390,11 @@ func NewGUIListBox(id int, x, y, w, h float64, fnt *font.Font, color, highlightC
 	return l
 }
 
+// Draws a nine-slice stretched over the list box behind its items.
+func (l *GUIListBox) SetBackground(background *sprite.NineSlice) {
+	l.background = background
+}
+
 func (l *GUIListBox) Add(item string) int {
 	newItem := new(guiListboxItem)
 	newItem.text = item
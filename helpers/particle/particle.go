@@ -1,7 +1,6 @@
 package particle
 
 import (
-	"C"
 	"math"
 	"reflect"
 	"unsafe"
@@ -71,7 +70,7 @@ type ParticleSystem struct {
 	rand              *rand.Rand
 }
 
-func New(filename string, sprite sprite.Sprite, a ...interface{}) *ParticleSystem {
+func New(filename string, sprite sprite.Sprite, a ...interface{}) (*ParticleSystem, error) {
 	ps := new(ParticleSystem)
 	if len(a) == 1 {
 		if fps, ok := a[0].(float64); ok {
@@ -85,11 +84,9 @@ func New(filename string, sprite sprite.Sprite, a ...interface{}) *ParticleSyste
 	ps.rand = rand.New(int(timer.Time()))
 	ps.rand.Seed()
 
-	ptr := resource.LoadBytes(filename)
-
-	if ptr == nil {
-		ps.h.Log("Particle file (%s) seems to be empty.", filename)
-		return nil
+	ptr, err := resource.LoadBytes(filename)
+	if err != nil {
+		return nil, err
 	}
 
 	// skip the first four bytes
@@ -98,6 +95,11 @@ func New(filename string, sprite sprite.Sprite, a ...interface{}) *ParticleSyste
 	// Ok, First we reflect the ParticleSystemInfo struct
 	s := reflect.ValueOf(&ps.Info).Elem()
 
+	if uintptr(len(ptr)) < i+infoSize(s) {
+		hge.Logger(hge.LogParticle).Warn("Particle file is too short", "file", filename, "size", len(ptr))
+		return nil, ps.h.NewLoadError("particle.New", filename, hge.ErrFormat)
+	}
+
 	// Then we loop through each element, skipping sprite for obvious reasons
 	for j := 1; j < s.NumField(); j++ {
 		// Then we get the field of the struct
@@ -135,7 +137,25 @@ func New(filename string, sprite sprite.Sprite, a ...interface{}) *ParticleSyste
 
 	ps.particles = make([]particle, hgeMAX_PARTICLES+1)
 
-	return ps
+	return ps, nil
+}
+
+// The number of bytes a particle system info takes up in a file.
+func infoSize(s reflect.Value) uintptr {
+	size := uintptr(0)
+
+	for j := 1; j < s.NumField(); j++ {
+		switch s.Field(j).Type().String() {
+		case "float64", "int":
+			size += 4
+		case "bool":
+			size += 4 // with padding
+		case "color.ColorRGB":
+			size += 4 * 4
+		}
+	}
+
+	return size
 }
 
 func NewWithInfo(psi ParticleSystemInfo, a ...interface{}) *ParticleSystem {
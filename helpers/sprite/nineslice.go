@@ -0,0 +1,185 @@
+package sprite
+
+import (
+	"github.com/losinggeneration/hge"
+	"github.com/losinggeneration/hge/gfx"
+	"github.com/losinggeneration/hge/helpers/rect"
+)
+
+// How a NineSlice fills the space between its corners.
+const (
+	NINESLICE_STRETCH = 0
+	NINESLICE_TILE    = 1
+)
+
+// A NineSlice draws a texture region at any size by cutting it into nine
+// parts with four border insets. The corners are drawn as they are, the
+// edges and center are stretched or tiled to fill the rest.
+type NineSlice struct {
+	gfx.Quad
+	TX, TY, W, H             float64
+	TexW, TexH               float64
+	Left, Top, Right, Bottom float64
+	Mode                     int
+}
+
+func NewNineSlice(texture *gfx.Texture, texx, texy, w, h, left, top, right, bottom float64) NineSlice {
+	var n NineSlice
+
+	n.TX, n.TY = texx, texy
+	n.W, n.H = w, h
+	n.Left, n.Top, n.Right, n.Bottom = left, top, right, bottom
+
+	n.SetTexture(texture)
+
+	for i := 0; i < 4; i++ {
+		n.Quad.V[i].Z = 0.5
+		n.Quad.V[i].Color = 0xffffffff
+	}
+
+	n.Quad.Blend = gfx.BLEND_DEFAULT
+	n.Mode = NINESLICE_STRETCH
+
+	return n
+}
+
+// Draws the nine-slice w by h with its top left corner at x, y. When w or h
+// is smaller than the borders, they're shrunk to fit.
+func (n *NineSlice) Render(x, y, w, h float64) {
+	tile := n.Mode == NINESLICE_TILE
+	cols := sliceSpans(x, w, n.TX, n.W, n.Left, n.Right, tile)
+	rows := sliceSpans(y, h, n.TY, n.H, n.Top, n.Bottom, tile)
+
+	for _, r := range rows {
+		for _, c := range cols {
+			n.Quad.V[0].X, n.Quad.V[0].Y = float32(c.x1), float32(r.x1)
+			n.Quad.V[1].X, n.Quad.V[1].Y = float32(c.x2), float32(r.x1)
+			n.Quad.V[2].X, n.Quad.V[2].Y = float32(c.x2), float32(r.x2)
+			n.Quad.V[3].X, n.Quad.V[3].Y = float32(c.x1), float32(r.x2)
+
+			tx1, tx2 := float32(c.t1/n.TexW), float32(c.t2/n.TexW)
+			ty1, ty2 := float32(r.t1/n.TexH), float32(r.t2/n.TexH)
+			n.Quad.V[0].TX, n.Quad.V[0].TY = tx1, ty1
+			n.Quad.V[1].TX, n.Quad.V[1].TY = tx2, ty1
+			n.Quad.V[2].TX, n.Quad.V[2].TY = tx2, ty2
+			n.Quad.V[3].TX, n.Quad.V[3].TY = tx1, ty2
+
+			n.Quad.Render()
+		}
+	}
+}
+
+// Draws the nine-slice filling r, like the rect of a GUI control.
+func (n *NineSlice) RenderRect(r *rect.Rect) {
+	n.Render(r.X1, r.Y1, r.X2-r.X1, r.Y2-r.Y1)
+}
+
+// A piece of one row or column, from x1 to x2 on screen and t1 to t2 in the
+// texture.
+type sliceSpan struct {
+	x1, x2, t1, t2 float64
+}
+
+// Cuts the size on screen along one axis into the first border, the middle
+// and the last border.
+func sliceSpans(x, size, t, tsize, first, last float64, tile bool) []sliceSpan {
+	if size <= 0 {
+		return nil
+	}
+
+	// The borders on screen, shrunk if there isn't room for them
+	sf, sl := first, last
+	if first+last > size {
+		s := size / (first + last)
+		sf, sl = first*s, last*s
+	}
+
+	spans := make([]sliceSpan, 0, 3)
+	if sf > 0 {
+		spans = append(spans, sliceSpan{x, x + sf, t, t + first})
+	}
+
+	mid, tmid := size-sf-sl, tsize-first-last
+	if mid > 0 && tmid > 0 {
+		if tile {
+			// Whole copies of the middle, then what's left of one
+			for p := 0.0; p < mid; p += tmid {
+				l := min(tmid, mid-p)
+				spans = append(spans, sliceSpan{x + sf + p, x + sf + p + l, t + first, t + first + l})
+			}
+		} else {
+			spans = append(spans, sliceSpan{x + sf, x + sf + mid, t + first, t + first + tmid})
+		}
+	}
+
+	if sl > 0 {
+		spans = append(spans, sliceSpan{x + size - sl, x + size, t + tsize - last, t + tsize})
+	}
+
+	return spans
+}
+
+func (n *NineSlice) SetTexture(tex *gfx.Texture) {
+	n.Quad.Texture = tex
+
+	if tex != nil {
+		n.TexW = float64(tex.Width())
+		n.TexH = float64(tex.Height())
+	} else {
+		n.TexW, n.TexH = 1.0, 1.0
+	}
+}
+
+func (n *NineSlice) SetTextureRect(x, y, w, h float64) {
+	n.TX, n.TY, n.W, n.H = x, y, w, h
+}
+
+func (n *NineSlice) SetBorders(left, top, right, bottom float64) {
+	n.Left, n.Top, n.Right, n.Bottom = left, top, right, bottom
+}
+
+// Sets whether the edges and center are stretched or tiled, one of the
+// NINESLICE constants.
+func (n *NineSlice) SetMode(mode int) {
+	n.Mode = mode
+}
+
+func (n *NineSlice) SetColor(col hge.Dword) {
+	for i := 0; i < 4; i++ {
+		n.Quad.V[i].Color = col
+	}
+}
+
+func (n *NineSlice) SetZ(z float64) {
+	for i := 0; i < 4; i++ {
+		n.Quad.V[i].Z = float32(z)
+	}
+}
+
+func (n *NineSlice) SetBlendMode(blend int) {
+	n.Quad.Blend = blend
+}
+
+func (n *NineSlice) Texture() *gfx.Texture {
+	return n.Quad.Texture
+}
+
+func (n *NineSlice) TextureRect() (x, y, w, h float64) {
+	return n.TX, n.TY, n.W, n.H
+}
+
+func (n *NineSlice) Borders() (left, top, right, bottom float64) {
+	return n.Left, n.Top, n.Right, n.Bottom
+}
+
+func (n *NineSlice) Color() hge.Dword {
+	return n.Quad.V[0].Color
+}
+
+func (n *NineSlice) Z() float64 {
+	return float64(n.Quad.V[0].Z)
+}
+
+func (n *NineSlice) BlendMode() int {
+	return n.Quad.Blend
+}
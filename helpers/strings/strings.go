@@ -16,17 +16,20 @@ type StringTable struct {
 	stringsMap map[string]string
 }
 
-func New(filename string) *StringTable {
+func New(filename string) (*StringTable, error) {
 	st := new(StringTable)
 	h := hge.New()
 
 	st.stringsMap = make(map[string]string)
 
-	f := LoadString(filename)
+	f, err := LoadString(filename)
+	if err != nil {
+		return nil, err
+	}
 
-	if f == nil || !strings.HasPrefix(*f, strHEADERTAG) {
+	if !strings.HasPrefix(*f, strHEADERTAG) {
 		h.Log(strFORMATERROR, filename)
-		return nil
+		return nil, h.NewLoadError("strings.New", filename, hge.ErrFormat)
 	}
 
 	var (
@@ -39,7 +42,7 @@ func New(filename string) *StringTable {
 
 	if e != nil {
 		h.Log("Unable to seek past header tag")
-		return nil
+		return nil, h.NewLoadError("strings.New", filename, e)
 	}
 
 	for b, e := reader.ReadByte(); e == nil; b, e = reader.ReadByte() {
@@ -136,7 +139,7 @@ func New(filename string) *StringTable {
 		}
 	}
 
-	return st
+	return st, nil
 }
 
 func (st StringTable) String(name string) string {
@@ -0,0 +1,125 @@
+package hgetest_test
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/losinggeneration/hge/gfx"
+	dist "github.com/losinggeneration/hge/helpers/distortionmesh"
+	"github.com/losinggeneration/hge/helpers/font"
+	"github.com/losinggeneration/hge/helpers/particle"
+	"github.com/losinggeneration/hge/helpers/sprite"
+	"github.com/losinggeneration/hge/hgetest"
+)
+
+// The helpers load their files relative to the working directory, the same
+// as the tutorials, so the tests run from the data directory.
+func TestMain(m *testing.M) {
+	dir, err := filepath.Abs(hgetest.Dir)
+	if err == nil {
+		err = os.Chdir(filepath.Join("..", "data"))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	hgetest.Dir = dir
+
+	os.Exit(m.Run())
+}
+
+func TestSpriteRenderEx(t *testing.T) {
+	var spr sprite.Sprite
+	hgetest.Check(t, "sprite_renderex", hgetest.Scene{
+		Width: 96, Height: 64,
+		Background: 0xFF202020,
+		Setup: func() error {
+			tex, err := gfx.LoadTexture("particles.png")
+			if err != nil {
+				return err
+			}
+			spr = sprite.New(tex, 96, 64, 32, 32)
+			spr.SetColor(0xFFFFA000)
+			spr.SetHotSpot(16, 16)
+			return nil
+		},
+		Render: func(frame int) {
+			spr.RenderEx(24, 32, 0.5, 1.5)
+			spr.RenderEx(72, 32, -1, 0.75, 1.25)
+		},
+	})
+}
+
+func TestFontRenderAlign(t *testing.T) {
+	var fnt *font.Font
+	hgetest.Check(t, "font_align", hgetest.Scene{
+		Width: 256, Height: 96,
+		Setup: func() (err error) {
+			fnt, err = font.New("font1.fnt")
+			return err
+		},
+		Render: func(frame int) {
+			fnt.Render(128, 0, font.TEXT_LEFT, "Left")
+			fnt.Render(128, 32, font.TEXT_CENTER, "Center")
+			fnt.Render(128, 64, font.TEXT_RIGHT, "Right")
+		},
+	})
+}
+
+func TestDistortionMeshRender(t *testing.T) {
+	var dis dist.DistortionMesh
+	hgetest.Check(t, "distortionmesh", hgetest.Scene{
+		Width: 128, Height: 128,
+		Setup: func() error {
+			tex, err := gfx.LoadTexture("texture.jpg")
+			if err != nil {
+				return err
+			}
+			dis = dist.New(8, 8)
+			dis.SetTexture(tex)
+			dis.SetTextureRect(0, 0, 512, 512)
+			dis.Clear(0xFF000000)
+			for i := 1; i < 7; i++ {
+				for j := 1; j < 7; j++ {
+					dis.SetDisplacement(j, i, math.Cos(float64(i+j))*4, math.Sin(float64(i*j))*4, dist.DISP_NODE)
+				}
+			}
+			return nil
+		},
+		Render: func(frame int) {
+			dis.Render(0, 0)
+		},
+	})
+}
+
+func TestParticleSystem(t *testing.T) {
+	var par *particle.ParticleSystem
+	hgetest.Check(t, "particle_trail", hgetest.Scene{
+		Width: 128, Height: 128,
+		Frames: 30,
+		Setup: func() error {
+			tex, err := gfx.LoadTexture("particles.png")
+			if err != nil {
+				return err
+			}
+			spt := sprite.New(tex, 32, 32, 32, 32)
+			spt.SetBlendMode(gfx.BLEND_COLORMUL | gfx.BLEND_ALPHAADD | gfx.BLEND_NOZWRITE)
+			spt.SetHotSpot(16, 16)
+			if par, err = particle.New("trail.psi", spt); err != nil {
+				return err
+			}
+			par.Fire()
+			return nil
+		},
+		Frame: func(frame int) {
+			par.MoveTo(32+float64(frame)*2, 64)
+			par.Update(1.0 / 60)
+		},
+		Render: func(frame int) {
+			par.Render()
+		},
+	})
+}
@@ -0,0 +1,256 @@
+// Package hgetest renders scenes on the headless backend and compares them
+// against golden PNG images, so the output of the helpers can be locked down
+// in tests.
+//
+// A test renders a scene and checks it against testdata/<name>.png:
+//
+//	func TestSpriteRenderEx(t *testing.T) {
+//		var spr sprite.Sprite
+//		hgetest.Check(t, "sprite_renderex", hgetest.Scene{
+//			Width: 64, Height: 64,
+//			Setup: func() error {
+//				tex, err := gfx.LoadTexture("particles.png")
+//				if err != nil {
+//					return err
+//				}
+//				spr = sprite.New(tex, 96, 64, 32, 32)
+//				return nil
+//			},
+//			Render: func(frame int) {
+//				spr.RenderEx(32, 32, 0.5, 1.5)
+//			},
+//		})
+//	}
+//
+// Running the tests with -update writes the rendered images as the new
+// goldens instead of comparing them.
+package hgetest
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/losinggeneration/hge"
+	"github.com/losinggeneration/hge/gfx"
+)
+
+var update = flag.Bool("update", false, "write rendered images as the new golden images")
+
+// The directory goldens and diff images are kept in, relative to the test.
+var Dir = "testdata"
+
+func init() {
+	// Nothing has touched the engine yet, so this can't fail
+	hge.UseBackend("headless")
+}
+
+// A scene to render.
+type Scene struct {
+	// The size of the screen, 800x600 when left zero
+	Width, Height int
+	// How many frames to run, 1 when left zero. The image is taken after
+	// the last one.
+	Frames int
+	// How long each frame is in seconds, hge.HeadlessDelta when left zero
+	Delta float64
+	// The color the screen is cleared to before each Render
+	Background hge.Dword
+	// How far each channel of a pixel may be from the golden image
+	Tolerance uint8
+
+	// Called once the engine is initiated, to load textures and set up
+	// whatever's rendered
+	Setup func() error
+	// Called every frame with the frame number, starting at 0, before Render
+	Frame func(frame int)
+	// Called every frame between gfx.BeginScene and gfx.EndScene
+	Render func(frame int)
+}
+
+// Renders the scene on the headless backend and returns what ended up on
+// the screen. The random number generator is seeded with 1 first, so
+// things like particle systems come out the same every run.
+func Render(s Scene) (*image.RGBA, error) {
+	h := hge.New()
+	defer h.Free()
+
+	headless, ok := h.Backend().(*hge.Headless)
+	if !ok {
+		return nil, errors.New("hgetest: the headless backend isn't in use, it's " + hge.BackendName())
+	}
+
+	width, height := s.Width, s.Height
+	if width == 0 || height == 0 {
+		width, height = 800, 600
+	}
+	frames := s.Frames
+	if frames <= 0 {
+		frames = 1
+	}
+
+	headless.SetDelta(s.Delta)
+
+	frame := 0
+	err := h.Initiate(
+		hge.WithScreenSize(width, height),
+		hge.WithWindowed(true),
+		hge.WithBool(hge.USESOUND, false),
+		hge.WithFrameFunc(func() int {
+			if s.Frame != nil {
+				s.Frame(frame)
+			}
+			return 0
+		}),
+		hge.WithRenderFunc(func() int {
+			gfx.BeginScene()
+			gfx.Clear(s.Background)
+			if s.Render != nil {
+				s.Render(frame)
+			}
+			gfx.EndScene()
+			frame++
+			return 0
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer h.Shutdown()
+
+	headless.RandomSeed(1)
+
+	if s.Setup != nil {
+		if err := s.Setup(); err != nil {
+			return nil, err
+		}
+	}
+
+	headless.Run(frames)
+
+	return gfx.ScreenImage(), nil
+}
+
+// Renders the scene and compares it against the golden image
+// <Dir>/<name>.png. When they differ the test fails, and what was rendered
+// and an image of the differences are written next to the golden as
+// <name>.got.png and <name>.diff.png.
+func Check(tb testing.TB, name string, s Scene) {
+	tb.Helper()
+
+	got, err := Render(s)
+	if err != nil {
+		tb.Fatalf("%s: %v", name, err)
+	}
+
+	golden := filepath.Join(Dir, name+".png")
+	if *update {
+		if err := writePNG(golden, got); err != nil {
+			tb.Fatalf("%s: %v", name, err)
+		}
+		return
+	}
+
+	want, err := readPNG(golden)
+	if err != nil {
+		tb.Fatalf("%s: %v (run with -update to create it)", name, err)
+	}
+
+	diff, n := Compare(got, want, s.Tolerance)
+	if n == 0 {
+		return
+	}
+
+	gotFile := filepath.Join(Dir, name+".got.png")
+	diffFile := filepath.Join(Dir, name+".diff.png")
+	if err := writePNG(gotFile, got); err != nil {
+		tb.Errorf("%s: %v", name, err)
+	}
+	if err := writePNG(diffFile, diff); err != nil {
+		tb.Errorf("%s: %v", name, err)
+	}
+
+	tb.Errorf("%s: %d pixels differ from %s, see %s and %s", name, n, golden, gotFile, diffFile)
+}
+
+// Compares two images pixel by pixel. A pixel differs when any of its
+// channels is more than tolerance away. Returns the number of pixels that
+// differ and an image with those in red over a faded copy of want. Images
+// of different sizes differ everywhere.
+func Compare(got, want image.Image, tolerance uint8) (diff *image.RGBA, n int) {
+	gb, wb := got.Bounds(), want.Bounds()
+	if gb.Size() != wb.Size() {
+		diff = image.NewRGBA(wb)
+		for i := range diff.Pix {
+			diff.Pix[i] = 0xFF
+		}
+		return diff, wb.Dx() * wb.Dy()
+	}
+
+	diff = image.NewRGBA(image.Rect(0, 0, wb.Dx(), wb.Dy()))
+	for y := 0; y < wb.Dy(); y++ {
+		for x := 0; x < wb.Dx(); x++ {
+			g := color.NRGBAModel.Convert(got.At(gb.Min.X+x, gb.Min.Y+y)).(color.NRGBA)
+			w := color.NRGBAModel.Convert(want.At(wb.Min.X+x, wb.Min.Y+y)).(color.NRGBA)
+
+			if within(g.R, w.R, tolerance) && within(g.G, w.G, tolerance) &&
+				within(g.B, w.B, tolerance) && within(g.A, w.A, tolerance) {
+				l := uint8((uint(w.R) + uint(w.G) + uint(w.B)) / 12)
+				diff.SetRGBA(x, y, color.RGBA{l, l, l, 0xFF})
+				continue
+			}
+
+			diff.SetRGBA(x, y, color.RGBA{0xFF, 0, 0, 0xFF})
+			n++
+		}
+	}
+
+	return diff, n
+}
+
+func within(a, b, tolerance uint8) bool {
+	if a > b {
+		return a-b <= tolerance
+	}
+
+	return b-a <= tolerance
+}
+
+func readPNG(filename string) (image.Image, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+
+	return img, nil
+}
+
+func writePNG(filename string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,653 @@
+//go:build !headless
+
+package hge
+
+/*
+#cgo pkg-config: hge-unix-c
+#include "hge_c.h"
+#include "callback.h"
+#include <stdio.h>
+#include <stdlib.h>
+void goHGE_System_Log(HGE_t *h, const char *str) {
+	HGE_System_Log(h, str);
+}
+*/
+import "C"
+
+import "unsafe"
+
+// The state constants in hge.go are hard-coded so headless builds don't need
+// hge_c.h. These stop compiling if one drifts from the header.
+var (
+	_ = [1]struct{}{}[VERSION-C.HGE_VERSION]
+	_ = [1]struct{}{}[WINDOWED-C.HGE_C_WINDOWED]
+	_ = [1]struct{}{}[ZBUFFER-C.HGE_C_ZBUFFER]
+	_ = [1]struct{}{}[TEXTUREFILTER-C.HGE_C_TEXTUREFILTER]
+	_ = [1]struct{}{}[USESOUND-C.HGE_C_USESOUND]
+	_ = [1]struct{}{}[DONTSUSPEND-C.HGE_C_DONTSUSPEND]
+	_ = [1]struct{}{}[HIDEMOUSE-C.HGE_C_HIDEMOUSE]
+	_ = [1]struct{}{}[SHOWSPLASH-C.HGE_C_SHOWSPLASH]
+	_ = [1]struct{}{}[BOOLSTATE_FORCE_DWORD-C.HGE_C_BOOLSTATE_FORCE_DWORD]
+	_ = [1]struct{}{}[FRAMEFUNC-C.HGE_C_FRAMEFUNC]
+	_ = [1]struct{}{}[RENDERFUNC-C.HGE_C_RENDERFUNC]
+	_ = [1]struct{}{}[FOCUSLOSTFUNC-C.HGE_C_FOCUSLOSTFUNC]
+	_ = [1]struct{}{}[FOCUSGAINFUNC-C.HGE_C_FOCUSGAINFUNC]
+	_ = [1]struct{}{}[GFXRESTOREFUNC-C.HGE_C_GFXRESTOREFUNC]
+	_ = [1]struct{}{}[EXITFUNC-C.HGE_C_EXITFUNC]
+	_ = [1]struct{}{}[FUNCSTATE_FORCE_DWORD-C.HGE_C_FUNCSTATE_FORCE_DWORD]
+	_ = [1]struct{}{}[HWND-C.HGE_C_HWND]
+	_ = [1]struct{}{}[HWNDPARENT-C.HGE_C_HWNDPARENT]
+	_ = [1]struct{}{}[HWNDSTATE_FORCE_DWORD-C.HGE_C_HWNDSTATE_FORCE_DWORD]
+	_ = [1]struct{}{}[SCREENWIDTH-C.HGE_C_SCREENWIDTH]
+	_ = [1]struct{}{}[SCREENHEIGHT-C.HGE_C_SCREENHEIGHT]
+	_ = [1]struct{}{}[SCREENBPP-C.HGE_C_SCREENBPP]
+	_ = [1]struct{}{}[SAMPLERATE-C.HGE_C_SAMPLERATE]
+	_ = [1]struct{}{}[FXVOLUME-C.HGE_C_FXVOLUME]
+	_ = [1]struct{}{}[MUSVOLUME-C.HGE_C_MUSVOLUME]
+	_ = [1]struct{}{}[STREAMVOLUME-C.HGE_C_STREAMVOLUME]
+	_ = [1]struct{}{}[FPS-C.HGE_C_FPS]
+	_ = [1]struct{}{}[POWERSTATUS-C.HGE_C_POWERSTATUS]
+	_ = [1]struct{}{}[ORIGSCREENWIDTH-C.HGE_C_ORIGSCREENWIDTH]
+	_ = [1]struct{}{}[ORIGSCREENHEIGHT-C.HGE_C_ORIGSCREENHEIGHT]
+	_ = [1]struct{}{}[INTSTATE_FORCE_DWORD-C.HGE_C_INTSTATE_FORCE_DWORD]
+	_ = [1]struct{}{}[ICON-C.HGE_C_ICON]
+	_ = [1]struct{}{}[TITLE-C.HGE_C_TITLE]
+	_ = [1]struct{}{}[INIFILE-C.HGE_C_INIFILE]
+	_ = [1]struct{}{}[LOGFILE-C.HGE_C_LOGFILE]
+	_ = [1]struct{}{}[STRINGSTATE_FORCE_DWORD-C.HGE_C_STRINGSTATE_FORCE_DWORD]
+	_ = [1]struct{}{}[PWR_AC-C.HGE_PWR_AC]
+	_ = [1]struct{}{}[PWR_UNSUPPORTED-C.HGE_PWR_UNSUPPORTED]
+)
+
+func init() {
+	RegisterBackend("hge-unix", func() Backend { return new(unixBackend) }, true)
+}
+
+func BoolToCInt(b bool) C.BOOL {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+type cTriple struct {
+	v     [3]BackendVertex
+	tex   C.HTEXTURE
+	blend C.int
+}
+
+type cQuad struct {
+	v     [4]BackendVertex
+	tex   C.HTEXTURE
+	blend C.int
+}
+
+// The backend that binds to the hge-unix C API
+type unixBackend struct {
+	h *C.HGE_t
+}
+
+func (b *unixBackend) Create(ver int) {
+	b.h = C.HGE_Create(C.int(ver))
+}
+
+func (b *unixBackend) Release() {
+	C.HGE_Release(b.h)
+}
+
+func (b *unixBackend) Initiate() bool {
+	// The restore hooks run even without a GFXRESTOREFUNC
+	C.setGfxRestoreFunc(b.h, C.HGE_FuncState_t(GFXRESTOREFUNC))
+	return C.HGE_System_Initiate(b.h) == 1
+}
+
+func (b *unixBackend) Shutdown() {
+	C.HGE_System_Shutdown(b.h)
+}
+
+func (b *unixBackend) Start() bool {
+	return C.HGE_System_Start(b.h) == 1
+}
+
+func (b *unixBackend) ErrorMessage() string {
+	return C.GoString(C.HGE_System_GetErrorMessage(b.h))
+}
+
+func (b *unixBackend) Log(str string) {
+	fstr := C.CString(str)
+	defer C.free(unsafe.Pointer(fstr))
+
+	C.goHGE_System_Log(b.h, fstr)
+}
+
+func (b *unixBackend) Launch(url string) bool {
+	urlstr := C.CString(url)
+	defer C.free(unsafe.Pointer(urlstr))
+
+	return C.HGE_System_Launch(b.h, urlstr) == 1
+}
+
+func (b *unixBackend) Snapshot(filename string) {
+	if filename == "" {
+		C.HGE_System_Snapshot(b.h, nil)
+		return
+	}
+
+	fname := C.CString(filename)
+	defer C.free(unsafe.Pointer(fname))
+
+	C.HGE_System_Snapshot(b.h, fname)
+}
+
+func (b *unixBackend) SetStateBool(state BoolState, value bool) {
+	C.HGE_System_SetStateBool(b.h, C.HGE_BoolState_t(state), BoolToCInt(value))
+}
+
+func (b *unixBackend) SetStateFunc(state FuncState, value StateFunc) {
+	funcCBs[state] = value
+	switch state {
+	case FRAMEFUNC:
+		C.setFrameFunc(b.h, C.HGE_FuncState_t(state))
+	case RENDERFUNC:
+		C.setRenderFunc(b.h, C.HGE_FuncState_t(state))
+	case FOCUSLOSTFUNC:
+		C.setFocusLostFunc(b.h, C.HGE_FuncState_t(state))
+	case FOCUSGAINFUNC:
+		C.setFocusGainFunc(b.h, C.HGE_FuncState_t(state))
+	case GFXRESTOREFUNC:
+		C.setGfxRestoreFunc(b.h, C.HGE_FuncState_t(state))
+	case EXITFUNC:
+		C.setExitFunc(b.h, C.HGE_FuncState_t(state))
+	}
+}
+
+func (b *unixBackend) SetStateHwnd(state HwndState, value Hwnd) {
+	C.HGE_System_SetStateHwnd(b.h, C.HGE_HwndState_t(state), C.HWND(unsafe.Pointer(value.hwnd)))
+}
+
+func (b *unixBackend) SetStateInt(state IntState, value int) {
+	C.HGE_System_SetStateInt(b.h, C.HGE_IntState_t(state), C.int(value))
+}
+
+func (b *unixBackend) SetStateString(state StringState, value string) {
+	val := C.CString(value)
+	defer C.free(unsafe.Pointer(val))
+
+	C.HGE_System_SetStateString(b.h, C.HGE_StringState_t(state), val)
+}
+
+func (b *unixBackend) StateBool(state BoolState) bool {
+	return C.HGE_System_GetStateBool(b.h, C.HGE_BoolState_t(state)) == 1
+}
+
+func (b *unixBackend) StateFunc(state FuncState) StateFunc {
+	// I don't know how to convert the HGE_Callback C function type to a Go
+	// function, so we just pass back the Go function
+	return funcCBs[state]
+}
+
+func (b *unixBackend) StateHwnd(state HwndState) Hwnd {
+	return Hwnd{uintptr(unsafe.Pointer(C.HGE_System_GetStateHwnd(b.h, C.HGE_HwndState_t(state))))}
+}
+
+func (b *unixBackend) StateInt(state IntState) int {
+	return int(C.HGE_System_GetStateInt(b.h, C.HGE_IntState_t(state)))
+}
+
+func (b *unixBackend) StateString(state StringState) string {
+	return C.GoString(C.HGE_System_GetStateString(b.h, C.HGE_StringState_t(state)))
+}
+
+func (b *unixBackend) ResourceLoad(filename string) []byte {
+	var s C.DWORD
+	fname := C.CString(filename)
+	defer C.free(unsafe.Pointer(fname))
+
+	p := C.HGE_Resource_Load(b.h, fname, &s)
+	if p == nil {
+		return nil
+	}
+
+	return unsafe.Slice((*byte)(p), int(s))
+}
+
+func (b *unixBackend) ResourceFree(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+
+	C.HGE_Resource_Free(b.h, unsafe.Pointer(&data[0]))
+}
+
+func (b *unixBackend) ResourceAttachPack(filename, password string) bool {
+	fname := C.CString(filename)
+	defer C.free(unsafe.Pointer(fname))
+
+	var pass *C.char
+	if password != "" {
+		pass = C.CString(password)
+		defer C.free(unsafe.Pointer(pass))
+	}
+
+	return C.HGE_Resource_AttachPack(b.h, fname, pass) == 1
+}
+
+func (b *unixBackend) ResourceRemovePack(filename string) {
+	fname := C.CString(filename)
+	defer C.free(unsafe.Pointer(fname))
+
+	C.HGE_Resource_RemovePack(b.h, fname)
+}
+
+func (b *unixBackend) ResourceRemoveAllPacks() {
+	C.HGE_Resource_RemoveAllPacks(b.h)
+}
+
+func (b *unixBackend) ResourceMakePath(filename string) string {
+	if filename == "" {
+		return C.GoString(C.HGE_Resource_MakePath(b.h, nil))
+	}
+
+	fname := C.CString(filename)
+	defer C.free(unsafe.Pointer(fname))
+
+	return C.GoString(C.HGE_Resource_MakePath(b.h, fname))
+}
+
+func (b *unixBackend) ResourceEnumFiles(wildcard string) string {
+	if wildcard == "" {
+		return C.GoString(C.HGE_Resource_EnumFiles(b.h, nil))
+	}
+
+	wcard := C.CString(wildcard)
+	defer C.free(unsafe.Pointer(wcard))
+
+	return C.GoString(C.HGE_Resource_EnumFiles(b.h, wcard))
+}
+
+func (b *unixBackend) ResourceEnumFolders(wildcard string) string {
+	if wildcard == "" {
+		return C.GoString(C.HGE_Resource_EnumFolders(b.h, nil))
+	}
+
+	wcard := C.CString(wildcard)
+	defer C.free(unsafe.Pointer(wcard))
+
+	return C.GoString(C.HGE_Resource_EnumFolders(b.h, wcard))
+}
+
+func (b *unixBackend) IniSetInt(section, name string, value int) {
+	s, n := C.CString(section), C.CString(name)
+	defer C.free(unsafe.Pointer(s))
+	defer C.free(unsafe.Pointer(n))
+
+	C.HGE_Ini_SetInt(b.h, s, n, C.int(value))
+}
+
+func (b *unixBackend) IniGetInt(section, name string, defVal int) int {
+	s, n := C.CString(section), C.CString(name)
+	defer C.free(unsafe.Pointer(s))
+	defer C.free(unsafe.Pointer(n))
+
+	return int(C.HGE_Ini_GetInt(b.h, s, n, C.int(defVal)))
+}
+
+func (b *unixBackend) IniSetFloat(section, name string, value float64) {
+	s, n := C.CString(section), C.CString(name)
+	defer C.free(unsafe.Pointer(s))
+	defer C.free(unsafe.Pointer(n))
+
+	C.HGE_Ini_SetFloat(b.h, s, n, C.float(value))
+}
+
+func (b *unixBackend) IniGetFloat(section, name string, defVal float64) float64 {
+	s, n := C.CString(section), C.CString(name)
+	defer C.free(unsafe.Pointer(s))
+	defer C.free(unsafe.Pointer(n))
+
+	return float64(C.HGE_Ini_GetFloat(b.h, s, n, C.float(defVal)))
+}
+
+func (b *unixBackend) IniSetString(section, name, value string) {
+	s, n, v := C.CString(section), C.CString(name), C.CString(value)
+	defer C.free(unsafe.Pointer(s))
+	defer C.free(unsafe.Pointer(n))
+	defer C.free(unsafe.Pointer(v))
+
+	C.HGE_Ini_SetString(b.h, s, n, v)
+}
+
+func (b *unixBackend) IniGetString(section, name, defVal string) string {
+	s, n, df := C.CString(section), C.CString(name), C.CString(defVal)
+	defer C.free(unsafe.Pointer(s))
+	defer C.free(unsafe.Pointer(n))
+	defer C.free(unsafe.Pointer(df))
+
+	return C.GoString(C.HGE_Ini_GetString(b.h, s, n, df))
+}
+
+func (b *unixBackend) RandomSeed(seed int) {
+	C.HGE_Random_Seed(b.h, C.int(seed))
+}
+
+func (b *unixBackend) RandomInt(min, max int) int {
+	return int(C.HGE_Random_Int(b.h, C.int(min), C.int(max)))
+}
+
+func (b *unixBackend) RandomFloat(min, max float64) float64 {
+	return float64(C.HGE_Random_Float(b.h, C.float(min), C.float(max)))
+}
+
+func (b *unixBackend) Time() float64 {
+	return float64(C.HGE_Timer_GetTime(b.h))
+}
+
+func (b *unixBackend) Delta() float64 {
+	return float64(C.HGE_Timer_GetDelta(b.h))
+}
+
+func (b *unixBackend) FPS() int {
+	return int(C.HGE_Timer_GetFPS(b.h))
+}
+
+func (b *unixBackend) EffectLoad(filename string, size Dword) HEffect {
+	fname := C.CString(filename)
+	defer C.free(unsafe.Pointer(fname))
+
+	return HEffect(C.HGE_Effect_Load(b.h, fname, C.DWORD(size)))
+}
+
+func (b *unixBackend) EffectFree(eff HEffect) {
+	C.HGE_Effect_Free(b.h, C.HEFFECT(eff))
+}
+
+func (b *unixBackend) EffectPlay(eff HEffect) HChannel {
+	return HChannel(C.HGE_Effect_Play(b.h, C.HEFFECT(eff)))
+}
+
+func (b *unixBackend) EffectPlayEx(eff HEffect, volume, pan int, pitch float64, loop bool) HChannel {
+	return HChannel(C.HGE_Effect_PlayEx(b.h, C.HEFFECT(eff), C.int(volume), C.int(pan), C.float(pitch), BoolToCInt(loop)))
+}
+
+func (b *unixBackend) MusicLoad(filename string, size Dword) HMusic {
+	fname := C.CString(filename)
+	defer C.free(unsafe.Pointer(fname))
+
+	return HMusic(C.HGE_Music_Load(b.h, fname, C.DWORD(size)))
+}
+
+func (b *unixBackend) MusicFree(mus HMusic) {
+	C.HGE_Music_Free(b.h, C.HMUSIC(mus))
+}
+
+func (b *unixBackend) MusicPlay(mus HMusic, loop bool, volume, order, row int) HChannel {
+	return HChannel(C.HGE_Music_Play(b.h, C.HMUSIC(mus), BoolToCInt(loop), C.int(volume), C.int(order), C.int(row)))
+}
+
+func (b *unixBackend) MusicSetAmplification(mus HMusic, ampl int) {
+	C.HGE_Music_SetAmplification(b.h, C.HMUSIC(mus), C.int(ampl))
+}
+
+func (b *unixBackend) MusicAmplification(mus HMusic) int {
+	return int(C.HGE_Music_GetAmplification(b.h, C.HMUSIC(mus)))
+}
+
+func (b *unixBackend) MusicLength(mus HMusic) int {
+	return int(C.HGE_Music_GetLength(b.h, C.HMUSIC(mus)))
+}
+
+func (b *unixBackend) MusicSetPos(mus HMusic, order, row int) {
+	C.HGE_Music_SetPos(b.h, C.HMUSIC(mus), C.int(order), C.int(row))
+}
+
+func (b *unixBackend) MusicPos(mus HMusic) (order, row int, ok bool) {
+	var o, r C.int
+
+	ok = C.HGE_Music_GetPos(b.h, C.HMUSIC(mus), &o, &r) == 1
+
+	return int(o), int(r), ok
+}
+
+func (b *unixBackend) MusicSetInstrVolume(mus HMusic, instr, volume int) {
+	C.HGE_Music_SetInstrVolume(b.h, C.HMUSIC(mus), C.int(instr), C.int(volume))
+}
+
+func (b *unixBackend) MusicInstrVolume(mus HMusic, instr int) int {
+	return int(C.HGE_Music_GetInstrVolume(b.h, C.HMUSIC(mus), C.int(instr)))
+}
+
+func (b *unixBackend) MusicSetChannelVolume(mus HMusic, channel, volume int) {
+	C.HGE_Music_SetChannelVolume(b.h, C.HMUSIC(mus), C.int(channel), C.int(volume))
+}
+
+func (b *unixBackend) MusicChannelVolume(mus HMusic, channel int) int {
+	return int(C.HGE_Music_GetChannelVolume(b.h, C.HMUSIC(mus), C.int(channel)))
+}
+
+func (b *unixBackend) StreamLoad(filename string, size Dword) HStream {
+	fname := C.CString(filename)
+	defer C.free(unsafe.Pointer(fname))
+
+	return HStream(C.HGE_Stream_Load(b.h, fname, C.DWORD(size)))
+}
+
+func (b *unixBackend) StreamFree(stream HStream) {
+	C.HGE_Stream_Free(b.h, C.HSTREAM(stream))
+}
+
+func (b *unixBackend) StreamPlay(stream HStream, loop bool, volume int) HChannel {
+	return HChannel(C.HGE_Stream_Play(b.h, C.HSTREAM(stream), BoolToCInt(loop), C.int(volume)))
+}
+
+func (b *unixBackend) ChannelSetPanning(chn HChannel, pan int) {
+	C.HGE_Channel_SetPanning(b.h, C.HCHANNEL(chn), C.int(pan))
+}
+
+func (b *unixBackend) ChannelSetVolume(chn HChannel, volume int) {
+	C.HGE_Channel_SetVolume(b.h, C.HCHANNEL(chn), C.int(volume))
+}
+
+func (b *unixBackend) ChannelSetPitch(chn HChannel, pitch float64) {
+	C.HGE_Channel_SetPitch(b.h, C.HCHANNEL(chn), C.float(pitch))
+}
+
+func (b *unixBackend) ChannelPause(chn HChannel) {
+	C.HGE_Channel_Pause(b.h, C.HCHANNEL(chn))
+}
+
+func (b *unixBackend) ChannelResume(chn HChannel) {
+	C.HGE_Channel_Resume(b.h, C.HCHANNEL(chn))
+}
+
+func (b *unixBackend) ChannelStop(chn HChannel) {
+	C.HGE_Channel_Stop(b.h, C.HCHANNEL(chn))
+}
+
+func (b *unixBackend) ChannelPauseAll() {
+	C.HGE_Channel_PauseAll(b.h)
+}
+
+func (b *unixBackend) ChannelResumeAll() {
+	C.HGE_Channel_ResumeAll(b.h)
+}
+
+func (b *unixBackend) ChannelStopAll() {
+	C.HGE_Channel_StopAll(b.h)
+}
+
+func (b *unixBackend) ChannelIsPlaying(chn HChannel) bool {
+	return C.HGE_Channel_IsPlaying(b.h, C.HCHANNEL(chn)) == 1
+}
+
+func (b *unixBackend) ChannelLength(chn HChannel) float64 {
+	return float64(C.HGE_Channel_GetLength(b.h, C.HCHANNEL(chn)))
+}
+
+func (b *unixBackend) ChannelPos(chn HChannel) float64 {
+	return float64(C.HGE_Channel_GetPos(b.h, C.HCHANNEL(chn)))
+}
+
+func (b *unixBackend) ChannelSetPos(chn HChannel, seconds float64) {
+	C.HGE_Channel_SetPos(b.h, C.HCHANNEL(chn), C.float(seconds))
+}
+
+func (b *unixBackend) ChannelSlideTo(chn HChannel, time float64, volume, pan int, pitch float64) {
+	C.HGE_Channel_SlideTo(b.h, C.HCHANNEL(chn), C.float(time), C.int(volume), C.int(pan), C.float(pitch))
+}
+
+func (b *unixBackend) ChannelIsSliding(chn HChannel) bool {
+	return C.HGE_Channel_IsSliding(b.h, C.HCHANNEL(chn)) == 1
+}
+
+func (b *unixBackend) MousePos() (x, y float64) {
+	var nx, ny C.float
+
+	C.HGE_Input_GetMousePos(b.h, &nx, &ny)
+
+	return float64(nx), float64(ny)
+}
+
+func (b *unixBackend) SetMousePos(x, y float64) {
+	C.HGE_Input_SetMousePos(b.h, C.float(x), C.float(y))
+}
+
+func (b *unixBackend) MouseWheel() int {
+	return int(C.HGE_Input_GetMouseWheel(b.h))
+}
+
+func (b *unixBackend) IsMouseOver() bool {
+	return C.HGE_Input_IsMouseOver(b.h) == 1
+}
+
+func (b *unixBackend) KeyDown(key int) bool {
+	return C.HGE_Input_KeyDown(b.h, C.int(key)) == 1
+}
+
+func (b *unixBackend) KeyUp(key int) bool {
+	return C.HGE_Input_KeyUp(b.h, C.int(key)) == 1
+}
+
+func (b *unixBackend) KeyState(key int) bool {
+	return C.HGE_Input_GetKeyState(b.h, C.int(key)) == 1
+}
+
+func (b *unixBackend) KeyName(key int) string {
+	return C.GoString(C.HGE_Input_GetKeyName(b.h, C.int(key)))
+}
+
+func (b *unixBackend) Key() int {
+	return int(C.HGE_Input_GetKey(b.h))
+}
+
+func (b *unixBackend) Char() int {
+	return int(C.HGE_Input_GetChar(b.h))
+}
+
+func (b *unixBackend) Event(e *BackendInputEvent) bool {
+	var ce C.HGE_InputEvent_t
+
+	if C.HGE_Input_GetEvent(b.h, &ce) != 1 {
+		return false
+	}
+
+	e.Type = int(ce._type)
+	e.Key = int(ce.key)
+	e.Flags = int(ce.flags)
+	e.Chr = int(ce.chr)
+	e.Wheel = int(ce.wheel)
+	e.X = float32(ce.x)
+	e.Y = float32(ce.y)
+
+	return true
+}
+
+func (b *unixBackend) BeginScene(target HTarget) bool {
+	return C.HGE_Gfx_BeginScene(b.h, C.HTARGET(target)) == 1
+}
+
+func (b *unixBackend) EndScene() {
+	C.HGE_Gfx_EndScene(b.h)
+}
+
+func (b *unixBackend) Clear(color Dword) {
+	C.HGE_Gfx_Clear(b.h, C.DWORD(color))
+}
+
+func (b *unixBackend) RenderLine(x1, y1, x2, y2 float64, color Dword, z float64) {
+	C.HGE_Gfx_RenderLine(b.h, C.float(x1), C.float(y1), C.float(x2), C.float(y2), C.DWORD(color), C.float(z))
+}
+
+func (b *unixBackend) RenderTriple(v *[3]BackendVertex, tex HTexture, blend int) {
+	ct := &cTriple{*v, C.HTEXTURE(tex), C.int(blend)}
+	C.HGE_Gfx_RenderTriple(b.h, (*C.HGE_Triple_t)(unsafe.Pointer(ct)))
+}
+
+func (b *unixBackend) RenderQuad(v *[4]BackendVertex, tex HTexture, blend int) {
+	cq := &cQuad{*v, C.HTEXTURE(tex), C.int(blend)}
+	C.HGE_Gfx_RenderQuad(b.h, (*C.HGE_Quad_t)(unsafe.Pointer(cq)))
+}
+
+func (b *unixBackend) StartBatch(primType int, tex HTexture, blend int) (*BackendVertex, int) {
+	mp := C.int(0)
+
+	v := C.HGE_Gfx_StartBatch(b.h, C.int(primType), C.HTEXTURE(tex), C.int(blend), &mp)
+
+	return (*BackendVertex)(unsafe.Pointer(v)), int(mp)
+}
+
+func (b *unixBackend) FinishBatch(prim int) {
+	C.HGE_Gfx_FinishBatch(b.h, C.int(prim))
+}
+
+func (b *unixBackend) SetClipping(x, y, w, h int) {
+	C.HGE_Gfx_SetClipping(b.h, C.int(x), C.int(y), C.int(w), C.int(h))
+}
+
+func (b *unixBackend) SetTransform(x, y, dx, dy, rot, hscale, vscale float64) {
+	C.HGE_Gfx_SetTransform(b.h, C.float(x), C.float(y), C.float(dx), C.float(dy), C.float(rot), C.float(hscale), C.float(vscale))
+}
+
+func (b *unixBackend) TargetCreate(width, height int, zbuffer bool) HTarget {
+	return HTarget(C.HGE_Target_Create(b.h, C.int(width), C.int(height), BoolToCInt(zbuffer)))
+}
+
+func (b *unixBackend) TargetFree(target HTarget) {
+	C.HGE_Target_Free(b.h, C.HTARGET(target))
+}
+
+func (b *unixBackend) TargetTexture(target HTarget) HTexture {
+	return HTexture(C.HGE_Target_GetTexture(b.h, C.HTARGET(target)))
+}
+
+func (b *unixBackend) TextureCreate(width, height int) HTexture {
+	return HTexture(C.HGE_Texture_Create(b.h, C.int(width), C.int(height)))
+}
+
+func (b *unixBackend) TextureLoad(filename string, size Dword, mipmap bool) HTexture {
+	fname := C.CString(filename)
+	defer C.free(unsafe.Pointer(fname))
+
+	return HTexture(C.HGE_Texture_Load(b.h, fname, C.DWORD(size), BoolToCInt(mipmap)))
+}
+
+func (b *unixBackend) TextureFree(tex HTexture) {
+	C.HGE_Texture_Free(b.h, C.HTEXTURE(tex))
+}
+
+func (b *unixBackend) TextureWidth(tex HTexture, original bool) int {
+	return int(C.HGE_Texture_GetWidth(b.h, C.HTEXTURE(tex), BoolToCInt(original)))
+}
+
+func (b *unixBackend) TextureHeight(tex HTexture, original bool) int {
+	return int(C.HGE_Texture_GetHeight(b.h, C.HTEXTURE(tex), BoolToCInt(original)))
+}
+
+func (b *unixBackend) TextureLock(tex HTexture, readonly bool, left, top, width, height int) *Dword {
+	d := C.HGE_Texture_Lock(b.h, C.HTEXTURE(tex), BoolToCInt(readonly), C.int(left), C.int(top), C.int(width), C.int(height))
+	return (*Dword)(unsafe.Pointer(d))
+}
+
+func (b *unixBackend) TextureUnlock(tex HTexture) {
+	C.HGE_Texture_Unlock(b.h, C.HTEXTURE(tex))
+}
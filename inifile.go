@@ -0,0 +1,114 @@
+package hge
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// A minimal ini file as read and written by the Windows profile functions
+// HGE uses. Section and key order are kept so files round trip.
+type iniFile struct {
+	sections []*iniSection
+}
+
+type iniSection struct {
+	name string
+	keys []string
+	vals map[string]string
+}
+
+func readIniFile(filename string) *iniFile {
+	ini := new(iniFile)
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return ini
+	}
+	defer f.Close()
+
+	var sec *iniSection
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if len(line) == 0 || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+
+		if line[0] == '[' && line[len(line)-1] == ']' {
+			sec = ini.section(line[1:len(line)-1], true)
+			continue
+		}
+
+		if sec == nil {
+			continue
+		}
+
+		if i := strings.Index(line, "="); i != -1 {
+			sec.set(strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:]))
+		}
+	}
+
+	return ini
+}
+
+func (ini *iniFile) section(name string, create bool) *iniSection {
+	for _, sec := range ini.sections {
+		if strings.EqualFold(sec.name, name) {
+			return sec
+		}
+	}
+
+	if !create {
+		return nil
+	}
+
+	sec := &iniSection{name: name, vals: make(map[string]string)}
+	ini.sections = append(ini.sections, sec)
+
+	return sec
+}
+
+func (ini *iniFile) get(section, name string) (string, bool) {
+	sec := ini.section(section, false)
+	if sec == nil {
+		return "", false
+	}
+
+	v, ok := sec.vals[strings.ToLower(name)]
+	return v, ok
+}
+
+func (ini *iniFile) set(section, name, value string) {
+	ini.section(section, true).set(name, value)
+}
+
+func (sec *iniSection) set(name, value string) {
+	key := strings.ToLower(name)
+	if _, ok := sec.vals[key]; !ok {
+		sec.keys = append(sec.keys, name)
+	}
+	sec.vals[key] = value
+}
+
+func (ini *iniFile) write(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	for i, sec := range ini.sections {
+		if i > 0 {
+			w.WriteString("\n")
+		}
+		w.WriteString("[" + sec.name + "]\n")
+		for _, key := range sec.keys {
+			w.WriteString(key + "=" + sec.vals[strings.ToLower(key)] + "\n")
+		}
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,287 @@
+package input
+
+import (
+	"sync"
+	"unsafe"
+
+	"github.com/losinggeneration/hge"
+)
+
+// Fills e with the next input event, like GetEvent but without making a new
+// event each time. Returns false when there are no more events this frame.
+//
+// Reading an event takes it off the queue, so this, GetEvent and
+// Dispatcher.Dispatch each see only the events the others haven't read. Code
+// using the Events dispatcher, like scene.Manager, mustn't read events this
+// way as well.
+func PollEvent(e *InputEvent) bool {
+	return inputHGE.Backend().Event((*hge.BackendInputEvent)(unsafe.Pointer(e)))
+}
+
+// An input event as one of KeyEvent, MouseButtonEvent, MouseMoveEvent or
+// WheelEvent.
+type Event interface {
+	EventType() int
+	// Returns the event as HGE gave it
+	Input() InputEvent
+}
+
+// What every event has: its type, the modifier flags and where the mouse
+// was.
+type BaseEvent struct {
+	Type  int
+	Flags int
+	X, Y  float64
+}
+
+func (e BaseEvent) EventType() int {
+	return e.Type
+}
+
+func (e BaseEvent) Input() InputEvent {
+	return InputEvent{Type: e.Type, Flags: e.Flags, X: float32(e.X), Y: float32(e.Y)}
+}
+
+func (e BaseEvent) Shift() bool {
+	return e.Flags&INP_SHIFT != 0
+}
+
+func (e BaseEvent) Ctrl() bool {
+	return e.Flags&INP_CTRL != 0
+}
+
+func (e BaseEvent) Alt() bool {
+	return e.Flags&INP_ALT != 0
+}
+
+func (e BaseEvent) CapsLock() bool {
+	return e.Flags&INP_CAPSLOCK != 0
+}
+
+func (e BaseEvent) NumLock() bool {
+	return e.Flags&INP_NUMLOCK != 0
+}
+
+func (e BaseEvent) ScrollLock() bool {
+	return e.Flags&INP_SCROLLLOCK != 0
+}
+
+// Reports whether a key down is the key repeating while it's held.
+func (e BaseEvent) Repeat() bool {
+	return e.Flags&INP_REPEAT != 0
+}
+
+// Reports whether exactly the modifiers in flags, out of INP_SHIFT, INP_CTRL
+// and INP_ALT, are held, so Ctrl+S doesn't match Ctrl+Shift+S.
+func (e BaseEvent) Modifiers(flags int) bool {
+	const mods = INP_SHIFT | INP_CTRL | INP_ALT
+	return e.Flags&mods == flags&mods
+}
+
+// INPUT_KEYDOWN and INPUT_KEYUP
+type KeyEvent struct {
+	BaseEvent
+	Key Key
+	Chr int // the character typed, if any
+}
+
+func (e KeyEvent) Input() InputEvent {
+	i := e.BaseEvent.Input()
+	i.Key, i.Chr = int(e.Key), e.Chr
+	return i
+}
+
+// INPUT_MBUTTONDOWN and INPUT_MBUTTONUP
+type MouseButtonEvent struct {
+	BaseEvent
+	Button Key // K_LBUTTON, K_RBUTTON or K_MBUTTON
+}
+
+func (e MouseButtonEvent) Input() InputEvent {
+	i := e.BaseEvent.Input()
+	i.Key = int(e.Button)
+	return i
+}
+
+// INPUT_MOUSEMOVE
+type MouseMoveEvent struct {
+	BaseEvent
+}
+
+// INPUT_MOUSEWHEEL
+type WheelEvent struct {
+	BaseEvent
+	Wheel int // notches turned, positive away from the user
+}
+
+func (e WheelEvent) Input() InputEvent {
+	i := e.BaseEvent.Input()
+	i.Wheel = e.Wheel
+	return i
+}
+
+// Returns the typed event for e, or nil if its type isn't known.
+func NewEvent(e *InputEvent) Event {
+	base := BaseEvent{Type: e.Type, Flags: e.Flags, X: float64(e.X), Y: float64(e.Y)}
+
+	switch e.Type {
+	case INPUT_KEYDOWN, INPUT_KEYUP:
+		return KeyEvent{base, Key(e.Key), e.Chr}
+	case INPUT_MBUTTONDOWN, INPUT_MBUTTONUP:
+		return MouseButtonEvent{base, Key(e.Key)}
+	case INPUT_MOUSEMOVE:
+		return MouseMoveEvent{base}
+	case INPUT_MOUSEWHEEL:
+		return WheelEvent{base, e.Wheel}
+	}
+
+	return nil
+}
+
+// Dispatcher drains HGE's input events once a frame and hands them to
+// subscribers, through callbacks or channels, so input handling doesn't have
+// to poll. Call Dispatch from the frame function.
+//
+// A dispatcher takes the events off the queue as it reads them, so there
+// should be only one reading them, which everything subscribes to. Events
+// returns the one the other packages use.
+type Dispatcher struct {
+	mutex sync.Mutex
+	subs  []*Subscription
+	event InputEvent
+}
+
+var events = NewDispatcher()
+
+// Returns the dispatcher shared by everything reading input events. Only
+// one Dispatch a frame should be called on it, by the application or by
+// whatever it's handed the events to, like scene.Manager.
+func Events() *Dispatcher {
+	return events
+}
+
+// A callback or channel events are sent to.
+type Subscription struct {
+	d       *Dispatcher
+	types   int // a bit for each event type, 0 for all of them
+	f       func(Event)
+	ch      chan Event
+	dropped int
+	done    bool
+}
+
+// Creates a dispatcher with no subscribers. Events is the one to use unless
+// nothing else reads the events.
+func NewDispatcher() *Dispatcher {
+	return new(Dispatcher)
+}
+
+func typeMask(types []int) int {
+	mask := 0
+	for _, t := range types {
+		mask |= 1 << uint(t)
+	}
+
+	return mask
+}
+
+func (d *Dispatcher) add(s *Subscription) *Subscription {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	s.d = d
+	d.subs = append(d.subs, s)
+
+	return s
+}
+
+// Calls f with events of the types given, or every event if none are. f is
+// called from Dispatch, on the engine's thread.
+func (d *Dispatcher) Subscribe(f func(Event), types ...int) *Subscription {
+	return d.add(&Subscription{types: typeMask(types), f: f})
+}
+
+// Returns a channel getting events of the types given, or every event if
+// none are. The channel holds size events. Dispatch never waits for a
+// reader, so events that don't fit are dropped; Dropped counts them.
+func (d *Dispatcher) Channel(size int, types ...int) (<-chan Event, *Subscription) {
+	s := d.add(&Subscription{types: typeMask(types), ch: make(chan Event, size)})
+	return s.ch, s
+}
+
+// Reads every event waiting and passes it to the subscribers that want it,
+// in the order they subscribed. Returns the number of events read.
+func (d *Dispatcher) Dispatch() int {
+	n := 0
+	for PollEvent(&d.event) {
+		n++
+
+		e := NewEvent(&d.event)
+		if e == nil {
+			continue
+		}
+
+		d.mutex.Lock()
+		subs := d.subs
+		d.mutex.Unlock()
+
+		for _, s := range subs {
+			s.send(e)
+		}
+	}
+
+	return n
+}
+
+func (s *Subscription) send(e Event) {
+	if s.types != 0 && s.types&(1<<uint(e.EventType())) == 0 {
+		return
+	}
+
+	// Sending never waits, so the lock keeps Cancel from closing the
+	// channel during it
+	s.d.mutex.Lock()
+	if s.done {
+		s.d.mutex.Unlock()
+		return
+	}
+	if s.ch != nil {
+		select {
+		case s.ch <- e:
+		default:
+			s.dropped++
+		}
+	}
+	s.d.mutex.Unlock()
+
+	if s.f != nil {
+		s.f(e)
+	}
+}
+
+// Returns how many events didn't fit in the channel.
+func (s *Subscription) Dropped() int {
+	s.d.mutex.Lock()
+	defer s.d.mutex.Unlock()
+
+	return s.dropped
+}
+
+// Stops sending events to the subscription. A channel is closed.
+func (s *Subscription) Cancel() {
+	d := s.d
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for i, sub := range d.subs {
+		if sub == s {
+			// Dispatch may be going through the old slice
+			d.subs = append(d.subs[:i:i], d.subs[i+1:]...)
+			s.done = true
+			if s.ch != nil {
+				close(s.ch)
+			}
+			return
+		}
+	}
+}
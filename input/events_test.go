@@ -0,0 +1,189 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/losinggeneration/hge"
+	"github.com/losinggeneration/hge/hgetest"
+)
+
+// Runs a frame for each function, on the headless backend. The input for
+// a frame is queued with the backend during the one before it, or in Setup
+// for the first.
+func playEvents(t *testing.T, frames ...func(h *hge.Headless)) {
+	t.Helper()
+
+	var headless *hge.Headless
+	_, err := hgetest.Render(hgetest.Scene{
+		Width: 8, Height: 8,
+		Frames: len(frames),
+		Setup: func() error {
+			h := hge.New()
+			headless = h.Backend().(*hge.Headless)
+			h.Free()
+			return nil
+		},
+		Frame: func(i int) { frames[i](headless) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Takes whatever's waiting on the channel without waiting for more.
+func drain(ch <-chan Event) (events []Event, closed bool) {
+	for {
+		select {
+		case e, ok := <-ch:
+			if !ok {
+				return events, true
+			}
+			events = append(events, e)
+		default:
+			return events, false
+		}
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	d := NewDispatcher()
+
+	var all, mouse []Event
+	d.Subscribe(func(e Event) { all = append(all, e) })
+	d.Subscribe(func(e Event) { mouse = append(mouse, e) }, INPUT_MBUTTONDOWN, INPUT_MBUTTONUP, INPUT_MOUSEWHEEL)
+	keys, keySub := d.Channel(16, INPUT_KEYDOWN, INPUT_KEYUP)
+	small, smallSub := d.Channel(2)
+
+	at := func(typ, flags int) BaseEvent {
+		return BaseEvent{Type: typ, Flags: flags, X: 10, Y: 20}
+	}
+	want := []Event{
+		MouseMoveEvent{at(INPUT_MOUSEMOVE, 0)},
+		KeyEvent{at(INPUT_KEYDOWN, 0), K_SHIFT, 0},
+		KeyEvent{at(INPUT_KEYDOWN, INP_SHIFT), K_A, 0},
+		KeyEvent{at(INPUT_KEYDOWN, INP_SHIFT|INP_REPEAT), K_A, 0},
+		MouseButtonEvent{at(INPUT_MBUTTONDOWN, INP_SHIFT), K_LBUTTON},
+		WheelEvent{at(INPUT_MOUSEWHEEL, INP_SHIFT), -3},
+		KeyEvent{at(INPUT_KEYDOWN, INP_SHIFT), K_B, 'B'},
+		KeyEvent{at(INPUT_KEYUP, INP_SHIFT), K_B, 'B'},
+	}
+
+	playEvents(t,
+		func(h *hge.Headless) {
+			if n := d.Dispatch(); n != 0 {
+				t.Errorf("dispatched %d events with no input", n)
+			}
+
+			h.MoveMouse(10, 20)
+			h.PressKey(int(K_SHIFT))
+			h.PressKey(int(K_A))
+			h.PressKey(int(K_A))
+			h.PressKey(int(K_LBUTTON))
+			h.ScrollWheel(-3)
+			h.TypeChar(int(K_B), 'B')
+		},
+		func(h *hge.Headless) {
+			if n := d.Dispatch(); n != len(want) {
+				t.Errorf("dispatched %d events, want %d", n, len(want))
+			}
+
+			if !reflect.DeepEqual(all, want) {
+				t.Errorf("the callback got %+v, want %+v", all, want)
+			}
+			if wantMouse := []Event{want[4], want[5]}; !reflect.DeepEqual(mouse, wantMouse) {
+				t.Errorf("the mouse callback got %+v, want %+v", mouse, wantMouse)
+			}
+			if got, _ := drain(keys); !reflect.DeepEqual(got, []Event{want[1], want[2], want[3], want[6], want[7]}) {
+				t.Errorf("the key channel got %+v", got)
+			}
+
+			// The channel keeps what fits and drops the rest
+			if got, _ := drain(small); !reflect.DeepEqual(got, want[:2]) {
+				t.Errorf("the small channel got %+v, want %+v", got, want[:2])
+			}
+			if n := smallSub.Dropped(); n != len(want)-2 {
+				t.Errorf("the small channel dropped %d events, want %d", n, len(want)-2)
+			}
+
+			// Converting back gives what HGE gave
+			if e := all[6].Input(); e != (InputEvent{Type: INPUT_KEYDOWN, Key: int(K_B), Flags: INP_SHIFT, Chr: 'B', X: 10, Y: 20}) {
+				t.Errorf("the typed key converts back to %+v", e)
+			}
+			if e := all[5].Input(); e.Wheel != -3 || e.Key != 0 {
+				t.Errorf("the wheel converts back to %+v", e)
+			}
+
+			keySub.Cancel()
+			h.ReleaseKey(int(K_A))
+			h.ReleaseKey(int(K_SHIFT))
+		},
+		func(h *hge.Headless) {
+			all = nil
+			if n := d.Dispatch(); n != 2 {
+				t.Errorf("dispatched %d events, want 2", n)
+			}
+			if len(all) != 2 {
+				t.Errorf("the callback got %+v, want the two keys let go", all)
+			}
+
+			// A cancelled channel is closed and gets nothing more
+			if got, closed := drain(keys); len(got) != 0 || !closed {
+				t.Errorf("the cancelled key channel got %+v and closed is %v", got, closed)
+			}
+			// Cancelling again does nothing
+			keySub.Cancel()
+		},
+	)
+}
+
+func TestReadersTakeEvents(t *testing.T) {
+	d := NewDispatcher()
+	ch, sub := d.Channel(8)
+	defer sub.Cancel()
+
+	playEvents(t,
+		func(h *hge.Headless) {
+			h.PressKey(int(K_X))
+			h.PressKey(int(K_Y))
+			h.ReleaseKey(int(K_X))
+		},
+		func(h *hge.Headless) {
+			// What GetEvent reads the dispatcher doesn't see
+			e, ok := GetEvent()
+			if !ok || e.Type != INPUT_KEYDOWN || Key(e.Key) != K_X {
+				t.Errorf("got %+v,%v, want X going down", e, ok)
+			}
+			if n := d.Dispatch(); n != 2 {
+				t.Errorf("dispatched %d events, want the 2 left", n)
+			}
+			got, _ := drain(ch)
+			if len(got) != 2 || got[0].(KeyEvent).Key != K_Y || got[1].EventType() != INPUT_KEYUP {
+				t.Errorf("the channel got %+v, want Y going down and X up", got)
+			}
+
+			// And what the dispatcher read nothing else does
+			var ev InputEvent
+			if PollEvent(&ev) {
+				t.Errorf("polled %+v after Dispatch", ev)
+			}
+			if n := Events().Dispatch(); n != 0 {
+				t.Errorf("the shared dispatcher read %d events after Dispatch", n)
+			}
+
+			h.ReleaseKey(int(K_Y))
+		},
+		// Y going up isn't read
+		func(h *hge.Headless) {},
+		func(h *hge.Headless) {
+			// Events that aren't read by the end of the frame are gone
+			if n := d.Dispatch(); n != 0 {
+				t.Errorf("dispatched %d events a frame late", n)
+			}
+		},
+	)
+
+	if Events() != Events() {
+		t.Error("Events returns a different dispatcher each time")
+	}
+}
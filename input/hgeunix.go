@@ -0,0 +1,130 @@
+//go:build !headless
+
+package input
+
+/*
+#cgo pkg-config: hge-unix-c
+#include "hge_c.h"
+*/
+import "C"
+
+// The event, flag and key constants in input.go are hard-coded so headless
+// builds don't need hge_c.h. These stop compiling if one drifts from the
+// header.
+var (
+	_ = [1]struct{}{}[INPUT_KEYDOWN-C.HGE_INPUT_KEYDOWN]
+	_ = [1]struct{}{}[INPUT_KEYUP-C.HGE_INPUT_KEYUP]
+	_ = [1]struct{}{}[INPUT_MBUTTONDOWN-C.HGE_INPUT_MBUTTONDOWN]
+	_ = [1]struct{}{}[INPUT_MBUTTONUP-C.HGE_INPUT_MBUTTONUP]
+	_ = [1]struct{}{}[INPUT_MOUSEMOVE-C.HGE_INPUT_MOUSEMOVE]
+	_ = [1]struct{}{}[INPUT_MOUSEWHEEL-C.HGE_INPUT_MOUSEWHEEL]
+	_ = [1]struct{}{}[INP_SHIFT-C.HGE_INP_SHIFT]
+	_ = [1]struct{}{}[INP_CTRL-C.HGE_INP_CTRL]
+	_ = [1]struct{}{}[INP_ALT-C.HGE_INP_ALT]
+	_ = [1]struct{}{}[INP_CAPSLOCK-C.HGE_INP_CAPSLOCK]
+	_ = [1]struct{}{}[INP_SCROLLLOCK-C.HGE_INP_SCROLLLOCK]
+	_ = [1]struct{}{}[INP_NUMLOCK-C.HGE_INP_NUMLOCK]
+	_ = [1]struct{}{}[INP_REPEAT-C.HGE_INP_REPEAT]
+	_ = [1]struct{}{}[K_LBUTTON-C.HGE_K_LBUTTON]
+	_ = [1]struct{}{}[K_RBUTTON-C.HGE_K_RBUTTON]
+	_ = [1]struct{}{}[K_MBUTTON-C.HGE_K_MBUTTON]
+	_ = [1]struct{}{}[K_ESCAPE-C.HGE_K_ESCAPE]
+	_ = [1]struct{}{}[K_BACKSPACE-C.HGE_K_BACKSPACE]
+	_ = [1]struct{}{}[K_TAB-C.HGE_K_TAB]
+	_ = [1]struct{}{}[K_ENTER-C.HGE_K_ENTER]
+	_ = [1]struct{}{}[K_SPACE-C.HGE_K_SPACE]
+	_ = [1]struct{}{}[K_SHIFT-C.HGE_K_SHIFT]
+	_ = [1]struct{}{}[K_CTRL-C.HGE_K_CTRL]
+	_ = [1]struct{}{}[K_ALT-C.HGE_K_ALT]
+	_ = [1]struct{}{}[K_LWIN-C.HGE_K_LWIN]
+	_ = [1]struct{}{}[K_RWIN-C.HGE_K_RWIN]
+	_ = [1]struct{}{}[K_APPS-C.HGE_K_APPS]
+	_ = [1]struct{}{}[K_PAUSE-C.HGE_K_PAUSE]
+	_ = [1]struct{}{}[K_CAPSLOCK-C.HGE_K_CAPSLOCK]
+	_ = [1]struct{}{}[K_NUMLOCK-C.HGE_K_NUMLOCK]
+	_ = [1]struct{}{}[K_SCROLLLOCK-C.HGE_K_SCROLLLOCK]
+	_ = [1]struct{}{}[K_PGUP-C.HGE_K_PGUP]
+	_ = [1]struct{}{}[K_PGDN-C.HGE_K_PGDN]
+	_ = [1]struct{}{}[K_HOME-C.HGE_K_HOME]
+	_ = [1]struct{}{}[K_END-C.HGE_K_END]
+	_ = [1]struct{}{}[K_INSERT-C.HGE_K_INSERT]
+	_ = [1]struct{}{}[K_DELETE-C.HGE_K_DELETE]
+	_ = [1]struct{}{}[K_LEFT-C.HGE_K_LEFT]
+	_ = [1]struct{}{}[K_UP-C.HGE_K_UP]
+	_ = [1]struct{}{}[K_RIGHT-C.HGE_K_RIGHT]
+	_ = [1]struct{}{}[K_DOWN-C.HGE_K_DOWN]
+	_ = [1]struct{}{}[K_0-C.HGE_K_0]
+	_ = [1]struct{}{}[K_1-C.HGE_K_1]
+	_ = [1]struct{}{}[K_2-C.HGE_K_2]
+	_ = [1]struct{}{}[K_3-C.HGE_K_3]
+	_ = [1]struct{}{}[K_4-C.HGE_K_4]
+	_ = [1]struct{}{}[K_5-C.HGE_K_5]
+	_ = [1]struct{}{}[K_6-C.HGE_K_6]
+	_ = [1]struct{}{}[K_7-C.HGE_K_7]
+	_ = [1]struct{}{}[K_8-C.HGE_K_8]
+	_ = [1]struct{}{}[K_9-C.HGE_K_9]
+	_ = [1]struct{}{}[K_A-C.HGE_K_A]
+	_ = [1]struct{}{}[K_B-C.HGE_K_B]
+	_ = [1]struct{}{}[K_C-C.HGE_K_C]
+	_ = [1]struct{}{}[K_D-C.HGE_K_D]
+	_ = [1]struct{}{}[K_E-C.HGE_K_E]
+	_ = [1]struct{}{}[K_F-C.HGE_K_F]
+	_ = [1]struct{}{}[K_G-C.HGE_K_G]
+	_ = [1]struct{}{}[K_H-C.HGE_K_H]
+	_ = [1]struct{}{}[K_I-C.HGE_K_I]
+	_ = [1]struct{}{}[K_J-C.HGE_K_J]
+	_ = [1]struct{}{}[K_K-C.HGE_K_K]
+	_ = [1]struct{}{}[K_L-C.HGE_K_L]
+	_ = [1]struct{}{}[K_M-C.HGE_K_M]
+	_ = [1]struct{}{}[K_N-C.HGE_K_N]
+	_ = [1]struct{}{}[K_O-C.HGE_K_O]
+	_ = [1]struct{}{}[K_P-C.HGE_K_P]
+	_ = [1]struct{}{}[K_Q-C.HGE_K_Q]
+	_ = [1]struct{}{}[K_R-C.HGE_K_R]
+	_ = [1]struct{}{}[K_S-C.HGE_K_S]
+	_ = [1]struct{}{}[K_T-C.HGE_K_T]
+	_ = [1]struct{}{}[K_U-C.HGE_K_U]
+	_ = [1]struct{}{}[K_V-C.HGE_K_V]
+	_ = [1]struct{}{}[K_W-C.HGE_K_W]
+	_ = [1]struct{}{}[K_X-C.HGE_K_X]
+	_ = [1]struct{}{}[K_Y-C.HGE_K_Y]
+	_ = [1]struct{}{}[K_Z-C.HGE_K_Z]
+	_ = [1]struct{}{}[K_GRAVE-C.HGE_K_GRAVE]
+	_ = [1]struct{}{}[K_MINUS-C.HGE_K_MINUS]
+	_ = [1]struct{}{}[K_EQUALS-C.HGE_K_EQUALS]
+	_ = [1]struct{}{}[K_BACKSLASH-C.HGE_K_BACKSLASH]
+	_ = [1]struct{}{}[K_LBRACKET-C.HGE_K_LBRACKET]
+	_ = [1]struct{}{}[K_RBRACKET-C.HGE_K_RBRACKET]
+	_ = [1]struct{}{}[K_SEMICOLON-C.HGE_K_SEMICOLON]
+	_ = [1]struct{}{}[K_APOSTROPHE-C.HGE_K_APOSTROPHE]
+	_ = [1]struct{}{}[K_COMMA-C.HGE_K_COMMA]
+	_ = [1]struct{}{}[K_PERIOD-C.HGE_K_PERIOD]
+	_ = [1]struct{}{}[K_SLASH-C.HGE_K_SLASH]
+	_ = [1]struct{}{}[K_NUMPAD0-C.HGE_K_NUMPAD0]
+	_ = [1]struct{}{}[K_NUMPAD1-C.HGE_K_NUMPAD1]
+	_ = [1]struct{}{}[K_NUMPAD2-C.HGE_K_NUMPAD2]
+	_ = [1]struct{}{}[K_NUMPAD3-C.HGE_K_NUMPAD3]
+	_ = [1]struct{}{}[K_NUMPAD4-C.HGE_K_NUMPAD4]
+	_ = [1]struct{}{}[K_NUMPAD5-C.HGE_K_NUMPAD5]
+	_ = [1]struct{}{}[K_NUMPAD6-C.HGE_K_NUMPAD6]
+	_ = [1]struct{}{}[K_NUMPAD7-C.HGE_K_NUMPAD7]
+	_ = [1]struct{}{}[K_NUMPAD8-C.HGE_K_NUMPAD8]
+	_ = [1]struct{}{}[K_NUMPAD9-C.HGE_K_NUMPAD9]
+	_ = [1]struct{}{}[K_MULTIPLY-C.HGE_K_MULTIPLY]
+	_ = [1]struct{}{}[K_DIVIDE-C.HGE_K_DIVIDE]
+	_ = [1]struct{}{}[K_ADD-C.HGE_K_ADD]
+	_ = [1]struct{}{}[K_SUBTRACT-C.HGE_K_SUBTRACT]
+	_ = [1]struct{}{}[K_DECIMAL-C.HGE_K_DECIMAL]
+	_ = [1]struct{}{}[K_F1-C.HGE_K_F1]
+	_ = [1]struct{}{}[K_F2-C.HGE_K_F2]
+	_ = [1]struct{}{}[K_F3-C.HGE_K_F3]
+	_ = [1]struct{}{}[K_F4-C.HGE_K_F4]
+	_ = [1]struct{}{}[K_F5-C.HGE_K_F5]
+	_ = [1]struct{}{}[K_F6-C.HGE_K_F6]
+	_ = [1]struct{}{}[K_F7-C.HGE_K_F7]
+	_ = [1]struct{}{}[K_F8-C.HGE_K_F8]
+	_ = [1]struct{}{}[K_F9-C.HGE_K_F9]
+	_ = [1]struct{}{}[K_F10-C.HGE_K_F10]
+	_ = [1]struct{}{}[K_F11-C.HGE_K_F11]
+	_ = [1]struct{}{}[K_F12-C.HGE_K_F12]
+)
@@ -1,14 +1,6 @@
 package input
 
-/*
-#cgo pkg-config: hge-unix-c
-#include "hge_c.h"
-*/
-import "C"
-
 import (
-	"unsafe"
-
 	"github.com/losinggeneration/hge"
 )
 
@@ -27,141 +19,141 @@ type InputEvent struct {
 
 // HGE Input Event type constants
 const (
-	INPUT_KEYDOWN     = C.HGE_INPUT_KEYDOWN
-	INPUT_KEYUP       = C.HGE_INPUT_KEYUP
-	INPUT_MBUTTONDOWN = C.HGE_INPUT_MBUTTONDOWN
-	INPUT_MBUTTONUP   = C.HGE_INPUT_MBUTTONUP
-	INPUT_MOUSEMOVE   = C.HGE_INPUT_MOUSEMOVE
-	INPUT_MOUSEWHEEL  = C.HGE_INPUT_MOUSEWHEEL
+	INPUT_KEYDOWN     = 1
+	INPUT_KEYUP       = 2
+	INPUT_MBUTTONDOWN = 3
+	INPUT_MBUTTONUP   = 4
+	INPUT_MOUSEMOVE   = 5
+	INPUT_MOUSEWHEEL  = 6
 )
 
 // HGE Input Event flags
 const (
-	INP_SHIFT      = C.HGE_INP_SHIFT
-	INP_CTRL       = C.HGE_INP_CTRL
-	INP_ALT        = C.HGE_INP_ALT
-	INP_CAPSLOCK   = C.HGE_INP_CAPSLOCK
-	INP_SCROLLLOCK = C.HGE_INP_SCROLLLOCK
-	INP_NUMLOCK    = C.HGE_INP_NUMLOCK
-	INP_REPEAT     = C.HGE_INP_REPEAT
+	INP_SHIFT      = 1
+	INP_CTRL       = 2
+	INP_ALT        = 4
+	INP_CAPSLOCK   = 8
+	INP_SCROLLLOCK = 16
+	INP_NUMLOCK    = 32
+	INP_REPEAT     = 64
 )
 
 // HGE_ Virtual-key codes
 const (
-	K_LBUTTON = C.HGE_K_LBUTTON
-	K_RBUTTON = C.HGE_K_RBUTTON
-	K_MBUTTON = C.HGE_K_MBUTTON
-
-	K_ESCAPE    = C.HGE_K_ESCAPE
-	K_BACKSPACE = C.HGE_K_BACKSPACE
-	K_TAB       = C.HGE_K_TAB
-	K_ENTER     = C.HGE_K_ENTER
-	K_SPACE     = C.HGE_K_SPACE
-
-	K_SHIFT = C.HGE_K_SHIFT
-	K_CTRL  = C.HGE_K_CTRL
-	K_ALT   = C.HGE_K_ALT
-
-	K_LWIN = C.HGE_K_LWIN
-	K_RWIN = C.HGE_K_RWIN
-	K_APPS = C.HGE_K_APPS
-
-	K_PAUSE      = C.HGE_K_PAUSE
-	K_CAPSLOCK   = C.HGE_K_CAPSLOCK
-	K_NUMLOCK    = C.HGE_K_NUMLOCK
-	K_SCROLLLOCK = C.HGE_K_SCROLLLOCK
-
-	K_PGUP   = C.HGE_K_PGUP
-	K_PGDN   = C.HGE_K_PGDN
-	K_HOME   = C.HGE_K_HOME
-	K_END    = C.HGE_K_END
-	K_INSERT = C.HGE_K_INSERT
-	K_DELETE = C.HGE_K_DELETE
-
-	K_LEFT  = C.HGE_K_LEFT
-	K_UP    = C.HGE_K_UP
-	K_RIGHT = C.HGE_K_RIGHT
-	K_DOWN  = C.HGE_K_DOWN
-
-	K_0 = C.HGE_K_0
-	K_1 = C.HGE_K_1
-	K_2 = C.HGE_K_2
-	K_3 = C.HGE_K_3
-	K_4 = C.HGE_K_4
-	K_5 = C.HGE_K_5
-	K_6 = C.HGE_K_6
-	K_7 = C.HGE_K_7
-	K_8 = C.HGE_K_8
-	K_9 = C.HGE_K_9
-
-	K_A = C.HGE_K_A
-	K_B = C.HGE_K_B
-	K_C = C.HGE_K_C
-	K_D = C.HGE_K_D
-	K_E = C.HGE_K_E
-	K_F = C.HGE_K_F
-	K_G = C.HGE_K_G
-	K_H = C.HGE_K_H
-	K_I = C.HGE_K_I
-	K_J = C.HGE_K_J
-	K_K = C.HGE_K_K
-	K_L = C.HGE_K_L
-	K_M = C.HGE_K_M
-	K_N = C.HGE_K_N
-	K_O = C.HGE_K_O
-	K_P = C.HGE_K_P
-	K_Q = C.HGE_K_Q
-	K_R = C.HGE_K_R
-	K_S = C.HGE_K_S
-	K_T = C.HGE_K_T
-	K_U = C.HGE_K_U
-	K_V = C.HGE_K_V
-	K_W = C.HGE_K_W
-	K_X = C.HGE_K_X
-	K_Y = C.HGE_K_Y
-	K_Z = C.HGE_K_Z
-
-	K_GRAVE      = C.HGE_K_GRAVE
-	K_MINUS      = C.HGE_K_MINUS
-	K_EQUALS     = C.HGE_K_EQUALS
-	K_BACKSLASH  = C.HGE_K_BACKSLASH
-	K_LBRACKET   = C.HGE_K_LBRACKET
-	K_RBRACKET   = C.HGE_K_RBRACKET
-	K_SEMICOLON  = C.HGE_K_SEMICOLON
-	K_APOSTROPHE = C.HGE_K_APOSTROPHE
-	K_COMMA      = C.HGE_K_COMMA
-	K_PERIOD     = C.HGE_K_PERIOD
-	K_SLASH      = C.HGE_K_SLASH
-
-	K_NUMPAD0 = C.HGE_K_NUMPAD0
-	K_NUMPAD1 = C.HGE_K_NUMPAD1
-	K_NUMPAD2 = C.HGE_K_NUMPAD2
-	K_NUMPAD3 = C.HGE_K_NUMPAD3
-	K_NUMPAD4 = C.HGE_K_NUMPAD4
-	K_NUMPAD5 = C.HGE_K_NUMPAD5
-	K_NUMPAD6 = C.HGE_K_NUMPAD6
-	K_NUMPAD7 = C.HGE_K_NUMPAD7
-	K_NUMPAD8 = C.HGE_K_NUMPAD8
-	K_NUMPAD9 = C.HGE_K_NUMPAD9
-
-	K_MULTIPLY = C.HGE_K_MULTIPLY
-	K_DIVIDE   = C.HGE_K_DIVIDE
-	K_ADD      = C.HGE_K_ADD
-	K_SUBTRACT = C.HGE_K_SUBTRACT
-	K_DECIMAL  = C.HGE_K_DECIMAL
-
-	K_F1  = C.HGE_K_F1
-	K_F2  = C.HGE_K_F2
-	K_F3  = C.HGE_K_F3
-	K_F4  = C.HGE_K_F4
-	K_F5  = C.HGE_K_F5
-	K_F6  = C.HGE_K_F6
-	K_F7  = C.HGE_K_F7
-	K_F8  = C.HGE_K_F8
-	K_F9  = C.HGE_K_F9
-	K_F10 = C.HGE_K_F10
-	K_F11 = C.HGE_K_F11
-	K_F12 = C.HGE_K_F12
+	K_LBUTTON = 0x01
+	K_RBUTTON = 0x02
+	K_MBUTTON = 0x04
+
+	K_ESCAPE    = 0x1B
+	K_BACKSPACE = 0x08
+	K_TAB       = 0x09
+	K_ENTER     = 0x0D
+	K_SPACE     = 0x20
+
+	K_SHIFT = 0x10
+	K_CTRL  = 0x11
+	K_ALT   = 0x12
+
+	K_LWIN = 0x5B
+	K_RWIN = 0x5C
+	K_APPS = 0x5D
+
+	K_PAUSE      = 0x13
+	K_CAPSLOCK   = 0x14
+	K_NUMLOCK    = 0x90
+	K_SCROLLLOCK = 0x91
+
+	K_PGUP   = 0x21
+	K_PGDN   = 0x22
+	K_HOME   = 0x24
+	K_END    = 0x23
+	K_INSERT = 0x2D
+	K_DELETE = 0x2E
+
+	K_LEFT  = 0x25
+	K_UP    = 0x26
+	K_RIGHT = 0x27
+	K_DOWN  = 0x28
+
+	K_0 = 0x30
+	K_1 = 0x31
+	K_2 = 0x32
+	K_3 = 0x33
+	K_4 = 0x34
+	K_5 = 0x35
+	K_6 = 0x36
+	K_7 = 0x37
+	K_8 = 0x38
+	K_9 = 0x39
+
+	K_A = 0x41
+	K_B = 0x42
+	K_C = 0x43
+	K_D = 0x44
+	K_E = 0x45
+	K_F = 0x46
+	K_G = 0x47
+	K_H = 0x48
+	K_I = 0x49
+	K_J = 0x4A
+	K_K = 0x4B
+	K_L = 0x4C
+	K_M = 0x4D
+	K_N = 0x4E
+	K_O = 0x4F
+	K_P = 0x50
+	K_Q = 0x51
+	K_R = 0x52
+	K_S = 0x53
+	K_T = 0x54
+	K_U = 0x55
+	K_V = 0x56
+	K_W = 0x57
+	K_X = 0x58
+	K_Y = 0x59
+	K_Z = 0x5A
+
+	K_GRAVE      = 0xC0
+	K_MINUS      = 0xBD
+	K_EQUALS     = 0xBB
+	K_BACKSLASH  = 0xDC
+	K_LBRACKET   = 0xDB
+	K_RBRACKET   = 0xDD
+	K_SEMICOLON  = 0xBA
+	K_APOSTROPHE = 0xDE
+	K_COMMA      = 0xBC
+	K_PERIOD     = 0xBE
+	K_SLASH      = 0xBF
+
+	K_NUMPAD0 = 0x60
+	K_NUMPAD1 = 0x61
+	K_NUMPAD2 = 0x62
+	K_NUMPAD3 = 0x63
+	K_NUMPAD4 = 0x64
+	K_NUMPAD5 = 0x65
+	K_NUMPAD6 = 0x66
+	K_NUMPAD7 = 0x67
+	K_NUMPAD8 = 0x68
+	K_NUMPAD9 = 0x69
+
+	K_MULTIPLY = 0x6A
+	K_DIVIDE   = 0x6F
+	K_ADD      = 0x6B
+	K_SUBTRACT = 0x6D
+	K_DECIMAL  = 0x6E
+
+	K_F1  = 0x70
+	K_F2  = 0x71
+	K_F3  = 0x72
+	K_F4  = 0x73
+	K_F5  = 0x74
+	K_F6  = 0x75
+	K_F7  = 0x76
+	K_F8  = 0x77
+	K_F9  = 0x78
+	K_F10 = 0x79
+	K_F11 = 0x7A
+	K_F12 = 0x7B
 )
 
 var inputHGE *hge.HGE
@@ -181,12 +173,9 @@ func NewMouse(x, y float64) *Mouse {
 }
 
 func (m *Mouse) Pos() (x, y float64) {
-	var nx, ny C.float
-
-	C.HGE_Input_GetMousePos(inputHGE.HGE, &nx, &ny)
-	m.X, m.Y = float64(nx), float64(ny)
+	m.X, m.Y = inputHGE.Backend().MousePos()
 
-	return float64(nx), float64(ny)
+	return m.X, m.Y
 }
 
 func (m Mouse) SetPos(a ...interface{}) {
@@ -202,16 +191,16 @@ func (m Mouse) SetPos(a ...interface{}) {
 			}
 		}
 	}
-	C.HGE_Input_SetMousePos(inputHGE.HGE, C.float(x), C.float(y))
+	inputHGE.Backend().SetMousePos(x, y)
 }
 
 func (m *Mouse) WheelMovement() int {
-	m.Wheel = int(C.HGE_Input_GetMouseWheel(inputHGE.HGE))
+	m.Wheel = inputHGE.Backend().MouseWheel()
 	return m.Wheel
 }
 
 func (m *Mouse) IsOver() bool {
-	m.Over = C.HGE_Input_IsMouseOver(inputHGE.HGE) == 1
+	m.Over = inputHGE.Backend().IsMouseOver()
 	return m.Over
 }
 
@@ -220,30 +209,33 @@ func NewKey(i int) Key {
 }
 
 func (k Key) Down() bool {
-	return C.HGE_Input_KeyDown(inputHGE.HGE, C.int(k)) == 1
+	return inputHGE.Backend().KeyDown(int(k))
 }
 
 func (k Key) Up() bool {
-	return C.HGE_Input_KeyUp(inputHGE.HGE, C.int(k)) == 1
+	return inputHGE.Backend().KeyUp(int(k))
 }
 
 func (k Key) State() bool {
-	return C.HGE_Input_GetKeyState(inputHGE.HGE, C.int(k)) == 1
+	return inputHGE.Backend().KeyState(int(k))
 }
 func (k Key) Name() string {
-	return C.GoString(C.HGE_Input_GetKeyName(inputHGE.HGE, C.int(k)))
+	return inputHGE.Backend().KeyName(int(k))
 }
 
 func GetKey() Key {
-	return Key(C.HGE_Input_GetKey(inputHGE.HGE))
+	return Key(inputHGE.Backend().Key())
 }
 
 func GetChar() int {
-	return int(C.HGE_Input_GetChar(inputHGE.HGE))
+	return inputHGE.Backend().Char()
 }
 
+// Returns the next input event, or false when there are no more this frame.
+// The event is taken off the queue, so it isn't seen by the Events
+// dispatcher; see PollEvent.
 func GetEvent() (e *InputEvent, b bool) {
 	e = new(InputEvent)
-	b = C.HGE_Input_GetEvent(inputHGE.HGE, (*C.HGE_InputEvent_t)(unsafe.Pointer(e))) == 1
+	b = PollEvent(e)
 	return e, b
 }
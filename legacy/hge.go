@@ -75,7 +75,12 @@ func (h *HGE) System_GetState(a ...interface{}) interface{} {
 
 // Loads a resource into memory from disk.
 func (h *HGE) Resource_Load(filename string) (*resource.Resource, hge.Dword) {
-	return resource.NewResource(filename)
+	r, err := resource.NewResource(filename)
+	if err != nil {
+		return nil, 0
+	}
+
+	return r, r.Size()
 }
 
 // Deletes a previously loaded resource from memory.
@@ -85,12 +90,14 @@ func (h *HGE) Resource_Free(res resource.Resource) {
 
 // Loads a resource, puts the loaded data into a byte array, and frees the data.
 func (h *HGE) ResourceLoadBytes(filename string) []byte {
-	return resource.LoadBytes(filename)
+	b, _ := resource.LoadBytes(filename)
+	return b
 }
 
 // Loads a resource, puts the data into a string, and frees the data.
 func (h *HGE) ResourceLoadString(filename string) *string {
-	return resource.LoadString(filename)
+	s, _ := resource.LoadString(filename)
+	return s
 }
 
 // Attaches a resource pack.
@@ -185,7 +192,8 @@ func (h *HGE) Timer_GetFPS() int {
 }
 
 func (h *HGE) Effect_Load(filename string, a ...interface{}) *sound.Effect {
-	return sound.NewEffect(filename, a...)
+	eff, _ := sound.NewEffect(filename, a...)
+	return eff
 }
 
 func (h *HGE) Effect_Free(eff *sound.Effect) {
@@ -201,7 +209,8 @@ func (h *HGE) Effect_PlayEx(eff *sound.Effect, a ...interface{}) sound.Channel {
 }
 
 func (h *HGE) Music_Load(filename string, size hge.Dword) *sound.Music {
-	return sound.NewMusic(filename, size)
+	music, _ := sound.NewMusic(filename, size)
+	return music
 }
 
 func (h *HGE) Music_Free(music *sound.Music) {
@@ -249,7 +258,8 @@ func (h *HGE) Music_GetChannelVolume(music *sound.Music, channel int) int {
 }
 
 func (h *HGE) Stream_Load(filename string, size hge.Dword) *sound.Stream {
-	return sound.NewStream(filename, size)
+	stream, _ := sound.NewStream(filename, size)
+	return stream
 }
 
 func (h *HGE) Stream_Free(stream *sound.Stream) {
@@ -422,7 +432,8 @@ func (h *HGE) Texture_Create(width, height int) *gfx.Texture {
 }
 
 func (h *HGE) Texture_Load(filename string, a ...interface{}) *gfx.Texture {
-	return gfx.LoadTexture(filename, a...)
+	tex, _ := gfx.LoadTexture(filename, a...)
+	return tex
 }
 
 func (h *HGE) Texture_Free(tex *gfx.Texture) {
@@ -0,0 +1,145 @@
+package hge
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+)
+
+// The subsystems HGE's own messages are tagged with.
+const (
+	LogSystem   = "system"
+	LogGfx      = "gfx"
+	LogSound    = "sound"
+	LogResource = "resource"
+	LogGUI      = "gui"
+	LogParticle = "particle"
+)
+
+// The attribute key a message's subsystem is kept under.
+const LogSubsystemKey = "subsystem"
+
+var (
+	logger    atomic.Pointer[slog.Logger]
+	logMirror atomic.Bool
+)
+
+func init() {
+	SetLogger(nil, true)
+}
+
+// Sets the logger HGE.Log and HGE's own messages go to. A nil logger drops
+// them. With mirror set, messages at info level and above are also written to
+// the log file set with the LOGFILE state, which is what happens by default.
+func SetLogger(l *slog.Logger, mirror bool) {
+	h := slog.Handler(slog.DiscardHandler)
+	if l != nil {
+		h = l.Handler()
+	}
+	if mirror {
+		h = &mirrorHandler{next: h}
+	}
+
+	logger.Store(slog.New(h))
+	logMirror.Store(mirror)
+}
+
+// Returns the logger for a subsystem, one of the Log constants or any other
+// name. An empty name gives the logger HGE.Log uses, its messages aren't
+// tagged.
+func Logger(subsystem string) *slog.Logger {
+	l := logger.Load()
+	if subsystem == "" {
+		return l
+	}
+
+	return l.With(LogSubsystemKey, subsystem)
+}
+
+// Writes records to the backend's log file as single lines: the level when
+// it isn't info, the subsystem, the message and then any attributes.
+type mirrorHandler struct {
+	next   slog.Handler
+	attrs  []slog.Attr
+	groups string
+}
+
+func (h *mirrorHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= slog.LevelInfo || h.next.Enabled(ctx, level)
+}
+
+func (h *mirrorHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelInfo {
+		if b := openedBackend(); b != nil {
+			b.Log(h.format(r))
+		}
+	}
+
+	if !h.next.Enabled(ctx, r.Level) {
+		return nil
+	}
+
+	return h.next.Handle(ctx, r)
+}
+
+func (h *mirrorHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	m := *h
+	m.next = h.next.WithAttrs(attrs)
+	m.attrs = append(h.attrs[:len(h.attrs):len(h.attrs)], h.prefixed(attrs)...)
+	return &m
+}
+
+func (h *mirrorHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	m := *h
+	m.next = h.next.WithGroup(name)
+	m.groups = h.groups + name + "."
+	return &m
+}
+
+func (h *mirrorHandler) prefixed(attrs []slog.Attr) []slog.Attr {
+	if h.groups == "" {
+		return attrs
+	}
+
+	p := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		p[i] = slog.Attr{Key: h.groups + a.Key, Value: a.Value}
+	}
+
+	return p
+}
+
+func (h *mirrorHandler) format(r slog.Record) string {
+	var sb strings.Builder
+
+	if r.Level != slog.LevelInfo {
+		sb.WriteString(r.Level.String() + ": ")
+	}
+
+	var rest []slog.Attr
+	for _, a := range h.attrs {
+		if a.Key == LogSubsystemKey {
+			sb.WriteString(a.Value.String() + ": ")
+		} else {
+			rest = append(rest, a)
+		}
+	}
+
+	sb.WriteString(r.Message)
+
+	r.Attrs(func(a slog.Attr) bool {
+		rest = append(rest, h.prefixed([]slog.Attr{a})...)
+		return true
+	})
+	for _, a := range rest {
+		fmt.Fprintf(&sb, " %s=%v", a.Key, a.Value.Resolve())
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,201 @@
+// Package loop runs a game's simulation at a fixed timestep from HGE's frame
+// and render functions.
+//
+// Each frame the time since the last one is added to an accumulator and the
+// update function is called once for every whole step in it. Whatever is left
+// over is passed to the render function as alpha, how far between the last
+// two updates the frame falls, so it can interpolate.
+package loop
+
+import (
+	"math"
+
+	"github.com/losinggeneration/hge"
+	"github.com/losinggeneration/hge/timer"
+)
+
+// The defaults New uses.
+const (
+	DefaultStep     = 1.0 / 60.0
+	DefaultMaxSteps = 5
+)
+
+// Called once for every fixed step with the step length. Returning true stops
+// the loop, the same as a frame function returning true.
+type UpdateFunc func(dt float64) bool
+
+// Called once a frame with alpha, between 0 and 1.
+type RenderFunc func(alpha float64)
+
+type Loop struct {
+	// The length of a step in seconds
+	Step float64
+	// The most updates run in a frame. Time the simulation can't catch up on
+	// is dropped so a slow update doesn't fall further behind every frame.
+	MaxSteps int
+	// How fast simulated time passes, 1 is real time
+	TimeScale float64
+
+	Update UpdateFunc
+	Render RenderFunc
+	// If set, called once a frame with the real frame delta before any
+	// updates. It's still called while the loop is paused, so input can
+	// unpause it. Returning true stops the loop.
+	Frame func(delta float64) bool
+
+	accumulator float64
+	time        float64
+	steps       uint64
+	dropped     float64
+	paused      bool
+}
+
+// Creates a loop running update every step seconds. A step of 0 or less
+// uses DefaultStep.
+func New(step float64, update UpdateFunc, render RenderFunc) *Loop {
+	if step <= 0 {
+		step = DefaultStep
+	}
+
+	return &Loop{
+		Step:      step,
+		MaxSteps:  DefaultMaxSteps,
+		TimeScale: 1,
+		Update:    update,
+		Render:    render,
+	}
+}
+
+// Stops simulated time. Render is still called with the same alpha.
+func (l *Loop) Pause() {
+	l.paused = true
+}
+
+func (l *Loop) Resume() {
+	l.paused = false
+}
+
+func (l *Loop) Paused() bool {
+	return l.paused
+}
+
+// Sets how fast simulated time passes, 0.5 is half speed. Negative scales
+// are treated as 0.
+func (l *Loop) SetTimeScale(scale float64) {
+	if scale < 0 {
+		scale = 0
+	}
+
+	l.TimeScale = scale
+}
+
+// Moves the loop on by delta seconds of real time, running as many updates
+// as fit. Returns true if Frame or Update asked to stop.
+func (l *Loop) Advance(delta float64) bool {
+	if l.Frame != nil && l.Frame(delta) {
+		return true
+	}
+
+	if l.paused || delta <= 0 || l.Step <= 0 {
+		return false
+	}
+
+	scale := l.TimeScale
+	if scale < 0 {
+		scale = 0
+	}
+	l.accumulator += delta * scale
+
+	for n := 0; l.accumulator >= l.Step; n++ {
+		if l.MaxSteps > 0 && n >= l.MaxSteps {
+			// Spiral of death, drop the whole steps left over
+			rem := math.Mod(l.accumulator, l.Step)
+			l.dropped += l.accumulator - rem
+			l.accumulator = rem
+			break
+		}
+
+		l.accumulator -= l.Step
+		l.time += l.Step
+		l.steps++
+
+		if l.Update != nil && l.Update(l.Step) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns how far between the last update and the next one the current frame
+// is, from 0 to 1.
+func (l *Loop) Alpha() float64 {
+	if l.Step <= 0 {
+		return 0
+	}
+
+	a := l.accumulator / l.Step
+	if a > 1 {
+		a = 1
+	}
+
+	return a
+}
+
+// Returns the simulated time in seconds, the number of steps run times the
+// step length.
+func (l *Loop) Time() float64 {
+	return l.time
+}
+
+// Returns the number of updates run so far.
+func (l *Loop) Steps() uint64 {
+	return l.steps
+}
+
+// Returns the simulated seconds thrown away because a frame needed more than
+// MaxSteps updates.
+func (l *Loop) Dropped() float64 {
+	return l.dropped
+}
+
+// Clears the accumulator and counters, for example after loading a level so
+// the time spent loading isn't simulated.
+func (l *Loop) Reset() {
+	l.accumulator = 0
+	l.time = 0
+	l.steps = 0
+	l.dropped = 0
+}
+
+// A frame function that advances the loop by timer.Delta().
+func (l *Loop) FrameFunc() int {
+	if l.Advance(timer.Delta()) {
+		return 1
+	}
+
+	return 0
+}
+
+// A render function that calls Render with the current alpha.
+func (l *Loop) RenderFunc() int {
+	if l.Render != nil {
+		l.Render(l.Alpha())
+	}
+
+	return 0
+}
+
+// Returns the options that make this loop the frame and render functions, to
+// pass to HGE.Initiate.
+func (l *Loop) Options() []hge.Option {
+	return []hge.Option{
+		hge.WithFrameFunc(l.FrameFunc),
+		hge.WithRenderFunc(l.RenderFunc),
+	}
+}
+
+// Sets FRAMEFUNC and RENDERFUNC to this loop.
+func (l *Loop) Install(h *hge.HGE) error {
+	return h.Configure(hge.NewConfig(l.Options()...))
+}
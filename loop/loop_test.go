@@ -0,0 +1,179 @@
+package loop
+
+import (
+	"math"
+	"testing"
+
+	"github.com/losinggeneration/hge/hgetest"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestAdvance(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxSteps  int
+		scale     float64
+		deltas    []float64
+		stepsEach []uint64 // the steps run after each delta
+		alpha     float64
+		dropped   float64
+	}{
+		{"under a step", 5, 1, []float64{0.1}, []uint64{0}, 0.4, 0},
+		{"a step at a time", 5, 1, []float64{0.1, 0.2, 0.3}, []uint64{0, 1, 2}, 0.4, 0},
+		{"several steps", 5, 1, []float64{0.8}, []uint64{3}, 0.2, 0},
+		{"clamped", 5, 1, []float64{10.1}, []uint64{5}, 0.4, 8.75},
+		{"clamped and caught up", 5, 1, []float64{10.1, 0.1}, []uint64{5, 5}, 0.8, 8.75},
+		{"no limit", 0, 1, []float64{10.1}, []uint64{40}, 0.4, 0},
+		{"half speed", 5, 0.5, []float64{0.5, 0.2}, []uint64{1, 1}, 0.4, 0},
+		{"stopped", 5, 0, []float64{1, 1}, []uint64{0, 0}, 0, 0},
+		{"no time", 5, 1, []float64{0, -1}, []uint64{0, 0}, 0, 0},
+	}
+
+	for _, test := range tests {
+		steps := test.stepsEach[len(test.stepsEach)-1]
+		var updates []float64
+		l := New(0.25, func(dt float64) bool {
+			updates = append(updates, dt)
+			return false
+		}, nil)
+		l.MaxSteps = test.maxSteps
+		l.SetTimeScale(test.scale)
+
+		for i, d := range test.deltas {
+			if l.Advance(d) {
+				t.Errorf("%s: stopped after %v", test.name, d)
+			}
+			if l.Steps() != test.stepsEach[i] {
+				t.Errorf("%s: %d steps after %v, want %d", test.name, l.Steps(), test.deltas[:i+1], test.stepsEach[i])
+			}
+		}
+
+		if uint64(len(updates)) != steps {
+			t.Errorf("%s: %d updates, want %d", test.name, len(updates), steps)
+		}
+		for _, dt := range updates {
+			if dt != 0.25 {
+				t.Errorf("%s: updated with %v, not the step", test.name, dt)
+			}
+		}
+		if !near(l.Time(), float64(steps)*0.25) {
+			t.Errorf("%s: simulated %v seconds, want %v", test.name, l.Time(), float64(steps)*0.25)
+		}
+		if !near(l.Alpha(), test.alpha) {
+			t.Errorf("%s: alpha %v, want %v", test.name, l.Alpha(), test.alpha)
+		}
+		if !near(l.Dropped(), test.dropped) {
+			t.Errorf("%s: dropped %v seconds, want %v", test.name, l.Dropped(), test.dropped)
+		}
+	}
+}
+
+func TestAdvanceStops(t *testing.T) {
+	updates := 0
+	l := New(0.25, func(float64) bool {
+		updates++
+		return updates == 2
+	}, nil)
+
+	// The steps after the one that stops aren't run
+	if !l.Advance(1) {
+		t.Error("Advance didn't stop when Update asked it to")
+	}
+	if updates != 2 || l.Steps() != 2 {
+		t.Errorf("%d updates and %d steps, want 2", updates, l.Steps())
+	}
+
+	var frames []float64
+	stop := false
+	l.Frame = func(delta float64) bool {
+		frames = append(frames, delta)
+		return stop
+	}
+
+	// Frame is still called while paused, but nothing's simulated
+	l.Pause()
+	if l.Advance(1) || !l.Paused() {
+		t.Error("a paused loop stopped or isn't paused")
+	}
+	if l.Steps() != 2 || len(frames) != 1 || frames[0] != 1 {
+		t.Errorf("paused, %d steps and frames %v, want 2 steps and frames [1]", l.Steps(), frames)
+	}
+	l.Resume()
+
+	// Frame stopping comes before any update
+	stop = true
+	if !l.Advance(1) {
+		t.Error("Advance didn't stop when Frame asked it to")
+	}
+	if updates != 2 {
+		t.Errorf("%d updates after Frame stopped, want 2", updates)
+	}
+
+	l.Reset()
+	if l.Steps() != 0 || l.Time() != 0 || l.Alpha() != 0 || l.Dropped() != 0 {
+		t.Errorf("after Reset %d steps, %v seconds, alpha %v, %v dropped", l.Steps(), l.Time(), l.Alpha(), l.Dropped())
+	}
+}
+
+func TestAlpha(t *testing.T) {
+	l := New(0, nil, nil)
+	if l.Step != DefaultStep || l.MaxSteps != DefaultMaxSteps || l.TimeScale != 1 {
+		t.Errorf("New made %+v", l)
+	}
+
+	l.Step = 0.25
+	l.Advance(0.2)
+
+	// A shorter step can leave more than a step waiting until the next frame
+	l.Step = 0.1
+	if a := l.Alpha(); a != 1 {
+		t.Errorf("alpha %v with two steps waiting, want 1", a)
+	}
+
+	l.Step = 0
+	if a := l.Alpha(); a != 0 {
+		t.Errorf("alpha %v with no step, want 0", a)
+	}
+	if l.Advance(1) || l.Steps() != 0 {
+		t.Error("a loop without a step ran")
+	}
+}
+
+func TestFrameFunc(t *testing.T) {
+	var alphas []float64
+	l := New(0.25, nil, func(alpha float64) { alphas = append(alphas, alpha) })
+
+	// The loop is run from HGE's frame and render functions, advancing by
+	// the frame delta
+	_, err := hgetest.Render(hgetest.Scene{
+		Width: 8, Height: 8,
+		Frames: 5,
+		Delta:  0.1,
+		Frame: func(int) {
+			if l.FrameFunc() != 0 {
+				t.Error("the frame function stopped")
+			}
+		},
+		Render: func(int) { l.RenderFunc() },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []float64{0.4, 0.8, 0.2, 0.6, 0}
+	if len(alphas) != len(want) {
+		t.Fatalf("rendered with %v, want %v", alphas, want)
+	}
+	for i := range want {
+		if math.Abs(alphas[i]-want[i]) > 1e-6 {
+			t.Errorf("rendered with %v, want %v", alphas, want)
+			break
+		}
+	}
+	if l.Steps() != 2 {
+		t.Errorf("%d steps in half a second, want 2", l.Steps())
+	}
+}
@@ -0,0 +1,286 @@
+package postfx
+
+import (
+	"math"
+
+	"github.com/losinggeneration/hge"
+	"github.com/losinggeneration/hge/gfx"
+	"github.com/losinggeneration/hge/helpers/distortionmesh"
+)
+
+const blendAdd = gfx.BLEND_COLORMUL | gfx.BLEND_ALPHAADD | gfx.BLEND_NOZWRITE
+
+func withAlpha(col hge.Dword, a float64) hge.Dword {
+	a = math.Max(0, math.Min(1, a))
+	return col&0x00FFFFFF | hge.Dword(a*float64(col>>24))<<24
+}
+
+// Draws src four times, each a texel of src off in a different direction,
+// and averages them. Later draws are more transparent so each ends up
+// weighing the same.
+func taps(c *Chain, src *gfx.Target, w, h float64) {
+	sw, sh := c.Size(src)
+	ox, oy := w/float64(sw), h/float64(sh)
+
+	offsets := [4][2]float64{{-ox, -oy}, {ox, -oy}, {ox, oy}, {-ox, oy}}
+	for i, o := range offsets {
+		c.Draw(src, o[0], o[1], w, h, withAlpha(0xFFFFFFFF, 1/float64(i+1)), gfx.BLEND_DEFAULT)
+	}
+}
+
+// Shrinks src by half levels times, blurring as it goes, and returns the
+// smallest.
+func downsample(c *Chain, owner interface{}, src *gfx.Target, levels int) *gfx.Target {
+	w, h := c.Size(src)
+	for i := 0; i < levels; i++ {
+		w, h = max(1, w/2), max(1, h/2)
+
+		t := c.Target(owner, i, w, h)
+		if t == nil {
+			break
+		}
+
+		if c.Begin(t) {
+			taps(c, src, float64(w), float64(h))
+			c.End(t)
+		}
+		src = t
+	}
+
+	return src
+}
+
+// Blurs the image by shrinking it by half Levels times and stretching it
+// back. Each level doubles how far the blur spreads.
+type Blur struct {
+	Levels int
+}
+
+func NewBlur(levels int) *Blur {
+	return &Blur{levels}
+}
+
+func (b *Blur) Render(c *Chain, src, dst *gfx.Target) {
+	small := downsample(c, b, src, b.Levels)
+
+	w, h := c.Size(src)
+	if c.Begin(dst) {
+		taps(c, small, float64(w), float64(h))
+		c.End(dst)
+	}
+}
+
+// Makes bright parts of the image glow by adding a blurred copy on top.
+// There's no way to pick out only the bright parts without shaders, so the
+// whole image glows. A darker Color keeps dim parts from glowing much, and
+// its alpha sets how strong the glow is.
+type Bloom struct {
+	Levels int
+	Color  hge.Dword
+}
+
+func NewBloom(levels int, color hge.Dword) *Bloom {
+	return &Bloom{levels, color}
+}
+
+func (b *Bloom) Render(c *Chain, src, dst *gfx.Target) {
+	small := downsample(c, b, src, b.Levels)
+
+	w, h := c.Size(src)
+	if c.Begin(dst) {
+		c.Draw(src, 0, 0, float64(w), float64(h), 0xFFFFFFFF, gfx.BLEND_DEFAULT)
+		c.Draw(small, 0, 0, float64(w), float64(h), b.Color, blendAdd)
+		c.End(dst)
+	}
+}
+
+// Multiplies the image's colors by Multiply, then adds Add scaled by its
+// alpha. Multiplying by 0xFFFFFFFF and adding nothing leaves it alone.
+type ColorGrade struct {
+	Multiply hge.Dword
+	Add      hge.Dword
+}
+
+func NewColorGrade(multiply, add hge.Dword) *ColorGrade {
+	return &ColorGrade{multiply, add}
+}
+
+func (g *ColorGrade) Render(c *Chain, src, dst *gfx.Target) {
+	w, h := c.Size(src)
+	if c.Begin(dst) {
+		c.Draw(src, 0, 0, float64(w), float64(h), g.Multiply|0xFF000000, gfx.BLEND_DEFAULT)
+		if g.Add&0x00FFFFFF != 0 && g.Add>>24 != 0 {
+			fill(0, 0, float64(w), float64(h), g.Add, blendAdd)
+		}
+		c.End(dst)
+	}
+}
+
+// A mesh covering the screen, made again when the size changes.
+type screenMesh struct {
+	dm   distortionmesh.DistortionMesh
+	w, h int
+	ok   bool
+}
+
+func (m *screenMesh) get(cols, rows, w, h int) *distortionmesh.DistortionMesh {
+	if !m.ok || m.w != w || m.h != h || m.dm.Cols() != cols || m.dm.Rows() != rows {
+		m.dm = distortionmesh.New(cols, rows)
+		m.dm.SetBlendMode(gfx.BLEND_DEFAULT)
+		m.w, m.h, m.ok = w, h, true
+	}
+
+	return &m.dm
+}
+
+// Maps the texture of src over the mesh.
+func (m *screenMesh) texture(c *Chain, src *gfx.Target) {
+	sw, sh := c.Size(src)
+	m.dm.SetTexture(src.Texture())
+	m.dm.SetTextureRect(0, 0, float64(sw), float64(sh))
+}
+
+// Darkens the image towards Color around the edges. Radius is how far out
+// from the middle the darkening starts, as a fraction of the distance to
+// the corners.
+type Vignette struct {
+	Color  hge.Dword
+	Radius float64
+
+	mesh screenMesh
+}
+
+func NewVignette(color hge.Dword, radius float64) *Vignette {
+	return &Vignette{Color: color, Radius: radius}
+}
+
+func (v *Vignette) Render(c *Chain, src, dst *gfx.Target) {
+	const cells = 16
+
+	w, h := c.Size(src)
+	dm := v.mesh.get(cells+1, cells+1, w, h)
+	dm.SetTextureRect(0, 0, float64(w), float64(h))
+
+	for row := 0; row <= cells; row++ {
+		for col := 0; col <= cells; col++ {
+			dx, dy := 2*float64(col)/cells-1, 2*float64(row)/cells-1
+			d := math.Hypot(dx, dy) / math.Sqrt2
+
+			t := 1.0
+			if v.Radius < 1 {
+				t = (d - v.Radius) / (1 - v.Radius)
+			}
+			t = math.Max(0, math.Min(1, t))
+			dm.SetColor(col, row, withAlpha(v.Color, t*t))
+		}
+	}
+
+	if c.Begin(dst) {
+		c.Draw(src, 0, 0, float64(w), float64(h), 0xFFFFFFFF, gfx.BLEND_DEFAULT)
+		dm.Render(0, 0)
+		c.End(dst)
+	}
+}
+
+// Makes the image look like an old monitor, bulging out and darkening every
+// other row. Curvature is how much it bulges and Scanlines how dark the
+// rows get, from 0 to 1.
+type CRT struct {
+	Curvature float64
+	Scanlines float64
+
+	mesh screenMesh
+}
+
+func NewCRT(curvature, scanlines float64) *CRT {
+	return &CRT{Curvature: curvature, Scanlines: scanlines}
+}
+
+func (crt *CRT) Render(c *Chain, src, dst *gfx.Target) {
+	const cells = 24
+
+	w, h := c.Size(src)
+	dm := crt.mesh.get(cells+1, cells+1, w, h)
+	crt.mesh.texture(c, src)
+
+	// Corners are pulled in more than the middle of the edges, so the edges
+	// bow out
+	hw, hh := float64(w)/2, float64(h)/2
+	for row := 0; row <= cells; row++ {
+		for col := 0; col <= cells; col++ {
+			u, v := 2*float64(col)/cells-1, 2*float64(row)/cells-1
+			k := 1 / (1 + crt.Curvature*(u*u+v*v))
+			dm.SetDisplacement(col, row, u*hw*k, v*hh*k, distortionmesh.DISP_CENTER)
+		}
+	}
+
+	if c.Begin(dst) {
+		dm.Render(0, 0)
+
+		if crt.Scanlines > 0 {
+			col := withAlpha(0xFF000000, crt.Scanlines)
+			for y := 1; y < h; y += 2 {
+				fill(0, float64(y), float64(w), 1, col, gfx.BLEND_DEFAULT)
+			}
+		}
+		c.End(dst)
+	}
+}
+
+// Sends ripples out across the image from X, Y, like a stone dropped in
+// water. Amplitude is how far in pixels the image moves, Wavelength the
+// distance between ripples and Speed how fast they spread in pixels a
+// second.
+type Ripple struct {
+	X, Y       float64
+	Amplitude  float64
+	Wavelength float64
+	Speed      float64
+	// Seconds since the ripple started, moved on by Update
+	Time float64
+
+	mesh screenMesh
+}
+
+func NewRipple(x, y, amplitude, wavelength, speed float64) *Ripple {
+	return &Ripple{X: x, Y: y, Amplitude: amplitude, Wavelength: wavelength, Speed: speed}
+}
+
+// Moves the ripples on by dt seconds.
+func (r *Ripple) Update(dt float64) {
+	r.Time += dt
+}
+
+func (r *Ripple) Render(c *Chain, src, dst *gfx.Target) {
+	const cells = 32
+
+	w, h := c.Size(src)
+	dm := r.mesh.get(cells+1, cells+1, w, h)
+	r.mesh.texture(c, src)
+
+	cw, ch := float64(w)/cells, float64(h)/cells
+	for row := 0; row <= cells; row++ {
+		for col := 0; col <= cells; col++ {
+			// The edges stay put so no gaps open up there
+			if row == 0 || col == 0 || row == cells || col == cells || r.Wavelength <= 0 {
+				dm.SetDisplacement(col, row, 0, 0, distortionmesh.DISP_NODE)
+				continue
+			}
+
+			dx, dy := float64(col)*cw-r.X, float64(row)*ch-r.Y
+			d := math.Hypot(dx, dy)
+			if d == 0 || (r.Speed > 0 && d > r.Speed*r.Time) {
+				dm.SetDisplacement(col, row, 0, 0, distortionmesh.DISP_NODE)
+				continue
+			}
+
+			s := r.Amplitude * math.Sin(2*math.Pi*(d-r.Speed*r.Time)/r.Wavelength)
+			dm.SetDisplacement(col, row, dx/d*s, dy/d*s, distortionmesh.DISP_NODE)
+		}
+	}
+
+	if c.Begin(dst) {
+		dm.Render(0, 0)
+		c.End(dst)
+	}
+}
@@ -0,0 +1,224 @@
+// Package postfx draws a scene into a render target and runs it through a
+// chain of passes on its way to the screen.
+//
+// Passes only draw quads and distortion meshes with the usual blend modes,
+// so they work the same on any backend with render targets. A chain makes
+// the targets its passes need and makes them again when the graphics device
+// is restored.
+package postfx
+
+import (
+	"github.com/losinggeneration/hge"
+	"github.com/losinggeneration/hge/gfx"
+)
+
+var postfxHGE *hge.HGE
+
+func init() {
+	postfxHGE = hge.New()
+}
+
+func screenSize() (w, h int) {
+	return postfxHGE.GetInt(hge.SCREENWIDTH), postfxHGE.GetInt(hge.SCREENHEIGHT)
+}
+
+// A Pass draws what's in src, after any earlier passes, to dst. A nil dst is
+// the screen. Passes begin and end the scene on dst themselves with
+// Chain.Begin and Chain.End, so they can draw to targets of their own first.
+type Pass interface {
+	Render(c *Chain, src, dst *gfx.Target)
+}
+
+// A Chain of passes the scene is drawn through.
+type Chain struct {
+	Passes []Pass
+
+	targets map[targetKey]*target
+}
+
+type targetKey struct {
+	owner interface{}
+	i     int
+}
+
+type target struct {
+	t    *gfx.Target
+	w, h int
+}
+
+// Creates a chain running passes in order.
+func New(passes ...Pass) *Chain {
+	return &Chain{Passes: passes, targets: make(map[targetKey]*target)}
+}
+
+// Adds a pass to the end of the chain.
+func (c *Chain) Add(p Pass) {
+	c.Passes = append(c.Passes, p)
+}
+
+// Draws the scene through the passes to the screen. The scene is drawn
+// between BeginScene and EndScene, the same as a render function, and
+// should clear the target first. Without passes, or when targets can't be
+// made, it's drawn straight to the screen.
+func (c *Chain) Render(scene func()) {
+	w, h := screenSize()
+
+	var src *gfx.Target
+	if len(c.Passes) > 0 {
+		src = c.Target(c, 0, w, h)
+	}
+
+	if src == nil {
+		if gfx.BeginScene() {
+			scene()
+			gfx.EndScene()
+		}
+		return
+	}
+
+	if gfx.BeginScene(src) {
+		scene()
+		c.End(src)
+	}
+
+	for i, p := range c.Passes {
+		var dst *gfx.Target
+		if i < len(c.Passes)-1 {
+			// Passes take turns drawing to two targets
+			dst = c.Target(c, 1+i%2, w, h)
+		}
+
+		p.Render(c, src, dst)
+		if dst == nil {
+			return
+		}
+		src = dst
+	}
+}
+
+// Returns a target w by h for the owner to draw to, made the first time
+// it's asked for and kept until the size changes or the chain is restored
+// or freed. An owner can have as many targets as it likes, told apart by i.
+// Returns nil if the target can't be made.
+func (c *Chain) Target(owner interface{}, i, w, h int) *gfx.Target {
+	if c.targets == nil {
+		c.targets = make(map[targetKey]*target)
+	}
+
+	key := targetKey{owner, i}
+	if t, ok := c.targets[key]; ok {
+		if t.w == w && t.h == h {
+			return t.t
+		}
+		t.t.Free()
+		delete(c.targets, key)
+	}
+
+	t := gfx.NewTarget(w, h, false)
+	if t == nil {
+		hge.Logger(hge.LogGfx).Warn("postfx: can't create target", "width", w, "height", h)
+		return nil
+	}
+	c.targets[key] = &target{t, w, h}
+
+	return t
+}
+
+// Returns the size of what's drawn to a target made by the chain. The
+// target's texture can be bigger than this.
+func (c *Chain) Size(t *gfx.Target) (w, h int) {
+	for _, ct := range c.targets {
+		if ct.t == t {
+			return ct.w, ct.h
+		}
+	}
+
+	return screenSize()
+}
+
+// Draws the contents of src stretched over x, y, w, h.
+func (c *Chain) Draw(src *gfx.Target, x, y, w, h float64, col hge.Dword, blend int) {
+	tex := src.Texture()
+	sw, sh := c.Size(src)
+	tx := float32(float64(sw) / float64(tex.Width()))
+	ty := float32(float64(sh) / float64(tex.Height()))
+
+	q := gfx.Quad{Texture: tex, Blend: blend}
+	q.V[0] = gfx.Vertex{X: float32(x), Y: float32(y), Z: 0.5, Color: col, TX: 0, TY: 0}
+	q.V[1] = gfx.Vertex{X: float32(x + w), Y: float32(y), Z: 0.5, Color: col, TX: tx, TY: 0}
+	q.V[2] = gfx.Vertex{X: float32(x + w), Y: float32(y + h), Z: 0.5, Color: col, TX: tx, TY: ty}
+	q.V[3] = gfx.Vertex{X: float32(x), Y: float32(y + h), Z: 0.5, Color: col, TX: 0, TY: ty}
+	q.Render()
+}
+
+// Begins a scene on dst, or the screen when it's nil, and clears it to
+// black.
+func (c *Chain) Begin(dst *gfx.Target) bool {
+	var ok bool
+	if dst == nil {
+		ok = gfx.BeginScene()
+	} else {
+		ok = gfx.BeginScene(dst)
+	}
+
+	if ok {
+		gfx.Clear(0xFF000000)
+	}
+
+	return ok
+}
+
+// Ends the scene begun with Begin.
+//
+// Translucent drawing leaves a target's alpha below opaque, which would show
+// when the target is drawn, so an opaque black quad is added over it first.
+// That fills the alpha back in without changing the colors.
+func (c *Chain) End(dst *gfx.Target) {
+	if dst != nil {
+		w, h := c.Size(dst)
+		fill(0, 0, float64(w), float64(h), 0xFF000000, blendAdd)
+	}
+
+	gfx.EndScene()
+}
+
+// Draws an untextured quad.
+func fill(x, y, w, h float64, col hge.Dword, blend int) {
+	q := gfx.Quad{Blend: blend}
+	q.V[0] = gfx.Vertex{X: float32(x), Y: float32(y), Z: 0.5, Color: col}
+	q.V[1] = gfx.Vertex{X: float32(x + w), Y: float32(y), Z: 0.5, Color: col}
+	q.V[2] = gfx.Vertex{X: float32(x + w), Y: float32(y + h), Z: 0.5, Color: col}
+	q.V[3] = gfx.Vertex{X: float32(x), Y: float32(y + h), Z: 0.5, Color: col}
+	q.Render()
+}
+
+// Frees the chain's targets. They're made again the next time it renders.
+func (c *Chain) Free() {
+	for _, t := range c.targets {
+		t.t.Free()
+	}
+	c.targets = make(map[targetKey]*target)
+}
+
+// Makes the targets again after the graphics device is lost, when their
+// contents and possibly the screen size have gone.
+func (c *Chain) Restore() {
+	c.Free()
+}
+
+func (c *Chain) RestoreFunc() int {
+	c.Restore()
+	return 0
+}
+
+// Returns an option setting the gfx restore function to the chain's.
+func (c *Chain) Options() []hge.Option {
+	return []hge.Option{
+		hge.WithFunc(hge.GFXRESTOREFUNC, c.RestoreFunc),
+	}
+}
+
+// Sets the gfx restore function to the chain's.
+func (c *Chain) Install(h *hge.HGE) error {
+	return h.Configure(hge.NewConfig(c.Options()...))
+}
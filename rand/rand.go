@@ -1,11 +1,5 @@
 package rand
 
-/*
-#cgo pkg-config: hge-unix-c
-#include "hge_c.h"
-*/
-import "C"
-
 import "github.com/losinggeneration/hge"
 
 func Seed(a ...interface{}) {
@@ -44,17 +38,17 @@ func New(seed int) *Rand {
 }
 
 func (r *Rand) Seed() {
-	C.HGE_Random_Seed(r.randHGE.HGE, C.int(r.seed))
+	r.randHGE.Backend().RandomSeed(r.seed)
 }
 
 func (r *Rand) Int(min, max int) int {
-	return int(C.HGE_Random_Int(r.randHGE.HGE, C.int(min), C.int(max)))
+	return r.randHGE.Backend().RandomInt(min, max)
 }
 
 func (r *Rand) Float32(min, max float32) float32 {
-	return float32(C.HGE_Random_Float(r.randHGE.HGE, C.float(min), C.float(max)))
+	return float32(r.randHGE.Backend().RandomFloat(float64(min), float64(max)))
 }
 
 func (r *Rand) Float64(min, max float64) float64 {
-	return float64(C.HGE_Random_Float(r.randHGE.HGE, C.float(min), C.float(max)))
+	return r.randHGE.Backend().RandomFloat(min, max)
 }
@@ -0,0 +1,409 @@
+package hge
+
+import (
+	"image"
+	"math"
+)
+
+// Blend flags and primitive types the rasterizer needs to know about. These
+// mirror the constants in the gfx package.
+const (
+	blendColorAdd   = 1
+	blendAlphaBlend = 2
+	blendZWrite     = 4
+
+	primLines = 2
+)
+
+// Rasterizer is a software version of HGE's renderer. It draws lines,
+// triples and quads into an *image.RGBA with HGE's blend modes, clipping
+// and transform.
+//
+// Like a Direct3D surface the channels of Dst and of any texture hold the
+// raw values the blend equations produce, they aren't premultiplied.
+type Rasterizer struct {
+	// Where everything is drawn
+	Dst *image.RGBA
+	// One depth per pixel of Dst, depth testing is only done when it's set
+	ZBuffer []float32
+	// Sample textures bilinearly instead of taking the nearest texel
+	Filter bool
+	// Dst has no alpha channel, like the screen, so alpha is written as 255
+	Opaque bool
+
+	clip    image.Rectangle
+	clipSet bool
+
+	transform bool
+	m         [6]float64
+}
+
+// A vertex after it's been transformed, with everything interpolated across
+// a primitive as float64.
+type rasterVertex struct {
+	x, y, z    float64
+	r, g, b, a float64
+	u, v       float64
+}
+
+// Creates a rasterizer drawing into dst with texture filtering on, which is
+// HGE's default.
+func NewRasterizer(dst *image.RGBA) *Rasterizer {
+	return &Rasterizer{Dst: dst, Filter: true}
+}
+
+// Removes any clipping and transform.
+func (r *Rasterizer) Reset() {
+	r.clipSet = false
+	r.transform = false
+}
+
+// Limits drawing to the given rectangle. A zero width or height draws to all
+// of Dst again.
+func (r *Rasterizer) SetClipping(x, y, w, h int) {
+	if w <= 0 || h <= 0 {
+		r.clipSet = false
+		return
+	}
+
+	r.clip = image.Rect(x, y, x+w, y+h)
+	r.clipSet = true
+}
+
+// Sets the transform the same way Gfx_SetTransform does: vertices are
+// scaled by hscale and vscale and rotated by rot around (x, y), then moved
+// by (dx, dy). A zero vscale removes the transform.
+func (r *Rasterizer) SetTransform(x, y, dx, dy, rot, hscale, vscale float64) {
+	if vscale == 0 {
+		r.transform = false
+		return
+	}
+
+	sin, cos := math.Sincos(rot)
+
+	r.m = [6]float64{
+		hscale * cos, vscale * sin, 0,
+		-hscale * sin, vscale * cos, 0,
+	}
+	r.m[2] = x + dx - x*r.m[0] - y*r.m[1]
+	r.m[5] = y + dy - x*r.m[3] - y*r.m[4]
+	r.transform = true
+}
+
+// The area that can be drawn to, in Dst's coordinates.
+func (r *Rasterizer) bounds() image.Rectangle {
+	b := r.Dst.Bounds()
+	if !r.clipSet {
+		return b
+	}
+
+	return r.clip.Add(b.Min).Intersect(b)
+}
+
+// Fills the clipping area with color and resets the depth buffer.
+func (r *Rasterizer) Clear(color Dword) {
+	if r.Dst == nil {
+		return
+	}
+
+	c := [4]uint8{uint8(color >> 16), uint8(color >> 8), uint8(color), uint8(color >> 24)}
+	if r.Opaque {
+		c[3] = 0xFF
+	}
+
+	b := r.bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		i := r.Dst.PixOffset(b.Min.X, y)
+		for x := b.Min.X; x < b.Max.X; x++ {
+			copy(r.Dst.Pix[i:i+4], c[:])
+			if r.ZBuffer != nil {
+				r.ZBuffer[r.zOffset(x, y)] = 1
+			}
+			i += 4
+		}
+	}
+}
+
+func (r *Rasterizer) vertex(v *BackendVertex) rasterVertex {
+	rv := rasterVertex{
+		x: float64(v.X), y: float64(v.Y), z: float64(v.Z),
+		r: float64(v.Color >> 16 & 0xFF),
+		g: float64(v.Color >> 8 & 0xFF),
+		b: float64(v.Color & 0xFF),
+		a: float64(v.Color >> 24),
+		u: float64(v.TX), v: float64(v.TY),
+	}
+
+	if r.transform {
+		rv.x, rv.y = r.m[0]*rv.x+r.m[1]*rv.y+r.m[2], r.m[3]*rv.x+r.m[4]*rv.y+r.m[5]
+	}
+
+	return rv
+}
+
+// Draws a line with the default blend mode and no texture, like
+// Gfx_RenderLine does.
+func (r *Rasterizer) RenderLine(x1, y1, x2, y2 float64, color Dword, z float64) {
+	v := [2]BackendVertex{
+		{X: float32(x1), Y: float32(y1), Z: float32(z), Color: color},
+		{X: float32(x2), Y: float32(y2), Z: float32(z), Color: color},
+	}
+
+	r.line(r.vertex(&v[0]), r.vertex(&v[1]), nil, blendAlphaBlend)
+}
+
+func (r *Rasterizer) RenderTriple(v *[3]BackendVertex, tex *image.RGBA, blend int) {
+	a, b, c := r.vertex(&v[0]), r.vertex(&v[1]), r.vertex(&v[2])
+	r.triangle(&a, &b, &c, tex, blend)
+}
+
+// Quads are split into two triangles the same way HGE's index buffer does.
+func (r *Rasterizer) RenderQuad(v *[4]BackendVertex, tex *image.RGBA, blend int) {
+	a, b, c, d := r.vertex(&v[0]), r.vertex(&v[1]), r.vertex(&v[2]), r.vertex(&v[3])
+	r.triangle(&a, &b, &c, tex, blend)
+	r.triangle(&c, &d, &a, tex, blend)
+}
+
+// Draws prim primitives of primType from v, which is how a batch started
+// with Gfx_StartBatch is finished.
+func (r *Rasterizer) RenderBatch(v []BackendVertex, primType, prim int, tex *image.RGBA, blend int) {
+	if primType <= 0 || prim*primType > len(v) {
+		return
+	}
+
+	for i := 0; i < prim; i++ {
+		p := v[i*primType : (i+1)*primType]
+
+		switch primType {
+		case primLines:
+			r.line(r.vertex(&p[0]), r.vertex(&p[1]), tex, blend)
+		case 3:
+			r.RenderTriple((*[3]BackendVertex)(p), tex, blend)
+		case 4:
+			r.RenderQuad((*[4]BackendVertex)(p), tex, blend)
+		}
+	}
+}
+
+// Steps along the major axis one pixel at a time, leaving out the last pixel
+// the way Direct3D does.
+func (r *Rasterizer) line(a, b rasterVertex, tex *image.RGBA, blend int) {
+	if r.Dst == nil {
+		return
+	}
+
+	dx, dy := b.x-a.x, b.y-a.y
+	n := int(math.Ceil(math.Max(math.Abs(dx), math.Abs(dy))))
+	if n == 0 {
+		return
+	}
+
+	for i := 0; i < n; i++ {
+		t := (float64(i) + 0.5) / float64(n)
+		p := lerpVertex(&a, &b, t)
+		r.plot(int(math.Floor(p.x)), int(math.Floor(p.y)), &p, tex, blend)
+	}
+}
+
+// Pixels are covered when their centre is inside the triangle. Pixels right
+// on an edge go to only one of the triangles sharing it, so quads don't blend
+// their diagonal twice.
+func (r *Rasterizer) triangle(a, b, c *rasterVertex, tex *image.RGBA, blend int) {
+	if r.Dst == nil {
+		return
+	}
+
+	area := edge(a, b, c.x, c.y)
+	if area == 0 {
+		return
+	}
+	// HGE doesn't cull, so both windings are drawn
+	if area < 0 {
+		b, c = c, b
+		area = -area
+	}
+
+	bounds := r.bounds()
+	box := image.Rect(
+		int(math.Floor(math.Min(a.x, math.Min(b.x, c.x)))),
+		int(math.Floor(math.Min(a.y, math.Min(b.y, c.y)))),
+		int(math.Ceil(math.Max(a.x, math.Max(b.x, c.x))))+1,
+		int(math.Ceil(math.Max(a.y, math.Max(b.y, c.y))))+1,
+	).Add(r.Dst.Bounds().Min).Intersect(bounds)
+
+	min := r.Dst.Bounds().Min
+	for y := box.Min.Y; y < box.Max.Y; y++ {
+		py := float64(y-min.Y) + 0.5
+
+		for x := box.Min.X; x < box.Max.X; x++ {
+			px := float64(x-min.X) + 0.5
+
+			w0 := edge(b, c, px, py)
+			w1 := edge(c, a, px, py)
+			w2 := edge(a, b, px, py)
+			if !covers(w0, b, c) || !covers(w1, c, a) || !covers(w2, a, b) {
+				continue
+			}
+
+			l0, l1, l2 := w0/area, w1/area, w2/area
+			p := rasterVertex{
+				z: l0*a.z + l1*b.z + l2*c.z,
+				r: l0*a.r + l1*b.r + l2*c.r,
+				g: l0*a.g + l1*b.g + l2*c.g,
+				b: l0*a.b + l1*b.b + l2*c.b,
+				a: l0*a.a + l1*b.a + l2*c.a,
+				u: l0*a.u + l1*b.u + l2*c.u,
+				v: l0*a.v + l1*b.v + l2*c.v,
+			}
+
+			r.plot(x-min.X, y-min.Y, &p, tex, blend)
+		}
+	}
+}
+
+func edge(a, b *rasterVertex, x, y float64) float64 {
+	return (b.x-a.x)*(y-a.y) - (b.y-a.y)*(x-a.x)
+}
+
+// A pixel exactly on an edge only counts for one direction of the edge, so
+// of two triangles sharing it only one draws the pixel.
+func covers(w float64, a, b *rasterVertex) bool {
+	if w != 0 {
+		return w > 0
+	}
+
+	dx, dy := b.x-a.x, b.y-a.y
+	return dy > 0 || (dy == 0 && dx < 0)
+}
+
+func lerpVertex(a, b *rasterVertex, t float64) rasterVertex {
+	return rasterVertex{
+		x: a.x + (b.x-a.x)*t,
+		y: a.y + (b.y-a.y)*t,
+		z: a.z + (b.z-a.z)*t,
+		r: a.r + (b.r-a.r)*t,
+		g: a.g + (b.g-a.g)*t,
+		b: a.b + (b.b-a.b)*t,
+		a: a.a + (b.a-a.a)*t,
+		u: a.u + (b.u-a.u)*t,
+		v: a.v + (b.v-a.v)*t,
+	}
+}
+
+func (r *Rasterizer) zOffset(x, y int) int {
+	b := r.Dst.Bounds()
+	return (y-b.Min.Y)*b.Dx() + (x - b.Min.X)
+}
+
+// Shades and blends a single pixel, x and y are relative to Dst's origin.
+func (r *Rasterizer) plot(x, y int, p *rasterVertex, tex *image.RGBA, blend int) {
+	min := r.Dst.Bounds().Min
+	x, y = x+min.X, y+min.Y
+	if !(image.Point{x, y}).In(r.bounds()) {
+		return
+	}
+
+	if r.ZBuffer != nil {
+		i := r.zOffset(x, y)
+		z := float32(p.z)
+		if z > r.ZBuffer[i] {
+			return
+		}
+		if blend&blendZWrite != 0 {
+			r.ZBuffer[i] = z
+		}
+	}
+
+	src := [4]float64{p.r, p.g, p.b, p.a}
+	if tex != nil {
+		t := sample(tex, p.u, p.v, r.Filter)
+		for i := 0; i < 3; i++ {
+			if blend&blendColorAdd != 0 {
+				src[i] = math.Min(t[i]+src[i], 255)
+			} else {
+				src[i] = t[i] * src[i] / 255
+			}
+		}
+		src[3] = t[3] * src[3] / 255
+	}
+
+	i := r.Dst.PixOffset(x, y)
+	dst := r.Dst.Pix[i : i+4 : i+4]
+	sa := src[3] / 255
+
+	for c := 0; c < 4; c++ {
+		d := float64(dst[c])
+
+		var v float64
+		if blend&blendAlphaBlend != 0 {
+			v = src[c]*sa + d*(1-sa)
+		} else {
+			v = src[c]*sa + d
+		}
+
+		dst[c] = clampByte(v)
+	}
+
+	if r.Opaque {
+		dst[3] = 0xFF
+	}
+}
+
+func clampByte(v float64) uint8 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 255:
+		return 255
+	}
+
+	return uint8(v + 0.5)
+}
+
+// Samples tex at the texture coordinates u, v wrapping around its edges the
+// way Direct3D does by default.
+func sample(tex *image.RGBA, u, v float64, filter bool) [4]float64 {
+	b := tex.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return [4]float64{}
+	}
+
+	texel := func(x, y int) [4]float64 {
+		x, y = wrap(x, w), wrap(y, h)
+		i := tex.PixOffset(b.Min.X+x, b.Min.Y+y)
+		p := tex.Pix[i : i+4 : i+4]
+		return [4]float64{float64(p[0]), float64(p[1]), float64(p[2]), float64(p[3])}
+	}
+
+	if !filter {
+		return texel(int(math.Floor(u*float64(w))), int(math.Floor(v*float64(h))))
+	}
+
+	fx, fy := u*float64(w)-0.5, v*float64(h)-0.5
+	x0, y0 := math.Floor(fx), math.Floor(fy)
+	tx, ty := fx-x0, fy-y0
+
+	t00 := texel(int(x0), int(y0))
+	t10 := texel(int(x0)+1, int(y0))
+	t01 := texel(int(x0), int(y0)+1)
+	t11 := texel(int(x0)+1, int(y0)+1)
+
+	var c [4]float64
+	for i := range c {
+		top := t00[i] + (t10[i]-t00[i])*tx
+		bottom := t01[i] + (t11[i]-t01[i])*tx
+		c[i] = top + (bottom-top)*ty
+	}
+
+	return c
+}
+
+func wrap(i, n int) int {
+	i %= n
+	if i < 0 {
+		i += n
+	}
+
+	return i
+}
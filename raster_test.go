@@ -0,0 +1,226 @@
+package hge
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func newDst(w, h int, c [4]uint8) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(dst.Pix); i += 4 {
+		copy(dst.Pix[i:], c[:])
+	}
+
+	return dst
+}
+
+func rect(x0, y0, x1, y1 float32, color Dword) *[4]BackendVertex {
+	return &[4]BackendVertex{
+		{X: x0, Y: y0, Z: 0.5, Color: color, TX: 0, TY: 0},
+		{X: x1, Y: y0, Z: 0.5, Color: color, TX: 1, TY: 0},
+		{X: x1, Y: y1, Z: 0.5, Color: color, TX: 1, TY: 1},
+		{X: x0, Y: y1, Z: 0.5, Color: color, TX: 0, TY: 1},
+	}
+}
+
+func pixel(dst *image.RGBA, x, y int) [4]uint8 {
+	i := dst.PixOffset(x, y)
+	return [4]uint8(dst.Pix[i : i+4])
+}
+
+// Checks which pixels of dst were drawn, marked # in want, against the ones
+// left as they were, marked .
+func checkCoverage(t *testing.T, name string, dst *image.RGBA, drawn, blank [4]uint8, want ...string) {
+	t.Helper()
+
+	for y, row := range want {
+		for x, m := range row {
+			c := blank
+			if m == '#' {
+				c = drawn
+			}
+			if got := pixel(dst, x, y); got != c {
+				t.Errorf("%s: pixel %d,%d = %v, want %v", name, x, y, got, c)
+			}
+		}
+	}
+}
+
+func TestRasterizerBlend(t *testing.T) {
+	tex := newDst(1, 1, [4]uint8{200, 100, 50, 255})
+
+	tests := []struct {
+		name   string
+		blend  int
+		color  Dword
+		tex    *image.RGBA
+		opaque bool
+		want   [4]uint8
+	}{
+		// 0x80 alpha is 128/255 of red over 100 grey
+		{"alpha blend", blendAlphaBlend, 0x80FF0000, nil, false, [4]uint8{178, 50, 50, 191}},
+		{"alpha blend opaque", blendAlphaBlend, 0x80FF0000, nil, true, [4]uint8{178, 50, 50, 255}},
+		{"alpha add", 0, 0x80FF0000, nil, false, [4]uint8{228, 100, 100, 255}},
+		{"color mul", blendAlphaBlend, 0xFF808080, tex, false, [4]uint8{100, 50, 25, 255}},
+		{"color add", blendColorAdd | blendAlphaBlend, 0xFF808080, tex, false, [4]uint8{255, 228, 178, 255}},
+		{"color add alpha add", blendColorAdd, 0x80000000, tex, false, [4]uint8{200, 150, 125, 255}},
+	}
+
+	for _, test := range tests {
+		dst := newDst(1, 1, [4]uint8{100, 100, 100, 255})
+		r := &Rasterizer{Dst: dst, Opaque: test.opaque}
+		r.RenderQuad(rect(0, 0, 1, 1, test.color), test.tex, test.blend)
+
+		if got := pixel(dst, 0, 0); got != test.want {
+			t.Errorf("%s: %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRasterizerZBuffer(t *testing.T) {
+	dst := newDst(1, 1, [4]uint8{0, 0, 0, 255})
+	r := &Rasterizer{Dst: dst, ZBuffer: make([]float32, 1)}
+	r.Clear(0xFF000000)
+
+	draw := func(z float32, color Dword, blend int) {
+		v := rect(0, 0, 1, 1, color)
+		for i := range v {
+			v[i].Z = z
+		}
+		r.RenderQuad(v, nil, blend)
+	}
+
+	draw(0.5, 0xFFFF0000, blendAlphaBlend|blendZWrite)
+	draw(0.7, 0xFF00FF00, blendAlphaBlend|blendZWrite)
+	if got := pixel(dst, 0, 0); got != [4]uint8{255, 0, 0, 255} {
+		t.Errorf("behind: %v, want red", got)
+	}
+
+	// Without a z write the depth stays at 0.5
+	draw(0.3, 0xFF0000FF, blendAlphaBlend)
+	draw(0.4, 0xFF00FF00, blendAlphaBlend)
+	if got := pixel(dst, 0, 0); got != [4]uint8{0, 255, 0, 255} {
+		t.Errorf("in front: %v, want green", got)
+	}
+}
+
+func TestRasterizerClipping(t *testing.T) {
+	black, white := [4]uint8{0, 0, 0, 255}, [4]uint8{255, 255, 255, 255}
+
+	dst := newDst(4, 4, black)
+	r := &Rasterizer{Dst: dst}
+	r.SetClipping(1, 1, 2, 2)
+	r.RenderQuad(rect(0, 0, 4, 4, 0xFFFFFFFF), nil, blendAlphaBlend)
+	checkCoverage(t, "quad", dst, white, black,
+		"....",
+		".##.",
+		".##.",
+		"....",
+	)
+
+	r.SetClipping(2, 0, 5, 1)
+	r.Clear(0xFFFFFFFF)
+	checkCoverage(t, "clear", dst, white, black,
+		"..##",
+		".##.",
+		".##.",
+		"....",
+	)
+
+	// A zero size turns clipping off
+	dst = newDst(4, 4, black)
+	r = &Rasterizer{Dst: dst}
+	r.SetClipping(1, 1, 1, 1)
+	r.SetClipping(0, 0, 0, 0)
+	r.RenderQuad(rect(0, 0, 4, 4, 0xFFFFFFFF), nil, blendAlphaBlend)
+	checkCoverage(t, "off", dst, white, black, "####", "####", "####", "####")
+}
+
+func TestRasterizerTransform(t *testing.T) {
+	black, white := [4]uint8{0, 0, 0, 255}, [4]uint8{255, 255, 255, 255}
+
+	tests := []struct {
+		name                      string
+		x, y, dx, dy, rot, hs, vs float64
+		quad                      *[4]BackendVertex
+		want                      []string
+	}{
+		{
+			"move", 0, 0, 2, 1, 0, 1, 1,
+			rect(0, 0, 1, 1, 0xFFFFFFFF),
+			[]string{"....", "..#.", "....", "...."},
+		},
+		{
+			"scale around a point", 1, 1, 0, 0, 0, 2, 2,
+			rect(1, 1, 2, 2, 0xFFFFFFFF),
+			[]string{"....", ".##.", ".##.", "...."},
+		},
+		{
+			"scale each axis", 0, 0, 0, 0, 0, 3, 2,
+			rect(0, 0, 1, 1, 0xFFFFFFFF),
+			[]string{"###.", "###.", "....", "...."},
+		},
+		{
+			"rotate", 0, 0, 0, 2, math.Pi / 2, 1, 1,
+			rect(0, 0, 2, 1, 0xFFFFFFFF),
+			[]string{"#...", "#...", "....", "...."},
+		},
+		{
+			"none", 5, 5, 3, 3, 1, 2, 0,
+			rect(0, 0, 1, 1, 0xFFFFFFFF),
+			[]string{"#...", "....", "....", "...."},
+		},
+	}
+
+	for _, test := range tests {
+		dst := newDst(4, 4, black)
+		r := &Rasterizer{Dst: dst}
+		r.SetTransform(test.x, test.y, test.dx, test.dy, test.rot, test.hs, test.vs)
+		r.RenderQuad(test.quad, nil, blendAlphaBlend)
+		checkCoverage(t, test.name, dst, white, black, test.want...)
+	}
+}
+
+// Pixels on a shared edge are drawn once, and a pixel centre on an edge
+// belongs to the triangle on its right or below it.
+func TestRasterizerFillRule(t *testing.T) {
+	clear, half := [4]uint8{0, 0, 0, 0}, [4]uint8{128, 128, 128, 64}
+
+	tests := []struct {
+		name  string
+		quads [][4]float32
+		want  []string
+	}{
+		{"quad diagonal", [][4]float32{{0, 0, 4, 4}}, []string{"####", "####", "####", "####"}},
+		{"neighbours", [][4]float32{{0, 0, 2, 4}, {2, 0, 4, 4}}, []string{"####", "####", "####", "####"}},
+		{"stacked", [][4]float32{{0, 0, 4, 1.5}, {0, 1.5, 4, 4}}, []string{"####", "####", "####", "####"}},
+		{"centres on the edges", [][4]float32{{0.5, 0.5, 2.5, 2.5}}, []string{"....", ".##.", ".##.", "...."}},
+	}
+
+	for _, test := range tests {
+		dst := newDst(4, 4, clear)
+		r := &Rasterizer{Dst: dst}
+		for _, q := range test.quads {
+			// Added, so a pixel drawn twice comes out brighter
+			r.RenderQuad(rect(q[0], q[1], q[2], q[3], 0x80FFFFFF), nil, 0)
+		}
+		checkCoverage(t, test.name, dst, half, clear, test.want...)
+	}
+}
+
+func TestRasterizerLine(t *testing.T) {
+	black, white := [4]uint8{0, 0, 0, 255}, [4]uint8{255, 255, 255, 255}
+
+	dst := newDst(4, 4, black)
+	r := &Rasterizer{Dst: dst}
+	// The last pixel is left out
+	r.RenderLine(0, 1.5, 4, 1.5, 0xFFFFFFFF, 0.5)
+	r.RenderLine(3.5, 2, 3.5, 4, 0xFFFFFFFF, 0.5)
+	checkCoverage(t, "line", dst, white, black,
+		"....",
+		"####",
+		"...#",
+		"...#",
+	)
+}
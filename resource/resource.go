@@ -1,13 +1,6 @@
 package resource
 
-/*
-#cgo pkg-config: hge-unix-c
-#include "hge_c.h"
-*/
-import "C"
-
 import (
-	"fmt"
 	"runtime"
 	"unsafe"
 
@@ -18,6 +11,7 @@ type Pointer uintptr
 
 type Resource struct {
 	Pointer
+	data []byte
 }
 
 var resourceHGE *hge.HGE
@@ -27,125 +21,120 @@ func init() {
 }
 
 // Loads a resource into memory from disk.
-func NewResource(filename string) (*Resource, hge.Dword) {
-	var s C.DWORD
-	fname := C.CString(filename)
-	defer C.free(unsafe.Pointer(fname))
+func NewResource(filename string) (*Resource, error) {
+	data := resourceHGE.Backend().ResourceLoad(filename)
+
+	if data == nil {
+		return nil, resourceHGE.NewLoadError("resource.NewResource", filename, hge.ErrNotFound)
+	}
 
 	r := new(Resource)
+	r.data = data
 
-	r.Pointer = Pointer(C.HGE_Resource_Load(resourceHGE.HGE, fname, &s))
+	if len(data) > 0 {
+		r.Pointer = Pointer(unsafe.Pointer(&data[0]))
+	}
 
 	runtime.SetFinalizer(r, func(runtime *Resource) {
-		r.Free()
+		hge.DoAsync(r.Free)
 	})
 
-	return r, hge.Dword(s)
+	return r, nil
 }
 
 // Deletes a previously loaded resource from memory.
 func (r *Resource) Free() {
-	fmt.Println("Resource.Free")
-	C.HGE_Resource_Free(resourceHGE.HGE, unsafe.Pointer(r.Pointer))
+	hge.Logger(hge.LogResource).Debug("Freeing resource", "size", len(r.data))
+	resourceHGE.Backend().ResourceFree(r.data)
+	r.data = nil
+	r.Pointer = 0
 }
 
-// Loads a resource, puts the loaded data into a byte array, and frees the data.
-func LoadBytes(filename string) []byte {
-	r, size := NewResource(filename)
+// The loaded data. It's only valid until the resource is freed.
+func (r *Resource) Bytes() []byte {
+	return r.data
+}
+
+// The size of the loaded data.
+func (r *Resource) Size() hge.Dword {
+	return hge.Dword(len(r.data))
+}
 
-	if r == nil {
-		return nil
+// Loads a resource, puts the loaded data into a byte array, and frees the data.
+func LoadBytes(filename string) ([]byte, error) {
+	r, err := NewResource(filename)
+	if err != nil {
+		return nil, err
 	}
 
-	b := C.GoBytes(unsafe.Pointer(r.Pointer), C.int(size))
+	b := make([]byte, len(r.data))
+	copy(b, r.data)
 
-	return b
+	return b, nil
 }
 
 // Loads a resource, puts the data into a string, and frees the data.
-func LoadString(filename string) *string {
-	r, size := NewResource(filename)
-
-	if r == nil {
-		return nil
+func LoadString(filename string) (*string, error) {
+	r, err := NewResource(filename)
+	if err != nil {
+		return nil, err
 	}
 
-	s := C.GoStringN((*C.char)(unsafe.Pointer(r.Pointer)), C.int(size))
+	s := string(r.data)
 
-	return &s
+	return &s, nil
 }
 
 // Attaches a resource pack.
 func AttachPack(filename string, a ...interface{}) bool {
-	fname := C.CString(filename)
-	defer C.free(unsafe.Pointer(fname))
-
 	if len(a) == 1 {
-		var password *C.char
-
-		switch a[0].(type) {
-		case string:
-			password = C.CString(a[0].(string))
-			defer C.free(unsafe.Pointer(password))
+		if password, ok := a[0].(string); ok {
+			return resourceHGE.Backend().ResourceAttachPack(filename, password)
 		}
-
-		return C.HGE_Resource_AttachPack(resourceHGE.HGE, fname, password) == 1
 	}
 
-	return C.HGE_Resource_AttachPack(resourceHGE.HGE, fname, nil) == 1
+	return resourceHGE.Backend().ResourceAttachPack(filename, "")
 }
 
 // Removes a resource pack.
 func RemovePack(filename string) {
-	fname := C.CString(filename)
-	defer C.free(unsafe.Pointer(fname))
-
-	C.HGE_Resource_RemovePack(resourceHGE.HGE, fname)
+	resourceHGE.Backend().ResourceRemovePack(filename)
 }
 
 // Removes all resource packs previously attached.
 func RemoveAllPacks() {
-	C.HGE_Resource_RemoveAllPacks(resourceHGE.HGE)
+	resourceHGE.Backend().ResourceRemoveAllPacks()
 }
 
 // Builds absolute file path.
 func MakePath(a ...interface{}) string {
 	if len(a) == 1 {
 		if filename, ok := a[0].(string); ok {
-			fname := C.CString(filename)
-			defer C.free(unsafe.Pointer(fname))
-
-			return C.GoString(C.HGE_Resource_MakePath(resourceHGE.HGE, fname))
+			return resourceHGE.Backend().ResourceMakePath(filename)
 		}
 	}
 
-	return C.GoString(C.HGE_Resource_MakePath(resourceHGE.HGE, nil))
+	return resourceHGE.Backend().ResourceMakePath("")
 }
 
 // Enumerates files by given wildcard.
 func EnumFiles(a ...interface{}) string {
 	if len(a) == 1 {
 		if wildcard, ok := a[0].(string); ok {
-			wcard := C.CString(wildcard)
-			defer C.free(unsafe.Pointer(wcard))
-
-			return C.GoString(C.HGE_Resource_EnumFiles(resourceHGE.HGE, wcard))
+			return resourceHGE.Backend().ResourceEnumFiles(wildcard)
 		}
 	}
 
-	return C.GoString(C.HGE_Resource_EnumFiles(resourceHGE.HGE, nil))
+	return resourceHGE.Backend().ResourceEnumFiles("")
 }
 
 // Enumerates folders by given wildcard.
 func EnumFolders(a ...interface{}) string {
 	if len(a) == 1 {
 		if wildcard, ok := a[0].(string); ok {
-			wcard := C.CString(wildcard)
-			defer C.free(unsafe.Pointer(wcard))
-
-			return C.GoString(C.HGE_Resource_EnumFolders(resourceHGE.HGE, wcard))
+			return resourceHGE.Backend().ResourceEnumFolders(wildcard)
 		}
 	}
 
-	return C.GoString(C.HGE_Resource_EnumFolders(resourceHGE.HGE, nil))
+	return resourceHGE.Backend().ResourceEnumFolders("")
 }
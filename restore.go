@@ -0,0 +1,28 @@
+package hge
+
+import "sync"
+
+var gfxRestoreHooks struct {
+	sync.Mutex
+	funcs []func()
+}
+
+// Adds a function called when the graphics device is restored, before the
+// GFXRESTOREFUNC. Packages keeping things on the device, like gfx with its
+// targets, use it to put them back.
+func OnGfxRestore(f func()) {
+	gfxRestoreHooks.Lock()
+	defer gfxRestoreHooks.Unlock()
+
+	gfxRestoreHooks.funcs = append(gfxRestoreHooks.funcs, f)
+}
+
+func runGfxRestoreHooks() {
+	gfxRestoreHooks.Lock()
+	funcs := append([]func(){}, gfxRestoreHooks.funcs...)
+	gfxRestoreHooks.Unlock()
+
+	for _, f := range funcs {
+		f()
+	}
+}
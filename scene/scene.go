@@ -0,0 +1,349 @@
+// Package scene keeps a stack of screens, like a menu, the game itself and a
+// pause dialog on top of it, and runs whichever is on top.
+package scene
+
+import (
+	"github.com/losinggeneration/hge"
+	"github.com/losinggeneration/hge/gfx"
+	"github.com/losinggeneration/hge/input"
+	"github.com/losinggeneration/hge/timer"
+)
+
+// A screen of the game.
+type Scene interface {
+	// Called when the scene becomes the top of the stack, whether it was
+	// just pushed or the scene above it was popped.
+	Enter()
+	// Called when the scene stops being the top of the stack.
+	Leave()
+	// Called every frame while the scene is on top. Returning true stops
+	// the game.
+	Update(dt float64) bool
+	// Draws the scene. It's called between BeginScene and EndScene.
+	Render()
+	// Called for every input event while the scene is on top. Returns true
+	// if the event was used.
+	HandleEvent(e *input.InputEvent) bool
+}
+
+// Scenes that only cover part of the screen, like a pause menu, implement
+// Overlay so the scenes below them are drawn first. Events they don't use
+// are passed down to the scene below.
+type Overlay interface {
+	Overlay() bool
+}
+
+// Scenes implementing FocusHandler are told when the application loses or
+// gains focus while they're on top.
+type FocusHandler interface {
+	FocusLost()
+	FocusGain()
+}
+
+// Scenes implementing ExitHandler are asked before the application is closed
+// while they're on top. Returning false keeps it open.
+type ExitHandler interface {
+	Exit() bool
+}
+
+// Base has empty versions of the Scene methods to embed in scenes that don't
+// need all of them.
+type Base struct{}
+
+func (Base) Enter()                               {}
+func (Base) Leave()                               {}
+func (Base) Update(dt float64) bool               { return false }
+func (Base) Render()                              {}
+func (Base) HandleEvent(e *input.InputEvent) bool { return false }
+
+var sceneHGE *hge.HGE
+
+func init() {
+	sceneHGE = hge.New()
+}
+
+// Manager runs the scene on top of its stack. Changes to the stack take
+// effect straight away, a transition only changes what's drawn while it
+// runs.
+type Manager struct {
+	// The color the screen is cleared to before the scenes are drawn
+	Background hge.Dword
+
+	stack []Scene
+
+	transition Transition
+	from       []Scene
+	elapsed    float64
+
+	events *input.Dispatcher
+	sub    *input.Subscription
+}
+
+// Creates a manager with an empty stack. Events are taken from the
+// input.Dispatcher given, or input.Events if there isn't one, so other code
+// can subscribe to the same events.
+func New(a ...interface{}) *Manager {
+	m := &Manager{Background: 0xFF000000, events: input.Events()}
+	if len(a) == 1 {
+		if d, ok := a[0].(*input.Dispatcher); ok && d != nil {
+			m.events = d
+		}
+	}
+
+	m.sub = m.events.Subscribe(func(e input.Event) {
+		i := e.Input()
+		m.HandleEvent(&i)
+	})
+
+	return m
+}
+
+// Stops the manager getting events from its dispatcher.
+func (m *Manager) Free() {
+	m.sub.Cancel()
+}
+
+// Returns the scene on top of the stack, or nil if it's empty.
+func (m *Manager) Top() Scene {
+	if len(m.stack) == 0 {
+		return nil
+	}
+
+	return m.stack[len(m.stack)-1]
+}
+
+// Returns the number of scenes on the stack.
+func (m *Manager) Len() int {
+	return len(m.stack)
+}
+
+// Reports whether a transition is running.
+func (m *Manager) Transitioning() bool {
+	return m.transition != nil
+}
+
+// Puts a scene on top of the stack. A nil transition changes scenes at once.
+func (m *Manager) Push(s Scene, t Transition) {
+	m.begin(t)
+
+	if top := m.Top(); top != nil {
+		top.Leave()
+	}
+	m.stack = append(m.stack, s)
+	s.Enter()
+}
+
+// Removes the scene on top of the stack and returns it. The scene below, if
+// any, is entered.
+func (m *Manager) Pop(t Transition) Scene {
+	top := m.Top()
+	if top == nil {
+		return nil
+	}
+
+	m.begin(t)
+
+	top.Leave()
+	m.stack[len(m.stack)-1] = nil
+	m.stack = m.stack[:len(m.stack)-1]
+
+	if next := m.Top(); next != nil {
+		next.Enter()
+	}
+
+	return top
+}
+
+// Replaces the scene on top of the stack and returns the old one, if any.
+func (m *Manager) Replace(s Scene, t Transition) Scene {
+	m.begin(t)
+
+	top := m.Top()
+	if top != nil {
+		top.Leave()
+		m.stack[len(m.stack)-1] = s
+	} else {
+		m.stack = append(m.stack, s)
+	}
+	s.Enter()
+
+	return top
+}
+
+func (m *Manager) begin(t Transition) {
+	m.end()
+	if t == nil || t.Duration() <= 0 {
+		return
+	}
+
+	m.transition = t
+	m.from = m.visible()
+	m.elapsed = 0
+}
+
+// Stops the running transition, freeing anything it holds onto like the
+// crossfade's targets.
+func (m *Manager) end() {
+	if f, ok := m.transition.(interface{ Free() }); ok {
+		f.Free()
+	}
+
+	m.transition, m.from = nil, nil
+}
+
+// Returns the scenes that can be seen, the top one and any below it that
+// overlays let show through, bottom first.
+func (m *Manager) visible() []Scene {
+	i := len(m.stack) - 1
+	for i > 0 && isOverlay(m.stack[i]) {
+		i--
+	}
+	if i < 0 {
+		return nil
+	}
+
+	return append([]Scene(nil), m.stack[i:]...)
+}
+
+func isOverlay(s Scene) bool {
+	o, ok := s.(Overlay)
+	return ok && o.Overlay()
+}
+
+// Dispatches the input events, which the top scene gets through the
+// manager's subscription, moves any transition on and updates the top scene.
+// Events the application dispatched earlier in the frame have already been
+// handed over. Returns true when the scene asks to stop or the stack is
+// empty.
+func (m *Manager) Update(dt float64) bool {
+	m.events.Dispatch()
+
+	if m.transition != nil {
+		m.elapsed += dt
+		if m.elapsed >= m.transition.Duration() {
+			m.end()
+		}
+	}
+
+	top := m.Top()
+	if top == nil {
+		return true
+	}
+
+	return top.Update(dt)
+}
+
+// Gives an event to the top scene, and on down through any overlays that
+// don't use it. Returns true if a scene used it.
+func (m *Manager) HandleEvent(e *input.InputEvent) bool {
+	for i := len(m.stack) - 1; i >= 0; i-- {
+		if m.stack[i].HandleEvent(e) {
+			return true
+		}
+		if !isOverlay(m.stack[i]) {
+			break
+		}
+	}
+
+	return false
+}
+
+// Draws the visible scenes, or the running transition. This begins and ends
+// the scene itself.
+func (m *Manager) Render() {
+	if m.transition != nil {
+		t := m.elapsed / m.transition.Duration()
+		if t > 1 {
+			t = 1
+		}
+
+		from, to := m.from, m.visible()
+		m.transition.Render(func() { m.draw(from) }, func() { m.draw(to) }, t)
+		return
+	}
+
+	if gfx.BeginScene() {
+		m.draw(m.visible())
+		gfx.EndScene()
+	}
+}
+
+func (m *Manager) draw(scenes []Scene) {
+	gfx.Clear(m.Background)
+	for _, s := range scenes {
+		s.Render()
+	}
+}
+
+// Tells the top scene the application lost focus.
+func (m *Manager) FocusLost() {
+	if f, ok := m.Top().(FocusHandler); ok {
+		f.FocusLost()
+	}
+}
+
+// Tells the top scene the application gained focus.
+func (m *Manager) FocusGain() {
+	if f, ok := m.Top().(FocusHandler); ok {
+		f.FocusGain()
+	}
+}
+
+// Asks the top scene whether the application can close.
+func (m *Manager) Exit() bool {
+	if e, ok := m.Top().(ExitHandler); ok {
+		return e.Exit()
+	}
+
+	return true
+}
+
+// A frame function that updates the manager with timer.Delta().
+func (m *Manager) FrameFunc() int {
+	if m.Update(timer.Delta()) {
+		return 1
+	}
+
+	return 0
+}
+
+// A render function that draws the manager.
+func (m *Manager) RenderFunc() int {
+	m.Render()
+	return 0
+}
+
+func (m *Manager) FocusLostFunc() int {
+	m.FocusLost()
+	return 0
+}
+
+func (m *Manager) FocusGainFunc() int {
+	m.FocusGain()
+	return 0
+}
+
+func (m *Manager) ExitFunc() int {
+	if m.Exit() {
+		return 1
+	}
+
+	return 0
+}
+
+// Returns the options that make the manager the frame, render, focus and
+// exit functions, to pass to HGE.Initiate.
+func (m *Manager) Options() []hge.Option {
+	return []hge.Option{
+		hge.WithFrameFunc(m.FrameFunc),
+		hge.WithRenderFunc(m.RenderFunc),
+		hge.WithFunc(hge.FOCUSLOSTFUNC, m.FocusLostFunc),
+		hge.WithFunc(hge.FOCUSGAINFUNC, m.FocusGainFunc),
+		hge.WithFunc(hge.EXITFUNC, m.ExitFunc),
+	}
+}
+
+// Sets the frame, render, focus and exit functions to the manager.
+func (m *Manager) Install(h *hge.HGE) error {
+	return h.Configure(hge.NewConfig(m.Options()...))
+}
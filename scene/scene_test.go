@@ -0,0 +1,219 @@
+package scene
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/losinggeneration/hge/input"
+)
+
+// A scene writing down what's done to it.
+type recorder struct {
+	name    string
+	log     *[]string
+	overlay bool
+	uses    bool // whether it uses the events it's given
+	stop    bool
+}
+
+func (r *recorder) add(what string) {
+	*r.log = append(*r.log, r.name+" "+what)
+}
+
+func (r *recorder) Enter()  { r.add("enter") }
+func (r *recorder) Leave()  { r.add("leave") }
+func (r *recorder) Render() { r.add("render") }
+
+func (r *recorder) Update(dt float64) bool {
+	r.add(fmt.Sprint("update ", dt))
+	return r.stop
+}
+
+func (r *recorder) HandleEvent(e *input.InputEvent) bool {
+	r.add("event")
+	return r.uses
+}
+
+func (r *recorder) Overlay() bool {
+	return r.overlay
+}
+
+// A transition writing down how far through it's drawn, and what the from
+// and to functions draw.
+type fakeTransition struct {
+	time  float64
+	log   *[]string
+	freed bool
+}
+
+func (f *fakeTransition) Duration() float64 {
+	return f.time
+}
+
+func (f *fakeTransition) Render(from, to func(), t float64) {
+	*f.log = append(*f.log, fmt.Sprint("from ", t))
+	from()
+	*f.log = append(*f.log, fmt.Sprint("to ", t))
+	to()
+}
+
+func (f *fakeTransition) Free() {
+	f.freed = true
+}
+
+func newManager(t *testing.T) (*Manager, *[]string, func(name string) *recorder) {
+	t.Helper()
+
+	m := New(input.NewDispatcher())
+	t.Cleanup(m.Free)
+
+	log := new([]string)
+	return m, log, func(name string) *recorder {
+		return &recorder{name: name, log: log}
+	}
+}
+
+// Checks what's been written down since the last check.
+func expect(t *testing.T, step string, log *[]string, want ...string) {
+	t.Helper()
+
+	if fmt.Sprintf("%q", *log) != fmt.Sprintf("%q", want) {
+		t.Errorf("%s: got %q, want %q", step, *log, want)
+	}
+	*log = nil
+}
+
+func TestStack(t *testing.T) {
+	m, log, scene := newManager(t)
+	menu, game, pause, over := scene("menu"), scene("game"), scene("pause"), scene("over")
+
+	if m.Top() != nil || m.Pop(nil) != nil || !m.Update(0.1) {
+		t.Error("an empty manager has a scene or keeps going")
+	}
+
+	m.Push(menu, nil)
+	expect(t, "push", log, "menu enter")
+
+	if old := m.Replace(game, nil); old != menu {
+		t.Errorf("replace returned %v, want the menu", old)
+	}
+	expect(t, "replace", log, "menu leave", "game enter")
+
+	m.Push(pause, nil)
+	expect(t, "push on top", log, "game leave", "pause enter")
+	if m.Top() != pause || m.Len() != 2 {
+		t.Errorf("top %v of %d, want the pause menu of 2", m.Top(), m.Len())
+	}
+
+	// Only the top scene is updated, with the dt given
+	if m.Update(0.25) {
+		t.Error("Update stopped")
+	}
+	expect(t, "update", log, "pause update 0.25")
+
+	if old := m.Pop(nil); old != pause {
+		t.Errorf("pop returned %v, want the pause menu", old)
+	}
+	expect(t, "pop", log, "pause leave", "game enter")
+
+	game.stop = true
+	if !m.Update(0.5) {
+		t.Error("Update didn't stop when the scene asked to")
+	}
+	expect(t, "stop", log, "game update 0.5")
+
+	m.Replace(over, nil)
+	m.Pop(nil)
+	expect(t, "replace and pop the last", log, "game leave", "over enter", "over leave")
+
+	// Replacing on an empty stack pushes
+	if old := m.Replace(menu, nil); old != nil || m.Len() != 1 {
+		t.Errorf("replace on an empty stack returned %v and left %d scenes", old, m.Len())
+	}
+	expect(t, "replace on empty", log, "menu enter")
+}
+
+func TestTransition(t *testing.T) {
+	m, log, scene := newManager(t)
+	game, pause := scene("game"), scene("pause")
+	pause.overlay = true
+
+	m.Push(game, nil)
+	fade := &fakeTransition{time: 1, log: log}
+	m.Push(pause, fade)
+	*log = nil
+
+	// The stack changes straight away, the transition only changes what's
+	// drawn, from just the game to the pause menu over it
+	if !m.Transitioning() || m.Top() != pause {
+		t.Fatal("pushing with a transition didn't start it or change the top")
+	}
+
+	m.Render()
+	expect(t, "start", log, "from 0", "game render", "to 0", "game render", "pause render")
+
+	for _, step := range []struct {
+		dt float64
+		t  string
+	}{{0.25, "0.25"}, {0.5, "0.75"}, {0.125, "0.875"}} {
+		m.Update(step.dt)
+		m.Render()
+		expect(t, "progress", log, "pause update "+fmt.Sprint(step.dt), "from "+step.t, "game render", "to "+step.t, "game render", "pause render")
+	}
+
+	// Once it's run its time it's over and freed
+	m.Update(0.125)
+	if m.Transitioning() || !fade.freed {
+		t.Errorf("transitioning %v and freed %v once the time's up", m.Transitioning(), fade.freed)
+	}
+	*log = nil
+
+	// Popping goes back to just the game
+	m.Pop(&fakeTransition{time: 2, log: log})
+	*log = nil
+	m.Update(1)
+	m.Render()
+	expect(t, "pop", log, "game update 1", "from 0.5", "game render", "pause render", "to 0.5", "game render")
+
+	// A transition started during another one replaces it
+	old := m.transition.(*fakeTransition)
+	m.Replace(pause, &fakeTransition{time: 1, log: log})
+	if !old.freed {
+		t.Error("the interrupted transition wasn't freed")
+	}
+	*log = nil
+	m.Render()
+	expect(t, "replace", log, "from 0", "game render", "to 0", "pause render")
+
+	// One without a duration changes at once
+	m.Push(game, &fakeTransition{log: log})
+	if m.Transitioning() {
+		t.Error("a transition without a duration is running")
+	}
+}
+
+func TestHandleEvent(t *testing.T) {
+	m, log, scene := newManager(t)
+	game, hud, pause := scene("game"), scene("hud"), scene("pause")
+	hud.overlay, pause.overlay = true, true
+
+	m.Push(scene("menu"), nil)
+	m.Push(game, nil)
+	m.Push(hud, nil)
+	m.Push(pause, nil)
+	*log = nil
+
+	// Events go down through overlays that don't use them, and stop at the
+	// first scene that isn't one
+	e := &input.InputEvent{Type: input.INPUT_KEYDOWN, Key: int(input.K_SPACE)}
+	if m.HandleEvent(e) {
+		t.Error("an event nothing used was used")
+	}
+	expect(t, "unused", log, "pause event", "hud event", "game event")
+
+	hud.uses = true
+	if !m.HandleEvent(e) {
+		t.Error("the event the hud used wasn't used")
+	}
+	expect(t, "used", log, "pause event", "hud event")
+}
@@ -0,0 +1,210 @@
+package scene
+
+import (
+	"math"
+
+	"github.com/losinggeneration/hge"
+	"github.com/losinggeneration/hge/gfx"
+)
+
+// A Transition draws the change from one set of scenes to another.
+type Transition interface {
+	// How long the transition lasts in seconds.
+	Duration() float64
+	// Draws the transition t of the way through, from 0 to 1. The from and
+	// to functions clear and draw the scenes, the transition has to begin
+	// and end the scene, or scenes when drawing to targets, itself.
+	Render(from, to func(), t float64)
+}
+
+func screenSize() (w, h float64) {
+	return float64(sceneHGE.GetInt(hge.SCREENWIDTH)), float64(sceneHGE.GetInt(hge.SCREENHEIGHT))
+}
+
+// Draws a quad over the screen, with tex stretched over it when it's not nil.
+func screenQuad(tex *gfx.Texture, col hge.Dword) {
+	w, h := screenSize()
+	tx, ty := 1.0, 1.0
+	if tex != nil {
+		tx = w / float64(tex.Width())
+		ty = h / float64(tex.Height())
+	}
+
+	q := gfx.Quad{Texture: tex, Blend: gfx.BLEND_DEFAULT}
+	q.V[0] = gfx.Vertex{X: 0, Y: 0, Z: 0.5, Color: col, TX: 0, TY: 0}
+	q.V[1] = gfx.Vertex{X: float32(w), Y: 0, Z: 0.5, Color: col, TX: float32(tx), TY: 0}
+	q.V[2] = gfx.Vertex{X: float32(w), Y: float32(h), Z: 0.5, Color: col, TX: float32(tx), TY: float32(ty)}
+	q.V[3] = gfx.Vertex{X: 0, Y: float32(h), Z: 0.5, Color: col, TX: 0, TY: float32(ty)}
+	q.Render()
+}
+
+func withAlpha(col hge.Dword, a float64) hge.Dword {
+	if a < 0 {
+		a = 0
+	} else if a > 1 {
+		a = 1
+	}
+
+	return col&0x00FFFFFF | hge.Dword(a*float64(col>>24))<<24
+}
+
+// Fades the old scene out to a color and the new one in from it.
+type Fade struct {
+	Time  float64
+	Color hge.Dword
+}
+
+// Creates a fade lasting duration seconds through black.
+func NewFade(duration float64) *Fade {
+	return &Fade{duration, 0xFF000000}
+}
+
+func (f *Fade) Duration() float64 {
+	return f.Time
+}
+
+func (f *Fade) Render(from, to func(), t float64) {
+	if !gfx.BeginScene() {
+		return
+	}
+
+	if t < 0.5 {
+		from()
+		screenQuad(nil, withAlpha(f.Color, t*2))
+	} else {
+		to()
+		screenQuad(nil, withAlpha(f.Color, (1-t)*2))
+	}
+
+	gfx.EndScene()
+}
+
+// The directions a slide moves the scenes in.
+const (
+	SLIDE_LEFT = iota
+	SLIDE_RIGHT
+	SLIDE_UP
+	SLIDE_DOWN
+)
+
+// Slides the new scene in over the screen, pushing the old one off it. The
+// scenes are moved with SetTransform, so scenes that set their own transform
+// won't move.
+type Slide struct {
+	Time      float64
+	Direction int
+}
+
+func NewSlide(duration float64, direction int) *Slide {
+	return &Slide{duration, direction}
+}
+
+func (s *Slide) Duration() float64 {
+	return s.Time
+}
+
+func (s *Slide) Render(from, to func(), t float64) {
+	if !gfx.BeginScene() {
+		return
+	}
+
+	// Ease in and out
+	t = t * t * (3 - 2*t)
+
+	w, h := screenSize()
+	var dx, dy float64
+	switch s.Direction {
+	case SLIDE_LEFT:
+		dx = -w
+	case SLIDE_RIGHT:
+		dx = w
+	case SLIDE_UP:
+		dy = -h
+	case SLIDE_DOWN:
+		dy = h
+	}
+
+	// Each scene is clipped to the part of the screen it covers, so
+	// clearing one doesn't clear the other
+	slideTo(from, dx*t, dy*t, w, h)
+	slideTo(to, dx*(t-1), dy*(t-1), w, h)
+	gfx.SetClipping()
+	gfx.SetTransform()
+
+	gfx.EndScene()
+}
+
+func slideTo(draw func(), dx, dy, w, h float64) {
+	x1, y1 := int(math.Max(dx, 0)), int(math.Max(dy, 0))
+	x2, y2 := int(math.Min(w+dx, w)), int(math.Min(h+dy, h))
+	if x2 <= x1 || y2 <= y1 {
+		return
+	}
+
+	gfx.SetClipping(x1, y1, x2-x1, y2-y1)
+	gfx.SetTransform(0.0, 0.0, dx, dy, 0.0, 1.0, 1.0)
+	draw()
+}
+
+// Draws both scenes to render targets and blends from one to the other.
+type Crossfade struct {
+	Time float64
+
+	from, to *gfx.Target
+}
+
+func NewCrossfade(duration float64) *Crossfade {
+	return &Crossfade{Time: duration}
+}
+
+func (c *Crossfade) Duration() float64 {
+	return c.Time
+}
+
+func (c *Crossfade) Render(from, to func(), t float64) {
+	if c.from == nil || c.to == nil {
+		w, h := screenSize()
+		c.from = gfx.NewTarget(int(w), int(h), false)
+		c.to = gfx.NewTarget(int(w), int(h), false)
+	}
+
+	if c.from == nil || c.to == nil {
+		// No targets, so fall back to cutting half way through
+		if gfx.BeginScene() {
+			if t < 0.5 {
+				from()
+			} else {
+				to()
+			}
+			gfx.EndScene()
+		}
+		return
+	}
+
+	if gfx.BeginScene(c.from) {
+		from()
+		gfx.EndScene()
+	}
+	if gfx.BeginScene(c.to) {
+		to()
+		gfx.EndScene()
+	}
+
+	if gfx.BeginScene() {
+		screenQuad(c.from.Texture(), 0xFFFFFFFF)
+		screenQuad(c.to.Texture(), withAlpha(0xFFFFFFFF, t))
+		gfx.EndScene()
+	}
+}
+
+// Frees the targets the crossfade draws to. They're made again if it's used
+// after this.
+func (c *Crossfade) Free() {
+	if c.from != nil {
+		c.from.Free()
+	}
+	if c.to != nil {
+		c.to.Free()
+	}
+	c.from, c.to = nil, nil
+}
@@ -0,0 +1,257 @@
+// Package shapes draws circles, ellipses, arcs, rounded rectangles,
+// polygons, thick lines and Bezier curves by breaking them into triples.
+//
+// Shapes are drawn in batches through gfx.StartBatch, so they follow the
+// transform, clipping and blend stacks like anything else drawn with gfx.
+package shapes
+
+import (
+	"math"
+
+	"github.com/losinggeneration/hge"
+	"github.com/losinggeneration/hge/gfx"
+	"github.com/losinggeneration/hge/helpers/vector"
+)
+
+// How the segments of a thick line meet.
+const (
+	JOIN_MITER = iota
+	JOIN_BEVEL
+	JOIN_ROUND
+)
+
+// Miters longer than this many times the line's thickness are beveled.
+const MiterLimit = 4.0
+
+// How a shape is drawn.
+type Style struct {
+	Color hge.Dword
+	// When set, the color of each vertex comes from the gradient instead
+	Gradient Gradient
+	Blend    int
+	Z        float64
+	// How the segments of thick lines meet, one of the JOIN constants
+	Join int
+	// How many segments make up a full circle, 0 picks a number to suit the
+	// radius
+	Segments int
+}
+
+// Returns a style drawing in a single color with the default blend mode.
+func NewStyle(color hge.Dword) Style {
+	return Style{Color: color, Blend: gfx.BLEND_DEFAULT, Z: 0.5}
+}
+
+func (s *Style) colorAt(x, y float64) hge.Dword {
+	if s.Gradient != nil {
+		return s.Gradient.ColorAt(x, y)
+	}
+
+	return s.Color
+}
+
+func (s *Style) segments(r float64) int {
+	if s.Segments > 2 {
+		return s.Segments
+	}
+
+	// Keep the segments within a quarter pixel of the curve
+	if r <= 0.25 {
+		return 8
+	}
+	n := int(math.Ceil(2 * math.Pi / math.Acos(1-0.25/r)))
+
+	return max(8, min(n, 256))
+}
+
+// A Gradient gives the color at each point of a shape. Colors are blended
+// between the vertices, so gradients are only exact at them.
+type Gradient interface {
+	ColorAt(x, y float64) hge.Dword
+}
+
+// A gradient from one color to another along the line from X1, Y1 to X2, Y2.
+type Linear struct {
+	X1, Y1, X2, Y2 float64
+	From, To       hge.Dword
+}
+
+func (g Linear) ColorAt(x, y float64) hge.Dword {
+	dx, dy := g.X2-g.X1, g.Y2-g.Y1
+	l := dx*dx + dy*dy
+	if l == 0 {
+		return g.From
+	}
+
+	return lerpColor(g.From, g.To, ((x-g.X1)*dx+(y-g.Y1)*dy)/l)
+}
+
+// A gradient from one color in the middle to another at Radius.
+type Radial struct {
+	X, Y, Radius float64
+	Inner, Outer hge.Dword
+}
+
+func (g Radial) ColorAt(x, y float64) hge.Dword {
+	if g.Radius <= 0 {
+		return g.Outer
+	}
+
+	return lerpColor(g.Inner, g.Outer, math.Hypot(x-g.X, y-g.Y)/g.Radius)
+}
+
+func lerpColor(a, b hge.Dword, t float64) hge.Dword {
+	t = math.Max(0, math.Min(1, t))
+
+	var c hge.Dword
+	for shift := 0; shift < 32; shift += 8 {
+		ca, cb := float64((a>>shift)&0xFF), float64((b>>shift)&0xFF)
+		c |= hge.Dword(ca+(cb-ca)*t+0.5) << shift
+	}
+
+	return c
+}
+
+// Collects triangles to draw in one go.
+type mesh struct {
+	s *Style
+	v []gfx.Vertex
+}
+
+func (m *mesh) vertex(p vector.Vector) gfx.Vertex {
+	return gfx.Vertex{
+		X:     float32(p.X),
+		Y:     float32(p.Y),
+		Z:     float32(m.s.Z),
+		Color: m.s.colorAt(p.X, p.Y),
+	}
+}
+
+func (m *mesh) triangle(a, b, c vector.Vector) {
+	m.v = append(m.v, m.vertex(a), m.vertex(b), m.vertex(c))
+}
+
+func (m *mesh) quad(a, b, c, d vector.Vector) {
+	m.triangle(a, b, c)
+	m.triangle(c, d, a)
+}
+
+// Fills the convex outline from the point c inside it.
+func (m *mesh) fan(c vector.Vector, outline []vector.Vector, closed bool) {
+	for i := 0; i+1 < len(outline); i++ {
+		m.triangle(c, outline[i], outline[i+1])
+	}
+	if closed && len(outline) > 2 {
+		m.triangle(c, outline[len(outline)-1], outline[0])
+	}
+}
+
+func (m *mesh) render() {
+	const primType = gfx.PRIM_TRIPLES
+
+	for v := m.v; len(v) >= primType; {
+		buf, ok := gfx.StartBatchSlice(primType, nil, m.s.Blend)
+		if !ok {
+			return
+		}
+
+		n := min(len(v)/primType, len(buf)/primType)
+		copy(buf, v[:n*primType])
+		gfx.FinishBatch(n)
+
+		v = v[n*primType:]
+	}
+}
+
+// Returns points around an ellipse from angle start to end, in radians
+// clockwise from the right.
+func arcPoints(x, y, rx, ry, start, end float64, s *Style) []vector.Vector {
+	sweep := end - start
+	n := int(math.Ceil(float64(s.segments(math.Max(rx, ry))) * math.Abs(sweep) / (2 * math.Pi)))
+	n = max(n, 1)
+
+	points := make([]vector.Vector, n+1)
+	for i := 0; i <= n; i++ {
+		sin, cos := math.Sincos(start + sweep*float64(i)/float64(n))
+		points[i] = vector.New(x+rx*cos, y+ry*sin)
+	}
+
+	return points
+}
+
+func ellipsePoints(x, y, rx, ry float64, s *Style) []vector.Vector {
+	points := arcPoints(x, y, rx, ry, 0, 2*math.Pi, s)
+	return points[:len(points)-1]
+}
+
+func roundedRectPoints(x, y, w, h, r float64, s *Style) []vector.Vector {
+	r = math.Max(0, math.Min(r, math.Min(w, h)/2))
+	if r == 0 {
+		return []vector.Vector{{X: x, Y: y}, {X: x + w, Y: y}, {X: x + w, Y: y + h}, {X: x, Y: y + h}}
+	}
+
+	var points []vector.Vector
+	points = append(points, arcPoints(x+w-r, y+r, r, r, -math.Pi/2, 0, s)...)
+	points = append(points, arcPoints(x+w-r, y+h-r, r, r, 0, math.Pi/2, s)...)
+	points = append(points, arcPoints(x+r, y+h-r, r, r, math.Pi/2, math.Pi, s)...)
+	points = append(points, arcPoints(x+r, y+r, r, r, math.Pi, 3*math.Pi/2, s)...)
+
+	return points
+}
+
+// Draws a filled circle.
+func FillCircle(x, y, r float64, s Style) {
+	FillEllipse(x, y, r, r, s)
+}
+
+// Draws the outline of a circle, thickness wide.
+func Circle(x, y, r, thickness float64, s Style) {
+	Ellipse(x, y, r, r, thickness, s)
+}
+
+// Draws a filled ellipse.
+func FillEllipse(x, y, rx, ry float64, s Style) {
+	m := mesh{s: &s}
+	m.fan(vector.New(x, y), ellipsePoints(x, y, rx, ry, &s), true)
+	m.render()
+}
+
+// Draws the outline of an ellipse, thickness wide.
+func Ellipse(x, y, rx, ry, thickness float64, s Style) {
+	Polygon(ellipsePoints(x, y, rx, ry, &s), thickness, s)
+}
+
+// Draws part of a circle's outline from angle start to end, in radians
+// clockwise from the right.
+func Arc(x, y, r, start, end, thickness float64, s Style) {
+	Polyline(arcPoints(x, y, r, r, start, end, &s), thickness, s)
+}
+
+// Draws a filled slice of a circle from angle start to end.
+func FillPie(x, y, r, start, end float64, s Style) {
+	m := mesh{s: &s}
+	m.fan(vector.New(x, y), arcPoints(x, y, r, r, start, end, &s), false)
+	m.render()
+}
+
+// Draws a filled rectangle with corners rounded to radius r.
+func FillRoundedRect(x, y, w, h, r float64, s Style) {
+	m := mesh{s: &s}
+	m.fan(vector.New(x+w/2, y+h/2), roundedRectPoints(x, y, w, h, r, &s), true)
+	m.render()
+}
+
+// Draws the outline of a rectangle with corners rounded to radius r.
+func RoundedRect(x, y, w, h, r, thickness float64, s Style) {
+	Polygon(roundedRectPoints(x, y, w, h, r, &s), thickness, s)
+}
+
+// Draws a filled polygon. It can be concave, but its edges shouldn't cross.
+func FillPolygon(points []vector.Vector, s Style) {
+	m := mesh{s: &s}
+	tris := Triangulate(points)
+	for i := 0; i+2 < len(tris); i += 3 {
+		m.triangle(points[tris[i]], points[tris[i+1]], points[tris[i+2]])
+	}
+	m.render()
+}
@@ -0,0 +1,166 @@
+package shapes
+
+import (
+	"math"
+
+	"github.com/losinggeneration/hge/helpers/vector"
+)
+
+// Draws connected line segments thickness wide, joined the way the style
+// says. Where segments overlap on the inside of a join, translucent lines are
+// drawn twice.
+func Polyline(points []vector.Vector, thickness float64, s Style) {
+	m := mesh{s: &s}
+	m.stroke(points, thickness, false)
+	m.render()
+}
+
+// Draws the outline of a polygon thickness wide.
+func Polygon(points []vector.Vector, thickness float64, s Style) {
+	m := mesh{s: &s}
+	m.stroke(points, thickness, true)
+	m.render()
+}
+
+// Draws a quadratic Bezier curve from p0 to p1 pulled towards c.
+func Quadratic(p0, c, p1 vector.Vector, thickness float64, s Style) {
+	Polyline(QuadraticPoints(p0, c, p1), thickness, s)
+}
+
+// Draws a cubic Bezier curve from p0 to p1 pulled towards c0 and c1.
+func Cubic(p0, c0, c1, p1 vector.Vector, thickness float64, s Style) {
+	Polyline(CubicPoints(p0, c0, c1, p1), thickness, s)
+}
+
+// Returns points along a quadratic Bezier curve, close enough together to
+// draw it with straight lines.
+func QuadraticPoints(p0, c, p1 vector.Vector) []vector.Vector {
+	n := curveSegments(c.Sub(p0).Len() + p1.Sub(c).Len())
+
+	points := make([]vector.Vector, n+1)
+	for i := 0; i <= n; i++ {
+		t := float64(i) / float64(n)
+		u := 1 - t
+		points[i] = p0.Mul(u * u).Add(c.Mul(2 * u * t)).Add(p1.Mul(t * t))
+	}
+
+	return points
+}
+
+// Returns points along a cubic Bezier curve, close enough together to draw
+// it with straight lines.
+func CubicPoints(p0, c0, c1, p1 vector.Vector) []vector.Vector {
+	n := curveSegments(c0.Sub(p0).Len() + c1.Sub(c0).Len() + p1.Sub(c1).Len())
+
+	points := make([]vector.Vector, n+1)
+	for i := 0; i <= n; i++ {
+		t := float64(i) / float64(n)
+		u := 1 - t
+		points[i] = p0.Mul(u * u * u).Add(c0.Mul(3 * u * u * t)).Add(c1.Mul(3 * u * t * t)).Add(p1.Mul(t * t * t))
+	}
+
+	return points
+}
+
+// A segment about every 4 pixels of the control polygon's length.
+func curveSegments(length float64) int {
+	return max(4, min(128, int(math.Ceil(length/4))))
+}
+
+func perp(d vector.Vector) vector.Vector {
+	return vector.New(-d.Y, d.X)
+}
+
+func (m *mesh) stroke(points []vector.Vector, thickness float64, closed bool) {
+	// Repeated points have no direction
+	var p []vector.Vector
+	for _, pt := range points {
+		if len(p) == 0 || !pt.Eq(p[len(p)-1]) {
+			p = append(p, pt)
+		}
+	}
+	if closed && len(p) > 1 && p[0].Eq(p[len(p)-1]) {
+		p = p[:len(p)-1]
+	}
+	if len(p) < 2 {
+		return
+	}
+
+	if thickness <= 0 {
+		thickness = 1
+	}
+	hw := thickness / 2
+
+	segments := len(p) - 1
+	if closed {
+		segments = len(p)
+	}
+
+	dir := func(i int) vector.Vector {
+		d := p[(i+1)%len(p)].Sub(p[i])
+		return d.Div(d.Len())
+	}
+
+	for i := 0; i < segments; i++ {
+		a, b := p[i], p[(i+1)%len(p)]
+		n := perp(dir(i)).Mul(hw)
+		m.quad(a.Add(n), b.Add(n), b.Sub(n), a.Sub(n))
+	}
+
+	for i := 0; i < len(p); i++ {
+		if !closed && (i == 0 || i == len(p)-1) {
+			continue
+		}
+
+		prev := (i - 1 + len(p)) % len(p)
+		m.join(p[i], dir(prev), dir(i), hw)
+	}
+}
+
+// Fills the gap on the outside of the corner at p, where a segment going in
+// direction d0 meets one going in direction d1.
+func (m *mesh) join(p, d0, d1 vector.Vector, hw float64) {
+	cross := d0.X*d1.Y - d0.Y*d1.X
+	if math.Abs(cross) < 1e-9 && d0.Dot(d1) > 0 {
+		// Straight on
+		return
+	}
+
+	// The outside of the corner is away from the way it turns
+	side := 1.0
+	if cross > 0 {
+		side = -1
+	}
+	n0, n1 := perp(d0).Mul(hw*side), perp(d1).Mul(hw*side)
+	a, b := p.Add(n0), p.Add(n1)
+
+	switch m.s.Join {
+	case JOIN_ROUND:
+		start := math.Atan2(n0.Y, n0.X)
+		sweep := math.Atan2(n1.Y, n1.X) - start
+		if sweep > math.Pi {
+			sweep -= 2 * math.Pi
+		} else if sweep < -math.Pi {
+			sweep += 2 * math.Pi
+		}
+		m.fan(p, arcPoints(p.X, p.Y, hw, hw, start, start+sweep, m.s), false)
+
+	case JOIN_MITER:
+		mid := n0.Add(n1)
+		if l := mid.Len(); l > 0 {
+			mid = mid.Div(l)
+			// How far the tip is from p
+			length := hw * hw / mid.Dot(n0)
+			if length <= MiterLimit*hw*2 {
+				tip := p.Add(mid.Mul(length))
+				m.triangle(p, a, tip)
+				m.triangle(p, tip, b)
+				return
+			}
+		}
+		m.triangle(p, a, b)
+
+	default:
+		m.triangle(p, a, b)
+	}
+}
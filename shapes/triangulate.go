@@ -0,0 +1,105 @@
+package shapes
+
+import "github.com/losinggeneration/hge/helpers/vector"
+
+func cross(a, b, c vector.Vector) float64 {
+	return (b.X-a.X)*(c.Y-b.Y) - (b.Y-a.Y)*(c.X-b.X)
+}
+
+// Splits a polygon into triangles by ear clipping. The polygon can be convex
+// or concave and wind either way, but its edges shouldn't cross. Returns the
+// indexes of points making up each triangle, three to a triangle.
+func Triangulate(points []vector.Vector) []int {
+	if len(points) < 3 {
+		return nil
+	}
+
+	// Which way the polygon winds decides which corners point out
+	var area float64
+	for i, a := range points {
+		b := points[(i+1)%len(points)]
+		area += a.X*b.Y - b.X*a.Y
+	}
+	sign := 1.0
+	if area < 0 {
+		sign = -1
+	}
+
+	left := make([]int, len(points))
+	for i := range left {
+		left[i] = i
+	}
+
+	tris := make([]int, 0, (len(points)-2)*3)
+	for len(left) > 3 {
+		ear := -1
+		for i := range left {
+			a, b, c := vertices(points, left, i)
+			if cross(a, b, c)*sign <= 0 {
+				continue
+			}
+			if !anyInside(points, left, i, sign) {
+				ear = i
+				break
+			}
+		}
+
+		if ear < 0 {
+			// Only flat corners or crossing edges left. Drop a flat
+			// corner if there is one, otherwise fan out what's left.
+			if flat := flatCorner(points, left); flat >= 0 {
+				left = append(left[:flat], left[flat+1:]...)
+				continue
+			}
+			for i := 1; i+1 < len(left); i++ {
+				tris = append(tris, left[0], left[i], left[i+1])
+			}
+			return tris
+		}
+
+		n := len(left)
+		tris = append(tris, left[(ear-1+n)%n], left[ear], left[(ear+1)%n])
+		left = append(left[:ear], left[ear+1:]...)
+	}
+
+	return append(tris, left[0], left[1], left[2])
+}
+
+func vertices(points []vector.Vector, left []int, i int) (a, b, c vector.Vector) {
+	n := len(left)
+	return points[left[(i-1+n)%n]], points[left[i]], points[left[(i+1)%n]]
+}
+
+// Reports whether any other corner is inside the triangle at corner i.
+func anyInside(points []vector.Vector, left []int, i int, sign float64) bool {
+	n := len(left)
+	ia, ic := left[(i-1+n)%n], left[(i+1)%n]
+	a, b, c := points[ia], points[left[i]], points[ic]
+
+	for _, j := range left {
+		if j == ia || j == left[i] || j == ic {
+			continue
+		}
+
+		p := points[j]
+		if p.Eq(a) || p.Eq(b) || p.Eq(c) {
+			continue
+		}
+		if cross(a, b, p)*sign >= 0 && cross(b, c, p)*sign >= 0 && cross(c, a, p)*sign >= 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func flatCorner(points []vector.Vector, left []int) int {
+	for i := range left {
+		a, b, c := vertices(points, left, i)
+		if cross(a, b, c) == 0 {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,124 @@
+package shapes
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/losinggeneration/hge/helpers/vector"
+)
+
+func polygon(xy ...float64) []vector.Vector {
+	p := make([]vector.Vector, len(xy)/2)
+	for i := range p {
+		p[i] = vector.New(xy[i*2], xy[i*2+1])
+	}
+
+	return p
+}
+
+// Twice the signed area, positive when the points wind clockwise on the
+// screen.
+func signedArea(points []vector.Vector) float64 {
+	var area float64
+	for i, a := range points {
+		b := points[(i+1)%len(points)]
+		area += a.X*b.Y - b.X*a.Y
+	}
+
+	return area
+}
+
+func TestTriangulate(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []vector.Vector
+	}{
+		{"triangle", polygon(0, 0, 10, 0, 0, 10)},
+		{"square", polygon(0, 0, 10, 0, 10, 10, 0, 10)},
+		{"hexagon", polygon(10, 0, 20, 5, 20, 15, 10, 20, 0, 15, 0, 5)},
+		{"arrow", polygon(0, 0, 10, 5, 0, 10, 3, 5)},
+		{"L", polygon(0, 0, 4, 0, 4, 1, 1, 1, 1, 4, 0, 4)},
+		{"star", polygon(10, 0, 12, 7, 20, 7, 14, 12, 16, 20, 10, 15, 4, 20, 6, 12, 0, 7, 8, 7)},
+		{"comb", polygon(0, 0, 9, 0, 9, 5, 8, 5, 8, 1, 6, 1, 6, 5, 5, 5, 5, 1, 3, 1, 3, 5, 2, 5, 2, 1, 0, 1)},
+		{"spiral", polygon(0, 0, 10, 0, 10, 10, 2, 10, 2, 4, 6, 4, 6, 6, 4, 6, 4, 8, 8, 8, 8, 2, 0, 2)},
+		{"collinear edge", polygon(0, 0, 5, 0, 10, 0, 10, 10, 0, 10)},
+		{"collinear on every side", polygon(0, 0, 1, 0, 2, 0, 2, 1, 2, 2, 1, 2, 0, 2, 0, 1)},
+	}
+
+	for _, test := range tests {
+		for _, wind := range []string{"clockwise", "anticlockwise"} {
+			points := test.points
+			if wind == "anticlockwise" {
+				points = slices.Clone(points)
+				slices.Reverse(points)
+			}
+			name := test.name + " " + wind
+
+			idx := Triangulate(points)
+			want := len(points) - 2
+			if len(idx) != want*3 {
+				t.Errorf("%s: %d indexes, want %d triangles", name, len(idx), want)
+				continue
+			}
+
+			// The triangles wind the same way as the polygon, so they can't
+			// overlap and still add up to its area
+			area := signedArea(points)
+			var sum float64
+			for i := 0; i < len(idx); i += 3 {
+				for _, j := range idx[i : i+3] {
+					if j < 0 || j >= len(points) {
+						t.Fatalf("%s: index %d out of range", name, j)
+					}
+				}
+
+				a := signedArea([]vector.Vector{points[idx[i]], points[idx[i+1]], points[idx[i+2]]})
+				if a*area <= 0 {
+					t.Errorf("%s: triangle %v has area %v, the polygon %v", name, idx[i:i+3], a, area)
+				}
+				sum += a
+			}
+			if math.Abs(sum-area) > 1e-9 {
+				t.Errorf("%s: the triangles cover %v, want %v", name, sum/2, area/2)
+			}
+		}
+	}
+}
+
+func TestTriangulateDegenerate(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []vector.Vector
+	}{
+		{"nothing", nil},
+		{"point", polygon(1, 1)},
+		{"segment", polygon(0, 0, 5, 5)},
+		{"line", polygon(0, 0, 1, 1, 2, 2, 3, 3)},
+		{"same point", polygon(1, 1, 1, 1, 1, 1, 1, 1)},
+	}
+
+	for _, test := range tests {
+		idx := Triangulate(test.points)
+		if len(idx)%3 != 0 {
+			t.Errorf("%s: %d indexes", test.name, len(idx))
+			continue
+		}
+		if len(test.points) >= 3 && len(idx) > (len(test.points)-2)*3 {
+			t.Errorf("%s: %d triangles for %d points", test.name, len(idx)/3, len(test.points))
+		}
+
+		// Nothing's there to cover, and nothing should be
+		for i := 0; i < len(idx); i += 3 {
+			for _, j := range idx[i : i+3] {
+				if j < 0 || j >= len(test.points) {
+					t.Fatalf("%s: index %d out of range", test.name, j)
+				}
+			}
+			a := signedArea([]vector.Vector{test.points[idx[i]], test.points[idx[i+1]], test.points[idx[i+2]]})
+			if a != 0 {
+				t.Errorf("%s: triangle %v has area %v", test.name, idx[i:i+3], a)
+			}
+		}
+	}
+}
@@ -1,33 +1,18 @@
 package sound
 
-/*
-#cgo pkg-config: hge-unix-c
-#include "hge_c.h"
-*/
-import "C"
-
 import (
-	"fmt"
 	"runtime"
-	"unsafe"
 
 	"github.com/losinggeneration/hge"
 )
 
-func boolToCInt(b bool) C.BOOL {
-	return C.BOOL(hge.BoolToCInt(b))
-}
-
 // HGE Handle type
 type Effect struct {
-	effect   C.HEFFECT
+	effect   hge.HEffect
 	soundHGE *hge.HGE
 }
 
-func NewEffect(filename string, a ...interface{}) *Effect {
-	fname := C.CString(filename)
-	defer C.free(unsafe.Pointer(fname))
-
+func NewEffect(filename string, a ...interface{}) (*Effect, error) {
 	size := hge.Dword(0)
 
 	if len(a) == 1 {
@@ -38,22 +23,30 @@ func NewEffect(filename string, a ...interface{}) *Effect {
 
 	e := new(Effect)
 	e.soundHGE = hge.New()
-	e.effect = C.HGE_Effect_Load(e.soundHGE.HGE, fname, C.DWORD(size))
+	e.effect = e.soundHGE.Backend().EffectLoad(filename, size)
+	if e.effect == 0 {
+		return nil, e.soundHGE.LoadFailed("sound.NewEffect", filename, deviceReady(e.soundHGE))
+	}
 
 	runtime.SetFinalizer(e, func(effect *Effect) {
-		effect.Free()
+		hge.DoAsync(effect.Free)
 	})
 
-	return e
+	return e, nil
+}
+
+// Sound can only be loaded once the engine is initiated with sound on.
+func deviceReady(h *hge.HGE) bool {
+	return hge.Initiated() && h.GetBool(hge.USESOUND)
 }
 
 func (e *Effect) Free() {
-	fmt.Println("Effect.Free")
-	C.HGE_Effect_Free(e.soundHGE.HGE, (e.effect))
+	hge.Logger(hge.LogSound).Debug("Freeing effect", "effect", e.effect)
+	e.soundHGE.Backend().EffectFree(e.effect)
 }
 
 func (e *Effect) Play() Channel {
-	return Channel{C.HGE_Effect_Play(e.soundHGE.HGE, e.effect), e.soundHGE}
+	return Channel{e.soundHGE.Backend().EffectPlay(e.effect), e.soundHGE}
 }
 
 func (e *Effect) PlayEx(a ...interface{}) Channel {
@@ -87,68 +80,68 @@ func (e *Effect) PlayEx(a ...interface{}) Channel {
 		}
 	}
 
-	return Channel{C.HGE_Effect_PlayEx(e.soundHGE.HGE, e.effect, C.int(volume), C.int(pan), C.float(pitch), boolToCInt(loop)), e.soundHGE}
+	return Channel{e.soundHGE.Backend().EffectPlayEx(e.effect, volume, pan, pitch, loop), e.soundHGE}
 }
 
 // HGE Handle type
 type Channel struct {
-	channel  C.HCHANNEL
+	channel  hge.HChannel
 	soundHGE *hge.HGE
 }
 
 func (c Channel) SetPanning(pan int) {
-	C.HGE_Channel_SetPanning(c.soundHGE.HGE, c.channel, C.int(pan))
+	c.soundHGE.Backend().ChannelSetPanning(c.channel, pan)
 }
 
 func (c Channel) SetVolume(volume int) {
-	C.HGE_Channel_SetVolume(c.soundHGE.HGE, c.channel, C.int(volume))
+	c.soundHGE.Backend().ChannelSetVolume(c.channel, volume)
 }
 
 func (c Channel) SetPitch(pitch float64) {
-	C.HGE_Channel_SetPitch(c.soundHGE.HGE, c.channel, C.float(pitch))
+	c.soundHGE.Backend().ChannelSetPitch(c.channel, pitch)
 }
 
 func (c Channel) Pause() {
-	C.HGE_Channel_Pause(c.soundHGE.HGE, c.channel)
+	c.soundHGE.Backend().ChannelPause(c.channel)
 }
 
 func (c Channel) Resume() {
-	C.HGE_Channel_Resume(c.soundHGE.HGE, c.channel)
+	c.soundHGE.Backend().ChannelResume(c.channel)
 }
 
 func (c Channel) Stop() {
-	C.HGE_Channel_Stop(c.soundHGE.HGE, c.channel)
+	c.soundHGE.Backend().ChannelStop(c.channel)
 }
 
 // Pause all sounds on all channels
 func PauseAll() {
-	C.HGE_Channel_PauseAll(hge.New().HGE)
+	hge.New().Backend().ChannelPauseAll()
 }
 
 // Resume all sounds on all channels
 func ResumeAll() {
-	C.HGE_Channel_ResumeAll(hge.New().HGE)
+	hge.New().Backend().ChannelResumeAll()
 }
 
 // Stop all sounds on all channels
 func StopAll() {
-	C.HGE_Channel_StopAll(hge.New().HGE)
+	hge.New().Backend().ChannelStopAll()
 }
 
 func (c Channel) IsPlaying() bool {
-	return C.HGE_Channel_IsPlaying(c.soundHGE.HGE, c.channel) == 1
+	return c.soundHGE.Backend().ChannelIsPlaying(c.channel)
 }
 
 func (c Channel) Len() float64 {
-	return float64(C.HGE_Channel_GetLength(c.soundHGE.HGE, c.channel))
+	return c.soundHGE.Backend().ChannelLength(c.channel)
 }
 
 func (c Channel) Pos() float64 {
-	return float64(C.HGE_Channel_GetPos(c.soundHGE.HGE, c.channel))
+	return c.soundHGE.Backend().ChannelPos(c.channel)
 }
 
 func (c Channel) SetPos(seconds float64) {
-	C.HGE_Channel_SetPos(c.soundHGE.HGE, c.channel, C.float(seconds))
+	c.soundHGE.Backend().ChannelSetPos(c.channel, seconds)
 }
 
 func (c Channel) SlideTo(time float64, a ...interface{}) {
@@ -176,37 +169,37 @@ func (c Channel) SlideTo(time float64, a ...interface{}) {
 		}
 	}
 
-	C.HGE_Channel_SlideTo(c.soundHGE.HGE, c.channel, C.float(time), C.int(volume), C.int(pan), C.float(pitch))
+	c.soundHGE.Backend().ChannelSlideTo(c.channel, time, volume, pan, pitch)
 }
 
 func (c Channel) IsSliding() bool {
-	return C.HGE_Channel_IsSliding(c.soundHGE.HGE, c.channel) == 1
+	return c.soundHGE.Backend().ChannelIsSliding(c.channel)
 }
 
 // HGE Handle type
 type Music struct {
-	music    C.HMUSIC
+	music    hge.HMusic
 	soundHGE *hge.HGE
 }
 
-func NewMusic(filename string, size hge.Dword) *Music {
-	fname := C.CString(filename)
-	defer C.free(unsafe.Pointer(fname))
-
+func NewMusic(filename string, size hge.Dword) (*Music, error) {
 	m := new(Music)
 	m.soundHGE = hge.New()
-	m.music = C.HGE_Music_Load(m.soundHGE.HGE, fname, C.DWORD(size))
+	m.music = m.soundHGE.Backend().MusicLoad(filename, size)
+	if m.music == 0 {
+		return nil, m.soundHGE.LoadFailed("sound.NewMusic", filename, deviceReady(m.soundHGE))
+	}
 
 	runtime.SetFinalizer(m, func(music *Music) {
-		music.Free()
+		hge.DoAsync(music.Free)
 	})
 
-	return m
+	return m, nil
 }
 
 func (m *Music) Free() {
-	fmt.Println("Music.Free")
-	C.HGE_Music_Free(m.soundHGE.HGE, m.music)
+	hge.Logger(hge.LogSound).Debug("Freeing music", "music", m.music)
+	m.soundHGE.Backend().MusicFree(m.music)
 }
 
 func (m *Music) Play(loop bool, a ...interface{}) Channel {
@@ -230,73 +223,69 @@ func (m *Music) Play(loop bool, a ...interface{}) Channel {
 		}
 	}
 
-	return Channel{C.HGE_Music_Play(m.soundHGE.HGE, m.music, boolToCInt(loop), C.int(volume), C.int(order), C.int(row)), m.soundHGE}
+	return Channel{m.soundHGE.Backend().MusicPlay(m.music, loop, volume, order, row), m.soundHGE}
 }
 
 func (m *Music) SetAmplification(ampl int) {
-	C.HGE_Music_SetAmplification(m.soundHGE.HGE, m.music, C.int(ampl))
+	m.soundHGE.Backend().MusicSetAmplification(m.music, ampl)
 }
 
 func (m *Music) Amplification() int {
-	return int(C.HGE_Music_GetAmplification(m.soundHGE.HGE, m.music))
+	return m.soundHGE.Backend().MusicAmplification(m.music)
 }
 
 func (m *Music) Len() int {
-	return int(C.HGE_Music_GetLength(m.soundHGE.HGE, m.music))
+	return m.soundHGE.Backend().MusicLength(m.music)
 }
 
 func (m *Music) SetPos(order, row int) {
-	C.HGE_Music_SetPos(m.soundHGE.HGE, m.music, C.int(order), C.int(row))
+	m.soundHGE.Backend().MusicSetPos(m.music, order, row)
 }
 
 func (m *Music) Pos() (order, row int, ok bool) {
-	var o, r C.int
-
-	ok = C.HGE_Music_GetPos(m.soundHGE.HGE, m.music, &o, &r) == 1
-
-	return int(o), int(r), ok
+	return m.soundHGE.Backend().MusicPos(m.music)
 }
 
 func (m *Music) SetInstrVolume(instr int, volume int) {
-	C.HGE_Music_SetInstrVolume(m.soundHGE.HGE, m.music, C.int(instr), C.int(volume))
+	m.soundHGE.Backend().MusicSetInstrVolume(m.music, instr, volume)
 }
 
 func (m *Music) InstrVolume(instr int) int {
-	return int(C.HGE_Music_GetInstrVolume(m.soundHGE.HGE, m.music, C.int(instr)))
+	return m.soundHGE.Backend().MusicInstrVolume(m.music, instr)
 }
 
 func (m *Music) SetChannelVolume(channel, volume int) {
-	C.HGE_Music_SetChannelVolume(m.soundHGE.HGE, m.music, C.int(channel), C.int(volume))
+	m.soundHGE.Backend().MusicSetChannelVolume(m.music, channel, volume)
 }
 
 func (m *Music) ChannelVolume(channel int) int {
-	return int(C.HGE_Music_GetChannelVolume(m.soundHGE.HGE, m.music, C.int(channel)))
+	return m.soundHGE.Backend().MusicChannelVolume(m.music, channel)
 }
 
 // HGE Handle type
 type Stream struct {
-	stream   C.HSTREAM
+	stream   hge.HStream
 	soundHGE *hge.HGE
 }
 
-func NewStream(filename string, size hge.Dword) *Stream {
-	fname := C.CString(filename)
-	defer C.free(unsafe.Pointer(fname))
-
+func NewStream(filename string, size hge.Dword) (*Stream, error) {
 	s := new(Stream)
 	s.soundHGE = hge.New()
-	s.stream = C.HGE_Stream_Load(s.soundHGE.HGE, fname, C.DWORD(size))
+	s.stream = s.soundHGE.Backend().StreamLoad(filename, size)
+	if s.stream == 0 {
+		return nil, s.soundHGE.LoadFailed("sound.NewStream", filename, deviceReady(s.soundHGE))
+	}
 
 	runtime.SetFinalizer(s, func(stream *Stream) {
-		stream.Free()
+		hge.DoAsync(stream.Free)
 	})
 
-	return s
+	return s, nil
 }
 
 func (s *Stream) Free() {
-	fmt.Println("Stream.Free")
-	C.HGE_Stream_Free(s.soundHGE.HGE, s.stream)
+	hge.Logger(hge.LogSound).Debug("Freeing stream", "stream", s.stream)
+	s.soundHGE.Backend().StreamFree(s.stream)
 }
 
 func (s *Stream) Play(loop bool, a ...interface{}) Channel {
@@ -308,5 +297,5 @@ func (s *Stream) Play(loop bool, a ...interface{}) Channel {
 		}
 	}
 
-	return Channel{C.HGE_Stream_Play(s.soundHGE.HGE, s.stream, boolToCInt(loop), C.int(volume)), s.soundHGE}
+	return Channel{s.soundHGE.Backend().StreamPlay(s.stream, loop, volume), s.soundHGE}
 }
@@ -0,0 +1,330 @@
+package hge
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+)
+
+var (
+	ErrUnknownState = errors.New("hge: unknown state")
+	ErrReadOnly     = errors.New("hge: state is read only")
+	ErrInitiated    = errors.New("hge: state can only be set before Initiate")
+	ErrInvalidValue = errors.New("hge: invalid value for state")
+)
+
+// Set while the engine is initiated. The backend is shared, so this is too.
+var initiated atomic.Bool
+
+var boolStateNames = map[BoolState]string{
+	WINDOWED:      "WINDOWED",
+	ZBUFFER:       "ZBUFFER",
+	TEXTUREFILTER: "TEXTUREFILTER",
+	USESOUND:      "USESOUND",
+	DONTSUSPEND:   "DONTSUSPEND",
+	HIDEMOUSE:     "HIDEMOUSE",
+	SHOWSPLASH:    "SHOWSPLASH",
+}
+
+var funcStateNames = map[FuncState]string{
+	FRAMEFUNC:      "FRAMEFUNC",
+	RENDERFUNC:     "RENDERFUNC",
+	FOCUSLOSTFUNC:  "FOCUSLOSTFUNC",
+	FOCUSGAINFUNC:  "FOCUSGAINFUNC",
+	GFXRESTOREFUNC: "GFXRESTOREFUNC",
+	EXITFUNC:       "EXITFUNC",
+}
+
+var hwndStateNames = map[HwndState]string{
+	HWND:       "HWND",
+	HWNDPARENT: "HWNDPARENT",
+}
+
+var intStateNames = map[IntState]string{
+	SCREENWIDTH:      "SCREENWIDTH",
+	SCREENHEIGHT:     "SCREENHEIGHT",
+	SCREENBPP:        "SCREENBPP",
+	SAMPLERATE:       "SAMPLERATE",
+	FXVOLUME:         "FXVOLUME",
+	MUSVOLUME:        "MUSVOLUME",
+	STREAMVOLUME:     "STREAMVOLUME",
+	FPS:              "FPS",
+	POWERSTATUS:      "POWERSTATUS",
+	ORIGSCREENWIDTH:  "ORIGSCREENWIDTH",
+	ORIGSCREENHEIGHT: "ORIGSCREENHEIGHT",
+}
+
+var stringStateNames = map[StringState]string{
+	ICON:    "ICON",
+	TITLE:   "TITLE",
+	INIFILE: "INIFILE",
+	LOGFILE: "LOGFILE",
+}
+
+func stateName(name string, ok bool, state int) string {
+	if ok {
+		return name
+	}
+
+	return "state(" + strconv.Itoa(state) + ")"
+}
+
+func (s BoolState) String() string {
+	name, ok := boolStateNames[s]
+	return stateName(name, ok, int(s))
+}
+
+func (s FuncState) String() string {
+	name, ok := funcStateNames[s]
+	return stateName(name, ok, int(s))
+}
+
+func (s HwndState) String() string {
+	name, ok := hwndStateNames[s]
+	return stateName(name, ok, int(s))
+}
+
+func (s IntState) String() string {
+	name, ok := intStateNames[s]
+	return stateName(name, ok, int(s))
+}
+
+func (s StringState) String() string {
+	name, ok := stringStateNames[s]
+	return stateName(name, ok, int(s))
+}
+
+// Returns the system state with the given name, for example "WINDOWED". The
+// state is a BoolState, FuncState, HwndState, IntState or StringState.
+func StateByName(name string) (state interface{}, ok bool) {
+	for s, n := range boolStateNames {
+		if n == name {
+			return s, true
+		}
+	}
+	for s, n := range funcStateNames {
+		if n == name {
+			return s, true
+		}
+	}
+	for s, n := range hwndStateNames {
+		if n == name {
+			return s, true
+		}
+	}
+	for s, n := range intStateNames {
+		if n == name {
+			return s, true
+		}
+	}
+	for s, n := range stringStateNames {
+		if n == name {
+			return s, true
+		}
+	}
+
+	return nil, false
+}
+
+// Reports whether the engine has been initiated and not shut down since.
+func Initiated() bool {
+	return initiated.Load()
+}
+
+func stateError(err error, state fmt.Stringer) error {
+	return fmt.Errorf("%w: %v", err, state)
+}
+
+func valueError(state fmt.Stringer, value interface{}) error {
+	return fmt.Errorf("%w: %v = %v", ErrInvalidValue, state, value)
+}
+
+// Checks a bool state can be set to value. Like HGE, the z-buffer and splash
+// screen only matter while initiating.
+func checkBool(state BoolState, value bool, initiated bool) error {
+	if _, ok := boolStateNames[state]; !ok {
+		return stateError(ErrUnknownState, state)
+	}
+
+	switch state {
+	case ZBUFFER, SHOWSPLASH:
+		if initiated {
+			return stateError(ErrInitiated, state)
+		}
+	}
+
+	return nil
+}
+
+func checkFunc(state FuncState, value StateFunc, initiated bool) error {
+	if _, ok := funcStateNames[state]; !ok {
+		return stateError(ErrUnknownState, state)
+	}
+
+	if state == FRAMEFUNC && value == nil && initiated {
+		return valueError(state, "nil")
+	}
+
+	return nil
+}
+
+func checkHwnd(state HwndState, value Hwnd, initiated bool) error {
+	switch state {
+	case HWND:
+		return stateError(ErrReadOnly, state)
+	case HWNDPARENT:
+		if initiated {
+			return stateError(ErrInitiated, state)
+		}
+		return nil
+	}
+
+	return stateError(ErrUnknownState, state)
+}
+
+func checkInt(state IntState, value int, initiated bool) error {
+	if _, ok := intStateNames[state]; !ok {
+		return stateError(ErrUnknownState, state)
+	}
+
+	if isReadOnlyInt(state) {
+		return stateError(ErrReadOnly, state)
+	}
+
+	switch state {
+	case SCREENWIDTH, SCREENHEIGHT:
+		if initiated {
+			return stateError(ErrInitiated, state)
+		}
+		if value <= 0 {
+			return valueError(state, value)
+		}
+
+	case SCREENBPP:
+		if initiated {
+			return stateError(ErrInitiated, state)
+		}
+		if value != 16 && value != 32 {
+			return valueError(state, value)
+		}
+
+	case SAMPLERATE:
+		if initiated {
+			return stateError(ErrInitiated, state)
+		}
+		if value <= 0 {
+			return valueError(state, value)
+		}
+
+	case FXVOLUME, MUSVOLUME, STREAMVOLUME:
+		if value < 0 || value > 100 {
+			return valueError(state, value)
+		}
+
+	case FPS:
+		// FPS_VSYNC is -1
+		if value < -1 {
+			return valueError(state, value)
+		}
+	}
+
+	return nil
+}
+
+func isReadOnlyInt(s IntState) bool {
+	switch s {
+	case POWERSTATUS, ORIGSCREENWIDTH, ORIGSCREENHEIGHT:
+		return true
+	}
+
+	return false
+}
+
+// Checks a string state can be set. The icon is only read while initiating.
+func checkString(state StringState, value string, initiated bool) error {
+	if _, ok := stringStateNames[state]; !ok {
+		return stateError(ErrUnknownState, state)
+	}
+
+	if state == ICON && initiated {
+		return stateError(ErrInitiated, state)
+	}
+
+	return nil
+}
+
+// Sets a bool system state.
+func (h *HGE) SetBool(state BoolState, value bool) error {
+	if err := checkBool(state, value, Initiated()); err != nil {
+		return err
+	}
+
+	h.Backend().SetStateBool(state, value)
+	return nil
+}
+
+// Sets a function system state. A nil function clears it.
+func (h *HGE) SetFunc(state FuncState, value StateFunc) error {
+	if err := checkFunc(state, value, Initiated()); err != nil {
+		return err
+	}
+
+	h.Backend().SetStateFunc(state, value)
+	return nil
+}
+
+// Sets a window handle system state.
+func (h *HGE) SetHwnd(state HwndState, value Hwnd) error {
+	if err := checkHwnd(state, value, Initiated()); err != nil {
+		return err
+	}
+
+	h.Backend().SetStateHwnd(state, value)
+	return nil
+}
+
+// Sets an int system state.
+func (h *HGE) SetInt(state IntState, value int) error {
+	if err := checkInt(state, value, Initiated()); err != nil {
+		return err
+	}
+
+	h.Backend().SetStateInt(state, value)
+	return nil
+}
+
+// Sets a string system state.
+func (h *HGE) SetString(state StringState, value string) error {
+	if err := checkString(state, value, Initiated()); err != nil {
+		return err
+	}
+
+	h.Backend().SetStateString(state, value)
+	return nil
+}
+
+// Returns a bool system state.
+func (h *HGE) GetBool(state BoolState) bool {
+	return h.Backend().StateBool(state)
+}
+
+// Returns a function system state.
+func (h *HGE) GetFunc(state FuncState) StateFunc {
+	return h.Backend().StateFunc(state)
+}
+
+// Returns a window handle system state.
+func (h *HGE) GetHwnd(state HwndState) Hwnd {
+	return h.Backend().StateHwnd(state)
+}
+
+// Returns an int system state.
+func (h *HGE) GetInt(state IntState) int {
+	return h.Backend().StateInt(state)
+}
+
+// Returns a string system state.
+func (h *HGE) GetString(state StringState) string {
+	return h.Backend().StateString(state)
+}
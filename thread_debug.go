@@ -0,0 +1,7 @@
+//go:build hgedebug
+
+package hge
+
+// Built with the hgedebug tag, using the engine from the wrong goroutine
+// panics.
+const debugThread = true
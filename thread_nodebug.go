@@ -0,0 +1,5 @@
+//go:build !hgedebug
+
+package hge
+
+const debugThread = false
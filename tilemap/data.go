@@ -0,0 +1,92 @@
+package tilemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/losinggeneration/hge"
+)
+
+// Decodes layer data saved as CSV or base64, which can be compressed with
+// gzip or zlib.
+func decodeData(encoding, compression, text string, size int) ([]uint32, error) {
+	var gids []uint32
+
+	switch encoding {
+	case "csv":
+		for _, f := range strings.Split(text, ",") {
+			gid, err := strconv.ParseUint(strings.TrimSpace(f), 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			gids = append(gids, uint32(gid))
+		}
+
+	case "base64":
+		data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+		if err != nil {
+			return nil, err
+		}
+
+		var r io.Reader = bytes.NewReader(data)
+		switch compression {
+		case "":
+		case "gzip":
+			if r, err = gzip.NewReader(r); err != nil {
+				return nil, err
+			}
+		case "zlib":
+			if r, err = zlib.NewReader(r); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, ErrEncoding
+		}
+
+		// Reading a byte past the layer is enough to know it's too big,
+		// without decompressing however much more there is
+		if data, err = io.ReadAll(io.LimitReader(r, int64(size)*4+1)); err != nil {
+			return nil, err
+		}
+		if len(data) > size*4 {
+			return nil, errors.New("tilemap: layer data is the wrong size")
+		}
+		if len(data)%4 != 0 {
+			return nil, errors.New("tilemap: layer data isn't a whole number of GIDs")
+		}
+		for i := 0; i < len(data); i += 4 {
+			gids = append(gids, binary.LittleEndian.Uint32(data[i:]))
+		}
+
+	default:
+		return nil, ErrEncoding
+	}
+
+	if len(gids) != size {
+		return nil, errors.New("tilemap: layer data is the wrong size")
+	}
+
+	return gids, nil
+}
+
+// Parses a color saved as #RRGGBB or #AARRGGBB. Returns 0 for no color.
+func parseColor(s string) hge.Dword {
+	s = strings.TrimPrefix(s, "#")
+	c, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return 0
+	}
+
+	if len(s) == 6 {
+		c |= 0xFF000000
+	}
+
+	return hge.Dword(c)
+}
@@ -0,0 +1,85 @@
+package tilemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func encodeGIDs(gids []uint32, compress func(io.Writer) io.WriteCloser) string {
+	var raw, b bytes.Buffer
+	binary.Write(&raw, binary.LittleEndian, gids)
+
+	if compress == nil {
+		b = raw
+	} else {
+		w := compress(&b)
+		w.Write(raw.Bytes())
+		w.Close()
+	}
+
+	return base64.StdEncoding.EncodeToString(b.Bytes())
+}
+
+func gzipWriter(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }
+func zlibWriter(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }
+
+func TestDecodeData(t *testing.T) {
+	gids := []uint32{0, 1, 2 | FLIP_HORIZONTAL, 3 | FLIP_VERTICAL | FLIP_DIAGONAL, GID_MASK, 0xFFFFFFFF}
+
+	tests := []struct {
+		name                  string
+		encoding, compression string
+		text                  string
+		size                  int
+		err                   bool
+	}{
+		{"csv", "csv", "", "0,1,2147483650,\n 1610612739 ,268435455,4294967295\n", 6, false},
+		{"base64", "base64", "", encodeGIDs(gids, nil), 6, false},
+		{"base64 with spaces", "base64", "", "\n   " + encodeGIDs(gids, nil) + "\n  ", 6, false},
+		{"gzip", "base64", "gzip", encodeGIDs(gids, gzipWriter), 6, false},
+		{"zlib", "base64", "zlib", encodeGIDs(gids, zlibWriter), 6, false},
+
+		{"csv too short", "csv", "", "0,1,2", 6, true},
+		{"csv too long", "csv", "", "0,1,2,3,4,5,6", 6, true},
+		{"csv not a number", "csv", "", "0,1,x,3,4,5", 6, true},
+		{"csv too big", "csv", "", "0,1,2,3,4,4294967296", 6, true},
+		{"base64 too short", "base64", "", encodeGIDs(gids[:5], nil), 6, true},
+		{"gzip too long", "base64", "gzip", encodeGIDs(append(gids, 1), gzipWriter), 6, true},
+		{"zlib far too long", "base64", "zlib", encodeGIDs(make([]uint32, 1<<20), zlibWriter), 6, true},
+		{"base64 part of a GID short", "base64", "", base64.StdEncoding.EncodeToString(make([]byte, 23)), 6, true},
+		{"bad base64", "base64", "", "not base64!", 6, true},
+		{"gzip that isn't", "base64", "gzip", encodeGIDs(gids, zlibWriter), 6, true},
+		{"zlib that isn't", "base64", "zlib", encodeGIDs(gids, nil), 6, true},
+		{"zstd", "base64", "zstd", encodeGIDs(gids, nil), 6, true},
+		{"unknown encoding", "xml", "", "", 6, true},
+	}
+
+	for _, test := range tests {
+		got, err := decodeData(test.encoding, test.compression, test.text, test.size)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: decoded %v, want an error", test.name, got)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		} else if !reflect.DeepEqual(got, gids) {
+			t.Errorf("%s: %v, want %v", test.name, got, gids)
+		}
+	}
+
+	for _, enc := range [][2]string{{"xml", ""}, {"base64", "zstd"}} {
+		if _, err := decodeData(enc[0], enc[1], "", 0); !errors.Is(err, ErrEncoding) {
+			t.Errorf("%s %s: %v, want ErrEncoding", enc[0], enc[1], err)
+		}
+	}
+}
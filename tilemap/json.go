@@ -0,0 +1,291 @@
+package tilemap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/losinggeneration/hge/helpers/vector"
+)
+
+// Tiled's JSON names match these fields when case is ignored, the way
+// encoding/json matches them.
+type jsonMap struct {
+	Orientation           string
+	Width, Height         int
+	TileWidth, TileHeight int
+	Infinite              bool
+	BackgroundColor       string
+	Properties            []jsonProperty
+	Tilesets              []jsonTileset
+	Layers                []jsonLayer
+}
+
+type jsonProperty struct {
+	Name  string
+	Type  string
+	Value interface{}
+}
+
+type jsonTileset struct {
+	FirstGID                int
+	Source                  string
+	Name                    string
+	TileWidth, TileHeight   int
+	Spacing, Margin         int
+	TileCount, Columns      int
+	Image                   string
+	ImageWidth, ImageHeight int
+	TileOffset              struct{ X, Y int }
+	Tiles                   []jsonTile
+}
+
+type jsonTile struct {
+	ID          int
+	Type        string
+	Class       string
+	Properties  []jsonProperty
+	Animation   []struct{ TileID, Duration int }
+	ObjectGroup *jsonLayer
+}
+
+type jsonLayer struct {
+	Type                  string
+	Name                  string
+	Width, Height         int
+	Visible               *bool
+	Opacity               *float64
+	OffsetX, OffsetY      float64
+	Encoding, Compression string
+	Data                  json.RawMessage
+	Chunks                json.RawMessage
+	Objects               []jsonObject
+	Layers                []jsonLayer
+	Properties            []jsonProperty
+}
+
+type jsonObject struct {
+	ID                  int
+	Name, Type, Class   string
+	X, Y, Width, Height float64
+	Rotation            float64
+	GID                 uint32
+	Visible             *bool
+	Ellipse, Point      bool
+	Polygon, Polyline   []struct{ X, Y float64 }
+	Properties          []jsonProperty
+}
+
+func (l *jsonLayer) group(parent group) group {
+	g := group{
+		offsetX: parent.offsetX + l.OffsetX,
+		offsetY: parent.offsetY + l.OffsetY,
+		opacity: parent.opacity,
+		visible: parent.visible,
+	}
+	if l.Opacity != nil {
+		g.opacity *= *l.Opacity
+	}
+	if l.Visible != nil {
+		g.visible = g.visible && *l.Visible
+	}
+
+	return g
+}
+
+// Parses a map in Tiled's JSON format. Tilesets in their own files are left
+// with only FirstGID and Source set, and no tileset has its texture loaded;
+// Load does both.
+func ParseJSON(data []byte) (*Map, error) {
+	var jm jsonMap
+	if err := json.Unmarshal(data, &jm); err != nil {
+		return nil, err
+	}
+	if jm.Infinite {
+		return nil, ErrInfinite
+	}
+
+	m := &Map{
+		Orientation: jm.Orientation,
+		Width:       jm.Width,
+		Height:      jm.Height,
+		TileWidth:   jm.TileWidth,
+		TileHeight:  jm.TileHeight,
+		Background:  parseColor(jm.BackgroundColor),
+		Properties:  jsonProperties(jm.Properties),
+	}
+	if m.Orientation == "" {
+		m.Orientation = ORTHOGONAL
+	}
+
+	for i := range jm.Tilesets {
+		m.Tilesets = append(m.Tilesets, jsonTilesetOf(&jm.Tilesets[i]))
+	}
+	sort.Slice(m.Tilesets, func(i, j int) bool { return m.Tilesets[i].FirstGID < m.Tilesets[j].FirstGID })
+
+	for i := range jm.Layers {
+		if err := m.jsonLayer(&jm.Layers[i], group{visible: true, opacity: 1}); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// Parses a tileset in its own JSON file.
+func ParseTilesetJSON(data []byte) (*Tileset, error) {
+	var jt jsonTileset
+	if err := json.Unmarshal(data, &jt); err != nil {
+		return nil, err
+	}
+
+	jt.FirstGID, jt.Source = 1, ""
+	return jsonTilesetOf(&jt), nil
+}
+
+func jsonTilesetOf(jt *jsonTileset) *Tileset {
+	ts := &Tileset{
+		FirstGID:    jt.FirstGID,
+		Source:      jt.Source,
+		Name:        jt.Name,
+		TileWidth:   jt.TileWidth,
+		TileHeight:  jt.TileHeight,
+		Spacing:     jt.Spacing,
+		Margin:      jt.Margin,
+		TileCount:   jt.TileCount,
+		Columns:     jt.Columns,
+		OffsetX:     jt.TileOffset.X,
+		OffsetY:     jt.TileOffset.Y,
+		Image:       jt.Image,
+		ImageWidth:  jt.ImageWidth,
+		ImageHeight: jt.ImageHeight,
+		Tiles:       make(map[int]*Tile),
+	}
+
+	for _, jtile := range jt.Tiles {
+		t := &Tile{ID: jtile.ID, Type: jtile.Type, Properties: jsonProperties(jtile.Properties)}
+		if t.Type == "" {
+			t.Type = jtile.Class
+		}
+		for _, f := range jtile.Animation {
+			t.Animation = append(t.Animation, Frame{f.TileID, float64(f.Duration) / 1000})
+		}
+		if jtile.ObjectGroup != nil {
+			t.Collision = jsonObjectGroup(jtile.ObjectGroup, group{visible: true, opacity: 1})
+		}
+		ts.Tiles[t.ID] = t
+	}
+
+	return ts
+}
+
+func (m *Map) jsonLayer(jl *jsonLayer, parent group) error {
+	g := jl.group(parent)
+
+	switch jl.Type {
+	case "tilelayer":
+		if len(jl.Chunks) > 0 {
+			return ErrInfinite
+		}
+
+		l := &Layer{
+			Name:       jl.Name,
+			Width:      jl.Width,
+			Height:     jl.Height,
+			Visible:    g.visible,
+			Opacity:    g.opacity,
+			OffsetX:    g.offsetX,
+			OffsetY:    g.offsetY,
+			Properties: jsonProperties(jl.Properties),
+		}
+
+		var err error
+		if jl.Encoding == "base64" {
+			var s string
+			if err = json.Unmarshal(jl.Data, &s); err == nil {
+				l.GIDs, err = decodeData(jl.Encoding, jl.Compression, s, l.Width*l.Height)
+			}
+		} else if err = json.Unmarshal(jl.Data, &l.GIDs); err == nil && len(l.GIDs) != l.Width*l.Height {
+			err = errors.New("tilemap: layer data is the wrong size")
+		}
+		if err != nil {
+			return fmt.Errorf("layer %s: %w", l.Name, err)
+		}
+		m.Layers = append(m.Layers, l)
+
+	case "objectgroup":
+		m.ObjectGroups = append(m.ObjectGroups, jsonObjectGroup(jl, g))
+
+	case "group":
+		for i := range jl.Layers {
+			if err := m.jsonLayer(&jl.Layers[i], g); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func jsonObjectGroup(jl *jsonLayer, g group) *ObjectGroup {
+	og := &ObjectGroup{
+		Name:       jl.Name,
+		Visible:    g.visible,
+		Opacity:    g.opacity,
+		OffsetX:    g.offsetX,
+		OffsetY:    g.offsetY,
+		Properties: jsonProperties(jl.Properties),
+	}
+
+	for _, jo := range jl.Objects {
+		o := &Object{
+			ID:         jo.ID,
+			Name:       jo.Name,
+			Type:       jo.Type,
+			X:          jo.X,
+			Y:          jo.Y,
+			Width:      jo.Width,
+			Height:     jo.Height,
+			Rotation:   jo.Rotation,
+			GID:        jo.GID,
+			Visible:    jo.Visible == nil || *jo.Visible,
+			Ellipse:    jo.Ellipse,
+			Point:      jo.Point,
+			Properties: jsonProperties(jo.Properties),
+		}
+		if o.Type == "" {
+			o.Type = jo.Class
+		}
+		for _, p := range jo.Polygon {
+			o.Polygon = append(o.Polygon, vector.New(p.X, p.Y))
+		}
+		for _, p := range jo.Polyline {
+			o.Polyline = append(o.Polyline, vector.New(p.X, p.Y))
+		}
+		og.Objects = append(og.Objects, o)
+	}
+
+	return og
+}
+
+func jsonProperties(props []jsonProperty) Properties {
+	p := Properties{}
+	for _, jp := range props {
+		switch v := jp.Value.(type) {
+		case string:
+			p[jp.Name] = v
+		case float64:
+			p[jp.Name] = strconv.FormatFloat(v, 'g', -1, 64)
+		case bool:
+			p[jp.Name] = strconv.FormatBool(v)
+		case nil:
+			p[jp.Name] = ""
+		default:
+			p[jp.Name] = fmt.Sprint(v)
+		}
+	}
+
+	return p
+}
@@ -0,0 +1,105 @@
+package tilemap
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/losinggeneration/hge"
+	"github.com/losinggeneration/hge/gfx"
+	"github.com/losinggeneration/hge/resource"
+)
+
+func isJSON(filename string) bool {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".json", ".tmj", ".tsj":
+		return true
+	}
+
+	return false
+}
+
+// Loads a map, the tilesets in their own files and the tileset images. Maps
+// ending in .json or .tmj are read as JSON, anything else as TMX. The files
+// are read through the resource system, so they can be in an attached pack.
+func Load(filename string) (*Map, error) {
+	data, err := resource.LoadBytes(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var m *Map
+	if isJSON(filename) {
+		m, err = ParseJSON(data)
+	} else {
+		m, err = ParseTMX(data)
+	}
+	if err != nil {
+		return nil, formatError(filename, err)
+	}
+
+	dir := path.Dir(filename)
+	for i, ts := range m.Tilesets {
+		// Images are relative to the file the tileset is in
+		imageDir := dir
+
+		if ts.Source != "" {
+			source := path.Join(dir, ts.Source)
+			if ts, err = loadTileset(source, ts.FirstGID); err != nil {
+				m.Free()
+				return nil, err
+			}
+			ts.Source = m.Tilesets[i].Source
+			m.Tilesets[i] = ts
+			imageDir = path.Dir(source)
+		}
+
+		if ts.Image == "" {
+			m.Free()
+			return nil, formatError(filename, fmt.Errorf("tileset %s: %w", ts.Name, ErrImageCollection))
+		}
+
+		if ts.Texture, err = gfx.LoadTexture(path.Join(imageDir, ts.Image)); err != nil {
+			m.Free()
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+func loadTileset(filename string, firstGID int) (*Tileset, error) {
+	data, err := resource.LoadBytes(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var ts *Tileset
+	if isJSON(filename) {
+		ts, err = ParseTilesetJSON(data)
+	} else {
+		ts, err = ParseTSX(data)
+	}
+	if err != nil {
+		return nil, formatError(filename, err)
+	}
+	ts.FirstGID = firstGID
+
+	return ts, nil
+}
+
+// Returns a *hge.LoadError for a file that was read but can't be used. It's
+// hge.ErrFormat as well as the parser's error.
+func formatError(filename string, err error) error {
+	return tilemapHGE.NewLoadError("tilemap.Load", filename, fmt.Errorf("%w: %w", hge.ErrFormat, err))
+}
+
+// Frees the tileset textures.
+func (m *Map) Free() {
+	for _, ts := range m.Tilesets {
+		if ts.Texture != nil {
+			ts.Texture.Free()
+			ts.Texture = nil
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package tilemap
+
+import (
+	"math"
+
+	"github.com/losinggeneration/hge"
+	"github.com/losinggeneration/hge/camera"
+	"github.com/losinggeneration/hge/gfx"
+)
+
+var tilemapHGE *hge.HGE
+
+func init() {
+	tilemapHGE = hge.New()
+}
+
+// Moves animated tiles on by dt seconds.
+func (m *Map) Update(dt float64) {
+	m.time += dt
+}
+
+// Returns the size of the map in the world.
+func (m *Map) Size() (w, h float64) {
+	tw, th := float64(m.TileWidth), float64(m.TileHeight)
+	if m.Orientation == ISOMETRIC {
+		n := float64(m.Width + m.Height)
+		return n * tw / 2, n * th / 2
+	}
+
+	return float64(m.Width) * tw, float64(m.Height) * th
+}
+
+// Returns where tile coordinates are in the world. On orthogonal maps that's
+// the top left of the tile, on isometric maps its top corner. Add 0.5 to
+// each for the middle of the tile.
+func (m *Map) TileToWorld(tx, ty float64) (x, y float64) {
+	tw, th := float64(m.TileWidth), float64(m.TileHeight)
+	if m.Orientation == ISOMETRIC {
+		return (tx-ty)*tw/2 + float64(m.Height)*tw/2, (tx + ty) * th / 2
+	}
+
+	return tx * tw, ty * th
+}
+
+// Returns the tile coordinates of a point in the world. Round them down for
+// the tile the point is on.
+func (m *Map) WorldToTile(x, y float64) (tx, ty float64) {
+	tw, th := float64(m.TileWidth), float64(m.TileHeight)
+	if tw == 0 || th == 0 {
+		return 0, 0
+	}
+
+	if m.Orientation == ISOMETRIC {
+		x -= float64(m.Height) * tw / 2
+		return y/th + x/tw, y/th - x/tw
+	}
+
+	return x / tw, y / th
+}
+
+// Turns an object position as Tiled saves it into a world position. They're
+// the same on orthogonal maps, on isometric maps objects are placed along the
+// tile axes.
+func (m *Map) PixelToWorld(px, py float64) (x, y float64) {
+	if m.Orientation == ISOMETRIC && m.TileHeight > 0 {
+		th := float64(m.TileHeight)
+		return m.TileToWorld(px/th, py/th)
+	}
+
+	return px, py
+}
+
+// Draws the visible tile layers through the camera, only the tiles it can
+// see. A nil camera draws what's on the screen as it is.
+func (m *Map) Render(c *camera.Camera) {
+	for _, l := range m.Layers {
+		if l.Visible {
+			m.RenderLayer(l, c)
+		}
+	}
+}
+
+// Draws a tile layer through the camera, so things can be drawn between
+// layers.
+func (m *Map) RenderLayer(l *Layer, c *camera.Camera) {
+	if c == nil {
+		w, h := tilemapHGE.GetInt(hge.SCREENWIDTH), tilemapHGE.GetInt(hge.SCREENHEIGHT)
+		m.DrawLayer(l, 0, 0, float64(w), float64(h))
+		return
+	}
+
+	minX, minY, maxX, maxY := c.Visible()
+	if c.Rotation != 0 {
+		// A rotated view can see as far as its corners in any direction
+		cx, cy := (minX+maxX)/2, (minY+maxY)/2
+		r := math.Hypot(maxX-cx, maxY-cy)
+		minX, minY, maxX, maxY = cx-r, cy-r, cx+r, cy+r
+	}
+
+	c.Render(func() {
+		m.DrawLayer(l, minX, minY, maxX, maxY)
+	})
+}
+
+// Draws the tiles of a layer that overlap the part of the world from minX,
+// minY to maxX, maxY, with whatever transform is set.
+func (m *Map) DrawLayer(l *Layer, minX, minY, maxX, maxY float64) {
+	if m.TileWidth <= 0 || m.TileHeight <= 0 {
+		return
+	}
+
+	if m.batch == nil {
+		m.batch = gfx.NewSpriteBatch(0)
+	}
+	m.batch.Begin()
+	defer m.batch.End()
+
+	col := hge.Dword(0x00FFFFFF) | hge.Dword(math.Max(0, math.Min(1, l.Opacity))*255)<<24
+
+	// Tiles bigger than the grid reach up and right from their cell
+	overX, overY := m.overhang()
+	minX -= l.OffsetX + overX
+	maxX -= l.OffsetX - overX
+	minY -= l.OffsetY + overY
+	maxY -= l.OffsetY - overY
+
+	var x0, y0, x1, y1 int
+	if m.Orientation == ISOMETRIC {
+		// The tiles under each corner bound the ones to draw
+		fx0, fy0 := math.Inf(1), math.Inf(1)
+		fx1, fy1 := math.Inf(-1), math.Inf(-1)
+		for _, p := range [4][2]float64{{minX, minY}, {maxX, minY}, {maxX, maxY}, {minX, maxY}} {
+			tx, ty := m.WorldToTile(p[0], p[1])
+			fx0, fy0 = math.Min(fx0, tx), math.Min(fy0, ty)
+			fx1, fy1 = math.Max(fx1, tx), math.Max(fy1, ty)
+		}
+		x0, y0, x1, y1 = int(math.Floor(fx0)), int(math.Floor(fy0)), int(math.Floor(fx1)), int(math.Floor(fy1))
+	} else {
+		tw, th := float64(m.TileWidth), float64(m.TileHeight)
+		x0, y0 = int(math.Floor(minX/tw)), int(math.Floor(minY/th))
+		x1, y1 = int(math.Floor(maxX/tw)), int(math.Floor(maxY/th))
+	}
+
+	x0, y0 = max(x0, 0), max(y0, 0)
+	x1, y1 = min(x1, l.Width-1), min(y1, l.Height-1)
+
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			gid := l.GIDs[y*l.Width+x]
+			if gid&GID_MASK == 0 {
+				continue
+			}
+
+			// Tiles are drawn from the bottom left of their cell
+			wx, wy := m.TileToWorld(float64(x), float64(y)+1)
+			if m.Orientation == ISOMETRIC {
+				wx, wy = m.TileToWorld(float64(x)+1, float64(y)+1)
+				wx -= float64(m.TileWidth) / 2
+			}
+
+			m.drawTile(gid, wx+l.OffsetX, wy+l.OffsetY, col)
+		}
+	}
+}
+
+// Returns how far the tilesets' tiles can reach past a cell.
+func (m *Map) overhang() (x, y float64) {
+	for _, ts := range m.Tilesets {
+		x = math.Max(x, float64(ts.TileWidth-m.TileWidth+abs(ts.OffsetX)))
+		y = math.Max(y, float64(ts.TileHeight-m.TileHeight+abs(ts.OffsetY)))
+	}
+
+	return x, y
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+
+	return i
+}
+
+// Returns the tile to show for an animated tile at the map's time.
+func (m *Map) frame(ts *Tileset, id int) int {
+	t := ts.Tiles[id]
+	if t == nil || len(t.Animation) == 0 {
+		return id
+	}
+
+	var total float64
+	for _, f := range t.Animation {
+		total += f.Duration
+	}
+	if total <= 0 {
+		return t.Animation[0].TileID
+	}
+
+	at := math.Mod(m.time, total)
+	for _, f := range t.Animation {
+		if at < f.Duration {
+			return f.TileID
+		}
+		at -= f.Duration
+	}
+
+	return t.Animation[len(t.Animation)-1].TileID
+}
+
+// Draws a tile with the bottom left of its image at x, y.
+func (m *Map) drawTile(gid uint32, x, y float64, col hge.Dword) {
+	ts, id := m.Tileset(gid)
+	if ts == nil || ts.Texture == nil || ts.TileWidth <= 0 || ts.TileHeight <= 0 {
+		return
+	}
+	id = m.frame(ts, id)
+
+	columns := ts.Columns
+	if columns <= 0 {
+		columns = (ts.ImageWidth - 2*ts.Margin + ts.Spacing) / (ts.TileWidth + ts.Spacing)
+	}
+	columns = max(columns, 1)
+
+	tw, th := float64(ts.TileWidth), float64(ts.TileHeight)
+	texW, texH := float64(ts.Texture.Width()), float64(ts.Texture.Height())
+	u := float64(ts.Margin + (id%columns)*(ts.TileWidth+ts.Spacing))
+	v := float64(ts.Margin + (id/columns)*(ts.TileHeight+ts.Spacing))
+	u1, v1 := float32(u/texW), float32(v/texH)
+	u2, v2 := float32((u+tw)/texW), float32((v+th)/texH)
+
+	// Top left, top right, bottom right, bottom left
+	uv := [4][2]float32{{u1, v1}, {u2, v1}, {u2, v2}, {u1, v2}}
+	if gid&FLIP_DIAGONAL != 0 {
+		uv[1], uv[3] = uv[3], uv[1]
+	}
+	if gid&FLIP_HORIZONTAL != 0 {
+		uv[0], uv[1], uv[2], uv[3] = uv[1], uv[0], uv[3], uv[2]
+	}
+	if gid&FLIP_VERTICAL != 0 {
+		uv[0], uv[1], uv[2], uv[3] = uv[3], uv[2], uv[1], uv[0]
+	}
+
+	x += float64(ts.OffsetX)
+	y += float64(ts.OffsetY)
+	pos := [4][2]float64{{x, y - th}, {x + tw, y - th}, {x + tw, y}, {x, y}}
+
+	var q [4]gfx.Vertex
+	for i := range q {
+		q[i] = gfx.Vertex{
+			X:     float32(pos[i][0]),
+			Y:     float32(pos[i][1]),
+			Z:     0.5,
+			Color: col,
+			TX:    uv[i][0],
+			TY:    uv[i][1],
+		}
+	}
+	m.batch.AddVertices(q, ts.Texture, gfx.BLEND_DEFAULT)
+}
@@ -0,0 +1,4 @@
+<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" name="more" tilewidth="16" tileheight="16" tilecount="4" columns="2">
+ <image source="tiles.png" width="64" height="32"/>
+</tileset>
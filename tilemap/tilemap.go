@@ -0,0 +1,264 @@
+// Package tilemap loads maps made with the Tiled editor, in its TMX and JSON
+// formats, and draws them.
+//
+// Orthogonal and isometric maps are supported, with any number of tile
+// layers and object layers, tilesets embedded in the map or kept in their
+// own files, custom properties and animated tiles. Group layers are
+// flattened into the layers they hold. Infinite maps and tilesets made of a
+// collection of images aren't supported.
+package tilemap
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/losinggeneration/hge"
+	"github.com/losinggeneration/hge/gfx"
+	"github.com/losinggeneration/hge/helpers/vector"
+)
+
+// Map orientations.
+const (
+	ORTHOGONAL = "orthogonal"
+	ISOMETRIC  = "isometric"
+)
+
+// The top bits of a GID say how the tile is flipped. Diagonal flipping
+// swaps the tile's x and y axes, and is done before the others.
+const (
+	FLIP_HORIZONTAL uint32 = 0x80000000
+	FLIP_VERTICAL   uint32 = 0x40000000
+	FLIP_DIAGONAL   uint32 = 0x20000000
+
+	// The bits left for the tile itself
+	GID_MASK uint32 = 0x0FFFFFFF
+)
+
+var (
+	ErrInfinite = errors.New("tilemap: infinite maps aren't supported")
+	ErrEncoding = errors.New("tilemap: unsupported layer data encoding")
+
+	ErrImageCollection = errors.New("tilemap: image collection tilesets aren't supported")
+)
+
+// Custom properties set in Tiled. Values are kept as the text Tiled saves,
+// whatever their type.
+type Properties map[string]string
+
+// Returns the property as an int, or def if it isn't set or isn't a number.
+func (p Properties) Int(name string, def int) int {
+	if i, err := strconv.Atoi(p[name]); err == nil {
+		return i
+	}
+
+	return def
+}
+
+// Returns the property as a float64, or def if it isn't set or isn't a
+// number.
+func (p Properties) Float(name string, def float64) float64 {
+	if f, err := strconv.ParseFloat(p[name], 64); err == nil {
+		return f
+	}
+
+	return def
+}
+
+// Returns the property as a bool, or def if it isn't set or isn't a bool.
+func (p Properties) Bool(name string, def bool) bool {
+	if b, err := strconv.ParseBool(p[name]); err == nil {
+		return b
+	}
+
+	return def
+}
+
+// A map made of layers of tiles and objects.
+type Map struct {
+	Orientation string
+	// The size of the map in tiles
+	Width, Height int
+	// The size of the grid the tiles are laid out on
+	TileWidth, TileHeight int
+	// The color drawn behind the map, 0 if Tiled has none set
+	Background hge.Dword
+	Properties Properties
+
+	// Sorted by FirstGID
+	Tilesets []*Tileset
+	// Tile layers, in the order they're drawn
+	Layers []*Layer
+	// Object layers, in the order they appear in Tiled
+	ObjectGroups []*ObjectGroup
+
+	time  float64
+	batch *gfx.SpriteBatch
+}
+
+// A tileset cuts an image into tiles.
+type Tileset struct {
+	// The GID of the first tile, the rest follow on from it
+	FirstGID int
+	// The file the tileset was loaded from, when it isn't embedded in the map
+	Source string
+	Name   string
+
+	TileWidth, TileHeight int
+	Spacing, Margin       int
+	TileCount, Columns    int
+	// Moves where tiles are drawn
+	OffsetX, OffsetY int
+
+	Image                   string
+	ImageWidth, ImageHeight int
+	Texture                 *gfx.Texture
+
+	// Tiles with properties, animations or collision shapes, by their ID in
+	// the tileset
+	Tiles map[int]*Tile
+}
+
+// What's known about a tile besides its picture.
+type Tile struct {
+	ID         int
+	Type       string
+	Properties Properties
+	Animation  []Frame
+	// Collision shapes drawn in Tiled's tile collision editor, relative to
+	// the tile's top left
+	Collision *ObjectGroup
+}
+
+// A frame of an animated tile.
+type Frame struct {
+	TileID int
+	// In seconds
+	Duration float64
+}
+
+// A layer of tiles.
+type Layer struct {
+	Name             string
+	Width, Height    int
+	Visible          bool
+	Opacity          float64
+	OffsetX, OffsetY float64
+	Properties       Properties
+	// GIDs, with the flip flags, a row at a time. 0 is no tile.
+	GIDs []uint32
+}
+
+// Returns the GID at tile x, y, or 0 if it's outside the layer.
+func (l *Layer) GID(x, y int) uint32 {
+	if x < 0 || y < 0 || x >= l.Width || y >= l.Height {
+		return 0
+	}
+
+	return l.GIDs[y*l.Width+x]
+}
+
+// A layer of objects, for things like spawn points and collision.
+type ObjectGroup struct {
+	Name             string
+	Visible          bool
+	Opacity          float64
+	OffsetX, OffsetY float64
+	Properties       Properties
+	Objects          []*Object
+}
+
+// Returns the first object with the name, or nil if there isn't one.
+func (g *ObjectGroup) Object(name string) *Object {
+	for _, o := range g.Objects {
+		if o.Name == name {
+			return o
+		}
+	}
+
+	return nil
+}
+
+// Returns the objects of a type, or class as newer versions of Tiled call
+// it.
+func (g *ObjectGroup) ObjectsOfType(typ string) []*Object {
+	var objects []*Object
+	for _, o := range g.Objects {
+		if o.Type == typ {
+			objects = append(objects, o)
+		}
+	}
+
+	return objects
+}
+
+// An object placed on an object layer. Positions are in pixels as Tiled
+// saves them, for isometric maps that's along the tile axes. Use
+// Map.PixelToWorld to turn them into world positions.
+type Object struct {
+	ID         int
+	Name, Type string
+	X, Y       float64
+	// Zero for points, and for polygons and polylines
+	Width, Height float64
+	// In degrees clockwise
+	Rotation float64
+	// The tile shown by a tile object, 0 for other objects
+	GID     uint32
+	Visible bool
+
+	Ellipse, Point bool
+	// Points relative to X, Y
+	Polygon, Polyline []vector.Vector
+
+	Properties Properties
+}
+
+// Returns the tile layer with the name, or nil if there isn't one.
+func (m *Map) Layer(name string) *Layer {
+	for _, l := range m.Layers {
+		if l.Name == name {
+			return l
+		}
+	}
+
+	return nil
+}
+
+// Returns the object layer with the name, or nil if there isn't one.
+func (m *Map) ObjectGroup(name string) *ObjectGroup {
+	for _, g := range m.ObjectGroups {
+		if g.Name == name {
+			return g
+		}
+	}
+
+	return nil
+}
+
+// Returns the tileset a GID comes from and the tile's ID in it, or nil if no
+// tileset has it.
+func (m *Map) Tileset(gid uint32) (*Tileset, int) {
+	id := int(gid & GID_MASK)
+	if id == 0 {
+		return nil, 0
+	}
+
+	for i := len(m.Tilesets) - 1; i >= 0; i-- {
+		if ts := m.Tilesets[i]; id >= ts.FirstGID {
+			return ts, id - ts.FirstGID
+		}
+	}
+
+	return nil, 0
+}
+
+// Returns what's known about the tile with the GID, or nil if it has no
+// properties, animation or collision.
+func (m *Map) Tile(gid uint32) *Tile {
+	ts, id := m.Tileset(gid)
+	if ts == nil {
+		return nil
+	}
+
+	return ts.Tiles[id]
+}
@@ -0,0 +1,169 @@
+package tilemap
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/losinggeneration/hge"
+	"github.com/losinggeneration/hge/helpers/vector"
+	"github.com/losinggeneration/hge/hgetest"
+)
+
+// The layer every fixture map has, in each of the encodings.
+var fixtureGIDs = []uint32{
+	1, 2, 3, 4,
+	5 | FLIP_HORIZONTAL, 6 | FLIP_VERTICAL, 7 | FLIP_DIAGONAL, 0,
+	1 | FLIP_HORIZONTAL | FLIP_VERTICAL | FLIP_DIAGONAL, 2, 3, 4,
+}
+
+func parseFixture(t *testing.T, name string) (*Map, error) {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isJSON(name) {
+		return ParseJSON(data)
+	}
+
+	return ParseTMX(data)
+}
+
+func TestParseFixtures(t *testing.T) {
+	for _, name := range []string{
+		"csv.tmx", "xml.tmx", "base64.tmx", "gzip.tmx", "zlib.tmx",
+		"csv.json", "base64.json", "gzip.json", "zlib.json",
+	} {
+		m, err := parseFixture(t, name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+
+		if m.Orientation != ORTHOGONAL || m.Width != 4 || m.Height != 3 || m.TileWidth != 16 || m.TileHeight != 16 {
+			t.Errorf("%s: map is %s %dx%d of %dx%d tiles", name, m.Orientation, m.Width, m.Height, m.TileWidth, m.TileHeight)
+		}
+		if m.Background != 0xFF336699 {
+			t.Errorf("%s: background %#x, want 0xFF336699", name, m.Background)
+		}
+		if m.Properties["music"] != "level1.ogg" || m.Properties.Float("gravity", 0) != 9.8 {
+			t.Errorf("%s: properties %v", name, m.Properties)
+		}
+
+		l := m.Layer("ground")
+		if l == nil || len(m.Layers) != 1 {
+			t.Errorf("%s: %d layers and no ground", name, len(m.Layers))
+			continue
+		}
+		if !reflect.DeepEqual(l.GIDs, fixtureGIDs) {
+			t.Errorf("%s: GIDs %v, want %v", name, l.GIDs, fixtureGIDs)
+		}
+		if gid := l.GID(2, 1); gid&FLIP_DIAGONAL == 0 || gid&GID_MASK != 7 {
+			t.Errorf("%s: GID at 2,1 is %#x, want 7 flipped diagonally", name, gid)
+		}
+		if gid := l.GID(0, 2); gid&^GID_MASK != FLIP_HORIZONTAL|FLIP_VERTICAL|FLIP_DIAGONAL {
+			t.Errorf("%s: GID at 0,2 is %#x, want every flip", name, gid)
+		}
+		if l.GID(4, 0) != 0 || l.GID(0, -1) != 0 {
+			t.Errorf("%s: GIDs outside the layer aren't 0", name)
+		}
+
+		if len(m.Tilesets) != 2 {
+			t.Errorf("%s: %d tilesets, want 2", name, len(m.Tilesets))
+			continue
+		}
+		ts := m.Tilesets[0]
+		if ts.Name != "tiles" || ts.Image != "tiles.png" || ts.TileCount != 8 || ts.Columns != 4 {
+			t.Errorf("%s: tileset %+v", name, ts)
+		}
+		if m.Tilesets[1].FirstGID != 9 || m.Tilesets[1].Source == "" {
+			t.Errorf("%s: external tileset %+v", name, m.Tilesets[1])
+		}
+
+		// Flip flags don't change which tile it is
+		tile := m.Tile(3 | FLIP_HORIZONTAL)
+		want := []Frame{{2, 0.1}, {3, 0.25}}
+		if tile == nil || tile.Type != "water" || !reflect.DeepEqual(tile.Animation, want) {
+			t.Errorf("%s: tile 3 is %+v, want water animated with %v", name, tile, want)
+		}
+		if ts, id := m.Tileset(10 | FLIP_VERTICAL); ts != m.Tilesets[1] || id != 1 {
+			t.Errorf("%s: GID 10 is tile %d of %v", name, id, ts)
+		}
+
+		og := m.ObjectGroup("spawns")
+		if og == nil || og.OffsetX != 2 || og.OffsetY != 3 || len(og.Objects) != 2 {
+			t.Errorf("%s: spawns %+v", name, og)
+			continue
+		}
+		if p := og.Object("player"); p == nil || p.Type != "spawn" || p.X != 8 || p.Y != 24 {
+			t.Errorf("%s: player %+v", name, p)
+		}
+		poly := []vector.Vector{vector.New(0, 0), vector.New(32, 0), vector.New(16, 16)}
+		if z := og.Object("zone"); z == nil || !reflect.DeepEqual(z.Polygon, poly) {
+			t.Errorf("%s: zone %+v, want the polygon %v", name, z, poly)
+		}
+	}
+}
+
+func TestParseWrongSize(t *testing.T) {
+	for _, name := range []string{"wrongsize.tmx", "wrongsize_zlib.tmx", "wrongsize.json", "wrongsize_gzip.json"} {
+		if _, err := parseFixture(t, name); err == nil || !strings.Contains(err.Error(), "wrong size") {
+			t.Errorf("%s: %v, want the layer to be the wrong size", name, err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	for _, name := range []string{"testdata/gzip.tmx", "testdata/gzip.json"} {
+		var m *Map
+		_, err := hgetest.Render(hgetest.Scene{
+			Width: 64, Height: 48,
+			Setup: func() (err error) {
+				m, err = Load(name)
+				return err
+			},
+			Render: func(frame int) {
+				m.DrawLayer(m.Layers[0], 0, 0, 64, 48)
+			},
+		})
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+
+		more := m.Tilesets[1]
+		if more.Name != "more" || more.FirstGID != 9 || more.TileCount != 4 || more.Source == "" {
+			t.Errorf("%s: external tileset %+v", name, more)
+		}
+		for _, ts := range m.Tilesets {
+			if ts.Texture == nil {
+				t.Errorf("%s: tileset %s has no texture", name, ts.Name)
+			}
+		}
+		m.Free()
+	}
+}
+
+func TestLoadBadMap(t *testing.T) {
+	for _, name := range []string{"testdata/wrongsize.tmx", "testdata/wrongsize_gzip.json"} {
+		_, err := hgetest.Render(hgetest.Scene{
+			Width: 8, Height: 8,
+			Setup: func() error {
+				_, err := Load(name)
+				return err
+			},
+		})
+
+		var le *hge.LoadError
+		if !errors.As(err, &le) || le.Filename != name || !errors.Is(err, hge.ErrFormat) {
+			t.Errorf("%s: %v, want a LoadError with ErrFormat", name, err)
+		} else if !strings.Contains(err.Error(), "wrong size") {
+			t.Errorf("%s: %v doesn't say why", name, err)
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package tilemap
+
+import (
+	"encoding/xml"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/losinggeneration/hge/helpers/vector"
+)
+
+// TMX is read into a tree of elements, so layers and object layers keep the
+// order they're in.
+type xmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Text    string     `xml:",chardata"`
+	Nodes   []xmlNode  `xml:",any"`
+}
+
+func (n *xmlNode) attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+
+	return ""
+}
+
+func (n *xmlNode) int(name string, def int) int {
+	if i, err := strconv.Atoi(n.attr(name)); err == nil {
+		return i
+	}
+
+	return def
+}
+
+func (n *xmlNode) float(name string, def float64) float64 {
+	if f, err := strconv.ParseFloat(n.attr(name), 64); err == nil {
+		return f
+	}
+
+	return def
+}
+
+func (n *xmlNode) child(name string) *xmlNode {
+	for i := range n.Nodes {
+		if n.Nodes[i].XMLName.Local == name {
+			return &n.Nodes[i]
+		}
+	}
+
+	return nil
+}
+
+// Parses a map in Tiled's TMX format. Tilesets in their own files are left
+// with only FirstGID and Source set, and no tileset has its texture loaded;
+// Load does both.
+func ParseTMX(data []byte) (*Map, error) {
+	var root xmlNode
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if root.XMLName.Local != "map" {
+		return nil, errors.New("tilemap: not a TMX map")
+	}
+	if root.int("infinite", 0) != 0 {
+		return nil, ErrInfinite
+	}
+
+	m := &Map{
+		Orientation: root.attr("orientation"),
+		Width:       root.int("width", 0),
+		Height:      root.int("height", 0),
+		TileWidth:   root.int("tilewidth", 0),
+		TileHeight:  root.int("tileheight", 0),
+		Background:  parseColor(root.attr("backgroundcolor")),
+		Properties:  Properties{},
+	}
+	if m.Orientation == "" {
+		m.Orientation = ORTHOGONAL
+	}
+
+	for i := range root.Nodes {
+		n := &root.Nodes[i]
+		switch n.XMLName.Local {
+		case "properties":
+			m.Properties = tmxProperties(n)
+		case "tileset":
+			ts := &Tileset{FirstGID: n.int("firstgid", 1), Source: n.attr("source")}
+			if err := tmxTileset(ts, n); err != nil {
+				return nil, err
+			}
+			m.Tilesets = append(m.Tilesets, ts)
+		default:
+			if err := m.tmxLayer(n, group{visible: true, opacity: 1}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sort.Slice(m.Tilesets, func(i, j int) bool { return m.Tilesets[i].FirstGID < m.Tilesets[j].FirstGID })
+
+	return m, nil
+}
+
+// Parses a tileset in its own TSX file.
+func ParseTSX(data []byte) (*Tileset, error) {
+	var root xmlNode
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if root.XMLName.Local != "tileset" {
+		return nil, errors.New("tilemap: not a TSX tileset")
+	}
+
+	ts := &Tileset{FirstGID: 1}
+	if err := tmxTileset(ts, &root); err != nil {
+		return nil, err
+	}
+
+	return ts, nil
+}
+
+// What a group layer passes on to the layers in it.
+type group struct {
+	offsetX, offsetY float64
+	opacity          float64
+	visible          bool
+}
+
+func (g group) nest(n *xmlNode) group {
+	return group{
+		offsetX: g.offsetX + n.float("offsetx", 0),
+		offsetY: g.offsetY + n.float("offsety", 0),
+		opacity: g.opacity * n.float("opacity", 1),
+		visible: g.visible && n.int("visible", 1) != 0,
+	}
+}
+
+func (m *Map) tmxLayer(n *xmlNode, parent group) error {
+	g := parent.nest(n)
+
+	switch n.XMLName.Local {
+	case "layer":
+		l := &Layer{
+			Name:       n.attr("name"),
+			Width:      n.int("width", m.Width),
+			Height:     n.int("height", m.Height),
+			Visible:    g.visible,
+			Opacity:    g.opacity,
+			OffsetX:    g.offsetX,
+			OffsetY:    g.offsetY,
+			Properties: Properties{},
+		}
+		if p := n.child("properties"); p != nil {
+			l.Properties = tmxProperties(p)
+		}
+
+		d := n.child("data")
+		if d == nil {
+			return errors.New("tilemap: layer " + l.Name + " has no data")
+		}
+		if d.child("chunk") != nil {
+			return ErrInfinite
+		}
+
+		var err error
+		if enc := d.attr("encoding"); enc != "" {
+			l.GIDs, err = decodeData(enc, d.attr("compression"), d.Text, l.Width*l.Height)
+		} else {
+			l.GIDs, err = tmxTiles(d, l.Width*l.Height)
+		}
+		if err != nil {
+			return err
+		}
+		m.Layers = append(m.Layers, l)
+
+	case "objectgroup":
+		og, err := tmxObjectGroup(n)
+		if err != nil {
+			return err
+		}
+		og.Visible, og.Opacity = g.visible, g.opacity
+		og.OffsetX, og.OffsetY = g.offsetX, g.offsetY
+		m.ObjectGroups = append(m.ObjectGroups, og)
+
+	case "group":
+		for i := range n.Nodes {
+			if err := m.tmxLayer(&n.Nodes[i], g); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Reads layer data saved as a <tile> element for each tile.
+func tmxTiles(d *xmlNode, size int) ([]uint32, error) {
+	gids := make([]uint32, 0, size)
+	for i := range d.Nodes {
+		if t := &d.Nodes[i]; t.XMLName.Local == "tile" {
+			gid, _ := strconv.ParseUint(t.attr("gid"), 10, 32)
+			gids = append(gids, uint32(gid))
+		}
+	}
+
+	if len(gids) != size {
+		return nil, errors.New("tilemap: layer data is the wrong size")
+	}
+
+	return gids, nil
+}
+
+func tmxTileset(ts *Tileset, n *xmlNode) error {
+	if ts.Source != "" && n.attr("name") == "" {
+		// In a file of its own, Load reads it
+		return nil
+	}
+
+	ts.Name = n.attr("name")
+	ts.TileWidth = n.int("tilewidth", 0)
+	ts.TileHeight = n.int("tileheight", 0)
+	ts.Spacing = n.int("spacing", 0)
+	ts.Margin = n.int("margin", 0)
+	ts.TileCount = n.int("tilecount", 0)
+	ts.Columns = n.int("columns", 0)
+	ts.Tiles = make(map[int]*Tile)
+
+	for i := range n.Nodes {
+		c := &n.Nodes[i]
+		switch c.XMLName.Local {
+		case "tileoffset":
+			ts.OffsetX, ts.OffsetY = c.int("x", 0), c.int("y", 0)
+
+		case "image":
+			ts.Image = c.attr("source")
+			ts.ImageWidth, ts.ImageHeight = c.int("width", 0), c.int("height", 0)
+
+		case "tile":
+			t := &Tile{ID: c.int("id", 0), Type: c.attr("type"), Properties: Properties{}}
+			if t.Type == "" {
+				t.Type = c.attr("class")
+			}
+			if p := c.child("properties"); p != nil {
+				t.Properties = tmxProperties(p)
+			}
+			if a := c.child("animation"); a != nil {
+				for j := range a.Nodes {
+					f := &a.Nodes[j]
+					t.Animation = append(t.Animation, Frame{f.int("tileid", 0), f.float("duration", 0) / 1000})
+				}
+			}
+			if og := c.child("objectgroup"); og != nil {
+				var err error
+				if t.Collision, err = tmxObjectGroup(og); err != nil {
+					return err
+				}
+			}
+			ts.Tiles[t.ID] = t
+		}
+	}
+
+	return nil
+}
+
+func tmxObjectGroup(n *xmlNode) (*ObjectGroup, error) {
+	og := &ObjectGroup{
+		Name:       n.attr("name"),
+		Visible:    n.int("visible", 1) != 0,
+		Opacity:    n.float("opacity", 1),
+		OffsetX:    n.float("offsetx", 0),
+		OffsetY:    n.float("offsety", 0),
+		Properties: Properties{},
+	}
+
+	for i := range n.Nodes {
+		c := &n.Nodes[i]
+		switch c.XMLName.Local {
+		case "properties":
+			og.Properties = tmxProperties(c)
+
+		case "object":
+			o := &Object{
+				ID:         c.int("id", 0),
+				Name:       c.attr("name"),
+				Type:       c.attr("type"),
+				X:          c.float("x", 0),
+				Y:          c.float("y", 0),
+				Width:      c.float("width", 0),
+				Height:     c.float("height", 0),
+				Rotation:   c.float("rotation", 0),
+				Visible:    c.int("visible", 1) != 0,
+				Properties: Properties{},
+			}
+			if o.Type == "" {
+				o.Type = c.attr("class")
+			}
+			gid, _ := strconv.ParseUint(c.attr("gid"), 10, 32)
+			o.GID = uint32(gid)
+
+			for j := range c.Nodes {
+				s := &c.Nodes[j]
+				switch s.XMLName.Local {
+				case "properties":
+					o.Properties = tmxProperties(s)
+				case "ellipse":
+					o.Ellipse = true
+				case "point":
+					o.Point = true
+				case "polygon":
+					o.Polygon = parsePoints(s.attr("points"))
+				case "polyline":
+					o.Polyline = parsePoints(s.attr("points"))
+				}
+			}
+			og.Objects = append(og.Objects, o)
+		}
+	}
+
+	return og, nil
+}
+
+func tmxProperties(n *xmlNode) Properties {
+	p := Properties{}
+	for i := range n.Nodes {
+		c := &n.Nodes[i]
+		if c.XMLName.Local != "property" {
+			continue
+		}
+
+		// Text with more than one line is saved inside the element
+		v := c.attr("value")
+		if v == "" {
+			v = c.Text
+		}
+		p[c.attr("name")] = v
+	}
+
+	return p
+}
+
+// Parses points saved as "x,y x,y ...".
+func parsePoints(s string) []vector.Vector {
+	var points []vector.Vector
+	for _, f := range strings.Fields(s) {
+		x, y, _ := strings.Cut(f, ",")
+		px, _ := strconv.ParseFloat(x, 64)
+		py, _ := strconv.ParseFloat(y, 64)
+		points = append(points, vector.New(px, py))
+	}
+
+	return points
+}
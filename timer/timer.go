@@ -1,11 +1,5 @@
 package timer
 
-/*
-#cgo pkg-config: hge-unix-c
-#include "hge_c.h"
-*/
-import "C"
-
 import "github.com/losinggeneration/hge"
 
 var timerHGE *hge.HGE
@@ -15,13 +9,13 @@ func init() {
 }
 
 func Time() float64 {
-	return float64(C.HGE_Timer_GetTime(timerHGE.HGE))
+	return timerHGE.Backend().Time()
 }
 
 func Delta() float64 {
-	return float64(C.HGE_Timer_GetDelta(timerHGE.HGE))
+	return timerHGE.Backend().Delta()
 }
 
 func GetFPS() int {
-	return int(C.HGE_Timer_GetFPS(timerHGE.HGE))
+	return timerHGE.Backend().FPS()
 }
@@ -181,6 +181,6 @@ func main() {
 		// Let's rock now!
 		h.System_Start()
 	} else {
-		fmt.Println("Error: %s\n", h.System_GetErrorMessage())
+		fmt.Println("Error:", h.System_GetErrorMessage())
 	}
 }
@@ -119,10 +119,14 @@ func main() {
 		if err := hge.Initiate(); err == nil {
 			defer hge.Shutdown()
 
-			snd = NewEffect("menu.ogg")
-			tex := LoadTexture("particles.png")
-			if snd == nil || tex == nil {
-				fmt.Printf("Error: Can't load one of the following files:\nmenu.ogg, particles.png, font1.fnt, font1.png, trail.psi\n")
+			var err error
+			if snd, err = NewEffect("menu.ogg"); err != nil {
+				fmt.Println("Error:", err)
+				return
+			}
+			tex, err := LoadTexture("particles.png")
+			if err != nil {
+				fmt.Println("Error:", err)
 				return
 			}
 
@@ -130,8 +134,8 @@ func main() {
 			spr.SetColor(0xFFFFA000)
 			spr.SetHotSpot(16, 16)
 
-			if fnt = font.New("font1.fnt"); fnt == nil {
-				fmt.Println("Error loading font1.fnt")
+			if fnt, err = font.New("font1.fnt"); err != nil {
+				fmt.Println("Error:", err)
 				return
 			}
 
@@ -139,9 +143,8 @@ func main() {
 			spt.SetBlendMode(BLEND_COLORMUL | BLEND_ALPHAADD | BLEND_NOZWRITE)
 			spt.SetHotSpot(16, 16)
 
-			par = particle.New("trail.psi", spt)
-			if par == nil {
-				fmt.Println("Error loading trail.psi")
+			if par, err = particle.New("trail.psi", spt); err != nil {
+				fmt.Println("Error:", err)
 				return
 			}
 			par.Fire()
@@ -144,12 +144,14 @@ func main() {
 
 	if err := h.Initiate(); err == nil {
 		defer h.Shutdown()
-		snd = NewEffect("menu.ogg")
-		tex = LoadTexture("particles.png")
-		if snd == nil || tex == nil {
-			// If one of the data files is not found, display
-			// an error message and shutdown.
-			fmt.Printf("Error: Can't load one of the following files:\nmenu.ogg, particles.png, font1.fnt, font1.png, trail.psi\n")
+		// If one of the data files can't be loaded, display
+		// an error message and shutdown.
+		if snd, err = NewEffect("menu.ogg"); err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		if tex, err = LoadTexture("particles.png"); err != nil {
+			fmt.Println("Error:", err)
 			return
 		}
 
@@ -161,20 +163,16 @@ func main() {
 		spr.SetColor(0xFFFFA000)
 		spr.SetHotSpot(16, 16)
 
-		fnt = font.New("font1.fnt")
-
-		if fnt == nil {
-			fmt.Println("Error: Can't load font1.fnt or font1.png")
+		if fnt, err = font.New("font1.fnt"); err != nil {
+			fmt.Println("Error:", err)
 			return
 		}
 
 		spt = sprite.New(tex, 32, 32, 32, 32)
 		spt.SetBlendMode(BLEND_COLORMUL | BLEND_ALPHAADD | BLEND_NOZWRITE)
 		spt.SetHotSpot(16, 16)
-		par = particle.New("trail.psi", spt)
-
-		if par == nil {
-			fmt.Println("Error: Cannot load trail.psi")
+		if par, err = particle.New("trail.psi", spt); err != nil {
+			fmt.Println("Error:", err)
 			return
 		}
 		par.Fire()
@@ -111,9 +111,8 @@ func main() {
 
 	if err := h.Initiate(); err == nil {
 		defer h.Shutdown()
-		tex = LoadTexture("texture.jpg")
-		if tex == nil {
-			fmt.Println("Error: Can't load texture.jpg")
+		if tex, err = LoadTexture("texture.jpg"); err != nil {
+			fmt.Println("Error:", err)
 			return
 		}
 		defer tex.Free()
@@ -125,10 +124,8 @@ func main() {
 		dis.Clear(Dword(0xFF000000))
 
 		// Load a font
-		fnt = font.New("font1.fnt")
-
-		if fnt == nil {
-			fmt.Println("Error: Can't load font1.fnt or font1.png")
+		if fnt, err = font.New("font1.fnt"); err != nil {
+			fmt.Println("Error:", err)
 			return
 		}
 
@@ -90,24 +90,20 @@ func main() {
 	} else {
 		defer h.Shutdown()
 
-		quad.Texture = LoadTexture("bg.png")
-
-		if quad.Texture == nil {
-			fmt.Println("Error loading bg.png")
+		if quad.Texture, err = LoadTexture("bg.png"); err != nil {
+			fmt.Println("Error:", err)
 			return
 		}
 
-		snd := NewEffect("menu.ogg")
-
-		if snd == nil {
-			fmt.Println("Error loading menu.ogg")
+		snd, err := NewEffect("menu.ogg")
+		if err != nil {
+			fmt.Println("Error:", err)
 			return
 		}
 
-		cursorTex := LoadTexture("cursor.png")
-
-		if cursorTex == nil {
-			fmt.Println("Error loading cursor.png")
+		cursorTex, err := LoadTexture("cursor.png")
+		if err != nil {
+			fmt.Println("Error:", err)
 			return
 		}
 
@@ -126,10 +122,8 @@ func main() {
 		quad.V[2].X, quad.V[2].Y = 800, 600
 		quad.V[3].X, quad.V[3].Y = 0, 600
 
-		fnt = font.New("font1.fnt")
-
-		if fnt == nil {
-			fmt.Println("Error loading font1.fnt")
+		if fnt, err = font.New("font1.fnt"); err != nil {
+			fmt.Println("Error:", err)
 			return
 		}
 
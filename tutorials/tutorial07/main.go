@@ -147,7 +147,7 @@ func main() {
 		bgtex = h.Texture_Load("bg2.png")
 		tex = h.Texture_Load("zazaka.png")
 		if bgtex == nil || tex == nil {
-			fmt.Println("Error: Can't load bg2.png or zazaka.png\n")
+			fmt.Println("Error: Can't load bg2.png or zazaka.png")
 			return
 		}
 		// Delete created objects and free loaded resources
@@ -155,7 +155,11 @@ func main() {
 		defer h.Texture_Free(bgtex)
 
 		// Load font, create sprites
-		fnt = font.New("font1.fnt")
+		var err error
+		if fnt, err = font.New("font1.fnt"); err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
 		spr = sprite.New(tex, 0, 0, 64, 64)
 		spr.SetHotSpot(32, 32)
 
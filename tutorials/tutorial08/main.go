@@ -15,6 +15,7 @@ import (
 	"github.com/losinggeneration/hge/helpers/font"
 	"github.com/losinggeneration/hge/helpers/sprite"
 	. "github.com/losinggeneration/hge/input"
+	"github.com/losinggeneration/hge/loop"
 	. "github.com/losinggeneration/hge/rand"
 	. "github.com/losinggeneration/hge/timer"
 )
@@ -22,6 +23,7 @@ import (
 // Pointer to the HGE interface (helper classes require this to work)
 var (
 	fnt *font.Font
+	sim *loop.Loop
 )
 
 // Simulation constants
@@ -77,7 +79,7 @@ var (
 )
 
 ///////////////////////// Implementation ///////////////////////////
-func frame() int {
+func frame(delta float64) bool {
 	// Process keys
 	switch GetKey() {
 	case K_0:
@@ -101,16 +103,20 @@ func frame() int {
 	case K_9:
 		speed = 25.6
 	case K_ESCAPE:
-		return 1
+		return true
 	}
 
+	return false
+}
+
+func update(dt float64) bool {
 	// Update scene
-	UpdateSimulation()
+	UpdateSimulation(dt)
 
-	return 0
+	return false
 }
 
-func render() int {
+func render(alpha float64) {
 	// 	int hrs, mins, secs;
 	// 	float tmp;
 
@@ -126,17 +132,19 @@ func render() int {
 	fnt.Printf(7, 7, font.TEXT_LEFT, "Keys 1-9 to adjust simulation speed, 0 - real time\nFPS: %d", GetFPS())
 	fnt.Printf(SCREEN_WIDTH-50, 7, font.TEXT_LEFT, "%02d:%02d:%02d", hrs, mins, secs)
 	EndScene()
-
-	return 0
 }
 
 func main() {
 	h := hge.New()
 
+	// The simulation runs at a fixed 60 updates a second
+	sim = loop.New(1.0/60.0, update, render)
+	sim.Frame = frame
+
 	// Set desired system states and initialize HGE
 	h.SetState(hge.LOGFILE, "tutorial08.log")
-	h.SetState(hge.FRAMEFUNC, frame)
-	h.SetState(hge.RENDERFUNC, render)
+	h.SetState(hge.FRAMEFUNC, sim.FrameFunc)
+	h.SetState(hge.RENDERFUNC, sim.RenderFunc)
 	h.SetState(hge.TITLE, "HGE Tutorial 08 - The Big Calm")
 	h.SetState(hge.USESOUND, false)
 	h.SetState(hge.WINDOWED, true)
@@ -147,11 +155,14 @@ func main() {
 	if err := h.Initiate(); err == nil {
 		defer h.Shutdown()
 
-		fnt = font.New("font1.fnt")
+		if fnt, err = font.New("font1.fnt"); err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
 
 		if !InitSimulation() {
 			// If one of the data files is not found, display an error message and shutdown
-			fmt.Println("Error: Can't load resources. See log for details.\n")
+			fmt.Println("Error: Can't load resources. See log for details.")
 			return
 		}
 
@@ -172,8 +183,9 @@ func GetTime() float64 {
 
 func InitSimulation() bool {
 	// Load texture
-	texObjects = LoadTexture("objects.png")
-	if texObjects == nil {
+	var err error
+	if texObjects, err = LoadTexture("objects.png"); err != nil {
+		hge.New().Log("%v", err)
 		return false
 	}
 
@@ -217,7 +229,7 @@ func InitSimulation() bool {
 	return true
 }
 
-func UpdateSimulation() {
+func UpdateSimulation(dt float64) {
 	cellw := SCREEN_WIDTH / (SEA_SUBDIVISION - 1)
 
 	var col1, col2 color.ColorRGB
@@ -226,7 +238,7 @@ func UpdateSimulation() {
 	if speed == 0.0 {
 		timet = GetTime()
 	} else {
-		timet += Delta() * speed
+		timet += dt * speed
 		if timet >= 24.0 {
 			timet -= 24.0
 		}
@@ -349,7 +361,7 @@ func UpdateSimulation() {
 		a = float64(i) / (SEA_SUBDIVISION - 1)
 		col1 = colSeaTop.MulScalar(1 - a).Add(colSeaBtm.MulScalar(a))
 		dwCol1 = col1.HWColor()
-		fTime := 2.0 * Time()
+		fTime := 2.0 * sim.Time()
 		a *= 20
 
 		for j := 0; j < SEA_SUBDIVISION; j++ {